@@ -0,0 +1,127 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"errors"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// certificateCollector gathers certificates from several locations, keeping
+// track of any locations that could not be read.
+type certificateCollector struct {
+	certs []*schemas.Certificate
+	errs  []error
+}
+
+func (c *certificateCollector) add(certs []*schemas.Certificate, err error) {
+	if err != nil {
+		c.errs = append(c.errs, err)
+		return
+	}
+	c.certs = append(c.certs, certs...)
+}
+
+// AuditCertificates finds the certificates used by the service and reports
+// their expiry, key strength, signature algorithm and whether they are self
+// signed. Certificates are found using the CertificateService
+// CertificateLocations resource. If the service does not provide it, the
+// well known certificate collections of managers, systems, the account
+// service, event subscriptions and the update service are searched instead.
+//
+// Locations that cannot be read are recorded in the report's Errors rather
+// than stopping the audit. An error is only returned if no certificates could
+// be found and some locations failed.
+func (s *Service) AuditCertificates(opts *schemas.CertificateAuditOptions) (*schemas.CertificateAuditReport, error) {
+	collector := &certificateCollector{}
+	s.collectCertificateLocations(collector)
+	if len(collector.certs) == 0 {
+		s.collectKnownCertificates(collector)
+	}
+
+	report := schemas.AuditCertificates(collector.certs, opts)
+	report.Errors = collector.errs
+
+	if len(report.Entries) == 0 && len(report.Errors) > 0 {
+		return report, errors.Join(report.Errors...)
+	}
+	return report, nil
+}
+
+func (s *Service) collectCertificateLocations(collector *certificateCollector) {
+	certService, err := s.CertificateService()
+	if err != nil || certService == nil {
+		return
+	}
+
+	locations, err := certService.CertificateLocations()
+	if err != nil || locations == nil {
+		return
+	}
+
+	collector.add(locations.Certificates())
+}
+
+func (s *Service) collectKnownCertificates(collector *certificateCollector) {
+	client := s.GetClient()
+
+	managers, err := s.Managers()
+	collector.add(nil, err)
+	for _, manager := range managers {
+		collector.add(manager.Certificates())
+		protocol, err := manager.NetworkProtocol()
+		if err != nil {
+			collector.add(nil, err)
+		} else if protocol != nil {
+			collector.add(protocol.HTTPS.Certificates(client))
+		}
+	}
+
+	systems, err := s.Systems()
+	collector.add(nil, err)
+	for _, system := range systems {
+		collector.add(system.Certificates())
+		collector.add(system.Boot.Certificates(client))
+
+		secureBoot, err := system.SecureBoot()
+		if err != nil || secureBoot == nil {
+			collector.add(nil, err)
+			continue
+		}
+		databases, err := secureBoot.SecureBootDatabases()
+		collector.add(nil, err)
+		for _, database := range databases {
+			collector.add(database.Certificates())
+		}
+	}
+
+	accountService, err := s.AccountService()
+	collector.add(nil, err)
+	if accountService != nil {
+		accountService.LDAP.SetClient(client)
+		collector.add(accountService.LDAP.Certificates())
+		accountService.ActiveDirectory.SetClient(client)
+		collector.add(accountService.ActiveDirectory.Certificates())
+	}
+
+	eventService, err := s.EventService()
+	collector.add(nil, err)
+	if eventService != nil {
+		subscriptions, err := eventService.Subscriptions()
+		collector.add(nil, err)
+		for _, subscription := range subscriptions {
+			collector.add(subscription.Certificates())
+			collector.add(subscription.ClientCertificates())
+		}
+	}
+
+	updateService, err := s.UpdateService()
+	collector.add(nil, err)
+	if updateService != nil {
+		collector.add(updateService.RemoteServerCertificates())
+		collector.add(updateService.ClientCertificates())
+	}
+}
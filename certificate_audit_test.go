@@ -0,0 +1,67 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+func auditGetCall(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}
+}
+
+// TestAuditCertificatesLocations tests finding certificates through the
+// CertificateLocations resource.
+func TestAuditCertificatesLocations(t *testing.T) {
+	var service Service
+	err := json.Unmarshal([]byte(`{
+		"@odata.id": "/redfish/v1",
+		"CertificateService": {"@odata.id": "/redfish/v1/CertificateService"}
+	}`), &service)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &schemas.TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				auditGetCall(`{"@odata.id": "/redfish/v1/CertificateService",
+					"CertificateLocations": {"@odata.id": "/redfish/v1/CertificateService/CertificateLocations"}}`),
+				auditGetCall(`{"@odata.id": "/redfish/v1/CertificateService/CertificateLocations",
+					"Links": {"Certificates": [{"@odata.id": "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1"}]}}`),
+				auditGetCall(`{"@odata.id": "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1",
+					"CertificateType": "PKCS7", "CertificateString": "opaque",
+					"ValidNotAfter": "2000-01-01T00:00:00Z", "CertificateUsageTypes": ["Web"]}`),
+			},
+		},
+	}
+	service.SetClient(testClient)
+
+	report, err := service.AuditCertificates(nil)
+	if err != nil {
+		t.Fatalf("Error auditing certificates: %s", err)
+	}
+
+	if len(report.Entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(report.Entries))
+	}
+	entry := report.Entries[0]
+	if entry.URI != "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1" || !entry.Expired {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if len(report.Expiring()) != 1 {
+		t.Errorf("Expected expired certificate to be reported")
+	}
+}
@@ -7,9 +7,12 @@ package gofish
 import (
 	"bytes"
 	"context"
+	"crypto"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -55,6 +58,40 @@ type APIClient struct {
 	// keepAlive is a flag to indicate if we should try to keep idle connections open
 	keepAlive bool
 
+	// tokenSource provides multi-factor authentication tokens when renewing
+	// the session.
+	tokenSource func() (string, error)
+
+	// sessionContext is the client-supplied context for created sessions.
+	sessionContext string
+
+	// credentialProvider supplies credentials when the session is renewed.
+	credentialProvider CredentialProvider
+
+	// sessionStore persists the session for reuse by later clients.
+	sessionStore SessionStore
+
+	// bearer provides OAuth2 access tokens if bearer authentication is used.
+	bearer *bearerAuth
+
+	// passwordChangeAccount is the account that must have its password
+	// changed before the session is granted full access.
+	passwordChangeAccount string
+
+	// readOnly refuses requests that modify the service.
+	readOnly bool
+
+	// plan records modifying requests instead of sending them when in
+	// dry-run mode.
+	plan *requestPlan
+
+	// quirks are the workarounds applied for the service.
+	quirks Quirks
+
+	// firmwareQuirks are the workarounds depending on the manager firmware
+	// version, looked up when first needed.
+	firmwareQuirks *firmwareQuirks
+
 	Settings schemas.ClientSettings
 }
 
@@ -76,6 +113,36 @@ type ClientConfig struct {
 	// Password is the password to use for authentication.
 	Password string
 
+	// CredentialProvider optionally supplies the user name and password,
+	// taking precedence over Username and Password. It is also consulted
+	// when the session is renewed.
+	CredentialProvider CredentialProvider
+
+	// SessionStore optionally persists the session so later clients for the
+	// same endpoint and user can reuse it instead of creating a new session.
+	// A stored session is only used if the service still accepts it.
+	SessionStore SessionStore
+
+	// Token is an optional multi-factor authentication token, such as a
+	// Google Authenticator or RSA SecurID passcode, sent when creating the
+	// session. Since it can only be used once, sessions created with it
+	// cannot be renewed.
+	Token string
+
+	// TOTPSecret is an optional base32 encoded secret, as returned by
+	// ManagerAccount.GenerateSecretKey, used to compute a time based one-time
+	// passcode each time a session is created.
+	TOTPSecret string
+
+	// TokenSource is an optional function that returns the multi-factor
+	// authentication token each time a session is created. It takes
+	// precedence over Token and TOTPSecret.
+	TokenSource func() (string, error)
+
+	// SessionContext is an optional client-supplied context that remains
+	// with the session.
+	SessionContext string
+
 	// Session is an optional session ID+token obtained from a previous session
 	// If this is set, it is preferred over Username and Password
 	Session *Session
@@ -83,6 +150,43 @@ type ClientConfig struct {
 	// Insecure controls whether to enforce SSL certificate validity.
 	Insecure bool
 
+	// ClientCertificate is an optional certificate and key presented to the
+	// service for mutual TLS authentication. The PrivateKey may be any
+	// crypto.Signer, such as a key held in an HSM.
+	ClientCertificate *tls.Certificate
+
+	// ClientCertificatePEM is an optional PEM encoded client certificate,
+	// followed by any intermediate certificates, used if ClientCertificate is
+	// not set.
+	ClientCertificatePEM []byte
+
+	// ClientKeyPEM is the PEM encoded private key for ClientCertificatePEM.
+	// If empty, the key is expected to be included in ClientCertificatePEM.
+	ClientKeyPEM []byte
+
+	// ClientKey is an optional signer for ClientCertificatePEM, used instead
+	// of ClientKeyPEM when the private key cannot be exported.
+	ClientKey crypto.Signer
+
+	// RootCAs is an optional pool of CA certificates used to verify the
+	// service's certificate instead of the system pool.
+	RootCAs *x509.CertPool
+
+	// BearerTokenSource is an optional source of OAuth2 access tokens. If
+	// set, requests are authenticated with an "Authorization: Bearer" header
+	// instead of a session or basic auth. The token is refreshed when it
+	// expires or the service rejects it.
+	BearerTokenSource BearerTokenSource
+
+	// PinnedSPKIHashes is an optional list of base64 encoded SHA-256 hashes
+	// of certificate public keys, as returned by SPKIHash. If set, the
+	// connection is only trusted if the service's certificate matches one of
+	// the hashes, or is issued for the server name through a chain up to a
+	// presented certificate that matches one, replacing the normal
+	// certificate chain validation. This allows self-signed certificates to
+	// be trusted.
+	PinnedSPKIHashes []string
+
 	// Controls TLS handshake timeout
 	TLSHandshakeTimeout int
 
@@ -108,6 +212,24 @@ type ClientConfig struct {
 
 	// AutoExpand enables $expand if supported and automatically falls back if $expand fails.
 	AutoExpand bool
+
+	// ReadOnly makes the client refuse POST, PATCH, PUT and DELETE requests,
+	// other than logging in and out, with a ReadOnlyError.
+	ReadOnly bool
+
+	// DryRun makes the client record POST, PATCH, PUT and DELETE requests,
+	// other than logging in and out, instead of sending them. The recorded
+	// requests are available from PlannedRequests, and each is treated as
+	// having succeeded with no content. ReadOnly takes precedence.
+	DryRun bool
+
+	// Quirks optionally sets the workarounds to apply for the service,
+	// instead of detecting them from the QuirkRegistry.
+	Quirks *Quirks
+
+	// QuirkRegistry is the registry used to detect the workarounds to apply
+	// for the service. Defaults to DefaultQuirks.
+	QuirkRegistry *QuirkRegistry
 }
 
 // setupClientWithConfig setups the client using the client config
@@ -120,6 +242,10 @@ func setupClientWithConfig(ctx context.Context, config *ClientConfig) (c *APICli
 		endpoint:   config.Endpoint,
 		dumpWriter: config.DumpWriter,
 		ctx:        ctx,
+		readOnly:   config.ReadOnly,
+	}
+	if config.DryRun {
+		client.plan = &requestPlan{}
 	}
 
 	if config.MaxConcurrentRequests <= 0 {
@@ -152,7 +278,15 @@ func setupClientWithConfig(ctx context.Context, config *ClientConfig) (c *APICli
 	// if the provided HTTPClient uses a standard Transport, we want to
 	// amend its configuration to match what was provided to us if the user allows it.
 	// otherwise we'll rely on the user to configure the transport as they claim it's configured.
-	if transport, ok := client.HTTPClient.Transport.(*http.Transport); ok && !config.NoModifyTransport {
+	transport, ok := client.HTTPClient.Transport.(*http.Transport)
+	if ok && !config.NoModifyTransport {
+		if config.hasTLSOptions() {
+			transport.TLSClientConfig, err = config.applyTLSOptions(transport.TLSClientConfig)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		if config.Insecure {
 			// If we're using the default transport, need to make sure there
 			// is a TLSClientConfig set in order to set the SkipVerify flag.
@@ -177,6 +311,12 @@ func setupClientWithConfig(ctx context.Context, config *ClientConfig) (c *APICli
 		if config.TLSHandshakeTimeout != 0 {
 			transport.TLSHandshakeTimeout = time.Duration(config.TLSHandshakeTimeout) * time.Second
 		}
+	} else if config.hasTLSOptions() {
+		return nil, fmt.Errorf("TLS options can only be applied to an http.Transport that may be modified")
+	}
+
+	if config.BearerTokenSource != nil {
+		client.bearer = &bearerAuth{source: config.BearerTokenSource}
 	}
 
 	// Allow provided HTTPClients that don't use the standard Transport to reuse connections.
@@ -190,10 +330,21 @@ func setupClientWithConfig(ctx context.Context, config *ClientConfig) (c *APICli
 		return nil, err
 	}
 
-	// Init default settings
-	if config.AutoExpand && client.Service != nil {
+	if config.Quirks != nil {
+		client.SetQuirks(*config.Quirks)
+	} else {
+		client.detectQuirks(config, false)
+	}
+
+	return client, nil
+}
+
+// configureAutoExpand adds $expand to the default query options if enabled in
+// the config, supported by the service and not disabled by a quirk.
+func (c *APIClient) configureAutoExpand(config *ClientConfig) {
+	if config.AutoExpand && c.Service != nil && !c.currentQuirks(true).DisableExpand {
 		expand := schemas.ExpandNone
-		protocolFeats := client.Service.ProtocolFeaturesSupported
+		protocolFeats := c.Service.ProtocolFeaturesSupported
 		if protocolFeats.ExpandQuery.NoLinks {
 			expand = schemas.ExpandOptionPeriod
 		} else if protocolFeats.ExpandQuery.Links {
@@ -203,13 +354,11 @@ func setupClientWithConfig(ctx context.Context, config *ClientConfig) (c *APICli
 		}
 
 		if expand != schemas.ExpandNone {
-			client.Settings.DefaultQueryOptions = append(client.Settings.DefaultQueryOptions,
+			c.Settings.DefaultQueryOptions = append(c.Settings.DefaultQueryOptions,
 				schemas.WithCollectionQueryOpts(schemas.WithExpand(expand),
 					schemas.WithExpandFallback(true)))
 		}
 	}
-
-	return client, nil
 }
 
 // setupClientWithEndpoint setups the client using only the endpoint
@@ -236,33 +385,146 @@ func setupClientWithEndpoint(ctx context.Context, endpoint string) (c *APIClient
 
 // setupClientAuth setups the authentication in the client using the client config
 func (c *APIClient) setupClientAuth(config *ClientConfig) error {
+	if c.bearer != nil {
+		// Bearer tokens authenticate each request, no session is needed
+		return nil
+	}
+
+	c.credentialProvider = config.CredentialProvider
+	c.sessionStore = config.SessionStore
+
 	if config.Session != nil {
 		c.auth = &schemas.AuthToken{
 			Session: config.Session.ID,
 			Token:   config.Session.Token,
 		}
-	} else if config.Username != "" {
-		var auth *schemas.AuthToken
+		return nil
+	}
+
+	username, password := config.Username, config.Password
+	if c.credentialProvider != nil {
+		creds, err := c.credentialProvider.Credentials(c.ctx, c.endpoint)
+		if err != nil {
+			return fmt.Errorf("unable to get credentials: %w", err)
+		}
+		username, password = creds.Username, creds.Password
+	}
+
+	if username != "" && !config.BasicAuth && c.loadStoredSession(username) {
+		// Keep the credentials so the session can be renewed
+		c.auth.Username = username
+		c.auth.Password = password
+		return nil
+	}
+
+	if username != "" {
 		if config.BasicAuth {
-			auth = &schemas.AuthToken{
-				Username:  config.Username,
-				Password:  config.Password,
+			c.auth = &schemas.AuthToken{
+				Username:  username,
+				Password:  password,
 				BasicAuth: true,
 			}
-		} else {
-			var err error
-			auth, err = c.Service.CreateSession(config.Username, config.Password)
-			if err != nil {
-				return err
-			}
+			return nil
 		}
 
-		c.auth = auth
+		c.sessionContext = config.SessionContext
+		switch {
+		case config.TokenSource != nil:
+			c.tokenSource = config.TokenSource
+		case config.TOTPSecret != "":
+			c.tokenSource = schemas.TOTPTokenSource(config.TOTPSecret)
+		}
+
+		auth, err := c.createSession(username, password, config.Token)
+		if auth != nil {
+			// Keep the credentials so the session can be renewed if the
+			// service drops it, such as after a manager reset.
+			auth.Username = username
+			auth.Password = password
+			c.auth = auth
+		}
+		if err == nil {
+			c.storeSession(username)
+		}
+		return err
 	}
 
 	return nil
 }
 
+// loadStoredSession uses the user's session from the session store if there
+// is one and the service still accepts it.
+func (c *APIClient) loadStoredSession(username string) bool {
+	if c.sessionStore == nil {
+		return false
+	}
+
+	key := SessionStoreKey(c.endpoint, username)
+	session, err := c.sessionStore.Load(key)
+	if err != nil || session == nil || session.Token == "" {
+		return false
+	}
+	if session.ID == "" {
+		// Without the session URI the token can't be checked, so don't trust it
+		_ = c.sessionStore.Delete(key)
+		return false
+	}
+
+	c.auth = &schemas.AuthToken{Session: session.ID, Token: session.Token}
+	resp, err := c.Get(session.ID)
+	schemas.DeferredCleanupHTTPResponse(resp)
+	if err != nil {
+		// The session has expired or been removed
+		c.auth = nil
+		_ = c.sessionStore.Delete(key)
+		return false
+	}
+
+	return true
+}
+
+// storeSession saves the user's current session to the session store, if
+// there is one. Failing to save only means the session can't be reused later,
+// so errors are ignored.
+func (c *APIClient) storeSession(username string) {
+	if c.sessionStore == nil || c.auth == nil || c.auth.Token == "" || c.auth.Session == "" {
+		return
+	}
+	_ = c.sessionStore.Save(SessionStoreKey(c.endpoint, username), &Session{ID: c.auth.Session, Token: c.auth.Token})
+}
+
+// createSession creates a session, using the client's token source for the
+// multi-factor authentication token if there is one. If the service requires
+// a password change, the restricted session is returned along with the
+// PasswordChangeRequiredError.
+func (c *APIClient) createSession(username, password, token string) (*schemas.AuthToken, error) {
+	if c.tokenSource != nil {
+		var err error
+		token, err = c.tokenSource()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get authentication token: %w", err)
+		}
+	}
+
+	auth, err := c.Service.CreateSessionWithParameters(&schemas.SessionCreateParameters{
+		UserName: username,
+		Password: password,
+		Token:    token,
+		Context:  c.sessionContext,
+	})
+
+	var passwordErr *schemas.PasswordChangeRequiredError
+	if errors.As(err, &passwordErr) && passwordErr.Auth != nil {
+		c.passwordChangeAccount = passwordErr.AccountURI
+		return passwordErr.Auth, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
 // Connect creates a new client connection to a Redfish service.
 func Connect(config ClientConfig) (c *APIClient, err error) { //nolint:gocritic
 	return ConnectContext(context.Background(), config)
@@ -277,10 +539,19 @@ func ConnectContext(ctx context.Context, config ClientConfig) (c *APIClient, err
 
 	// Authenticate with the service
 	err = client.setupClientAuth(&config)
-	if err != nil {
+	if errors.Is(err, schemas.ErrPasswordChangeRequired) && client.auth != nil {
+		// Return the restricted client so ChangeRequiredPassword can be used
+		client.configureAutoExpand(&config)
+		return client, err
+	} else if err != nil {
 		return c, err
 	}
 
+	// Some quirks depend on details only available once authenticated, so
+	// wait for them before deciding whether to use $expand
+	client.detectQuirks(&config, true)
+	client.configureAutoExpand(&config)
+
 	return client, err
 }
 
@@ -321,6 +592,12 @@ func (c *APIClient) WithContext(ctx context.Context) *APIClient {
 	return &newClient
 }
 
+// ClientWithContext is the same as WithContext, returning the copy as a
+// schemas.Client so helpers that wait on the service can cancel requests.
+func (c *APIClient) ClientWithContext(ctx context.Context) schemas.Client {
+	return c.WithContext(ctx)
+}
+
 // CloneWithSession will create a new Client with a session instead of basic auth.
 func (c *APIClient) CloneWithSession() (*APIClient, error) {
 	if c.auth != nil && c.auth.Session != "" {
@@ -346,6 +623,73 @@ func (c *APIClient) CloneWithSession() (*APIClient, error) {
 	return &newClient, err
 }
 
+// RenewSession creates a new session using the credentials the client was
+// connected with, replacing the current session. This is needed when the
+// service has invalidated the session, such as after a manager reset. Clients
+// using basic auth or no authentication have nothing to renew.
+func (c *APIClient) RenewSession() error {
+	return c.renewSession(true)
+}
+
+// renewSession creates a new session, first getting the latest credentials
+// from the credential provider if refreshCredentials is set.
+func (c *APIClient) renewSession(refreshCredentials bool) error {
+	if c.auth == nil || c.auth.BasicAuth {
+		return nil
+	}
+	if refreshCredentials && c.credentialProvider != nil {
+		creds, err := c.credentialProvider.Credentials(c.ctx, c.endpoint)
+		if err != nil {
+			return fmt.Errorf("unable to renew session: %w", err)
+		}
+		c.auth.Username = creds.Username
+		c.auth.Password = creds.Password
+	}
+	if c.auth.Username == "" {
+		return fmt.Errorf("unable to renew session: no credentials available")
+	}
+
+	// Don't send the stale token along with the session request
+	c.auth.Token = ""
+	auth, err := c.createSession(c.auth.Username, c.auth.Password, "")
+	if err != nil {
+		return err
+	}
+
+	c.auth.Session = auth.Session
+	c.auth.Token = auth.Token
+	c.storeSession(c.auth.Username)
+	return nil
+}
+
+// ChangeRequiredPassword changes the password of an account that the service
+// requires to be changed before granting access. When ConnectContext returns
+// an error matching schemas.ErrPasswordChangeRequired along with a client, the
+// client holds a restricted session that may only be used to call this. Once
+// the password is changed, the restricted session is closed and a new session
+// is created with the new password.
+func (c *APIClient) ChangeRequiredPassword(newPassword string) error {
+	if c.auth == nil || c.passwordChangeAccount == "" {
+		return fmt.Errorf("no password change is required for this client")
+	}
+
+	err := schemas.ChangeRequiredPassword(c, c.passwordChangeAccount, newPassword)
+	if err != nil {
+		return err
+	}
+	c.passwordChangeAccount = ""
+
+	if c.auth.Session != "" {
+		// The restricted session is no longer needed, ignore failures
+		// since some services end it when the password changes.
+		_ = c.Service.DeleteSession(c.auth.Session)
+	}
+
+	// The credential provider may not know the new password yet
+	c.auth.Password = newPassword
+	return c.renewSession(false)
+}
+
 // GetSession retrieves the session data from an initialized APIClient. An error
 // is returned if the client is not authenticated.
 func (c *APIClient) GetSession() (*Session, error) {
@@ -538,6 +882,16 @@ func (c *APIClient) runRawRequestWithHeaders(method, url string, payloadBuffer i
 		return nil, schemas.ConstructError(0, []byte("unable to execute request, no target provided"))
 	}
 
+	if resp, intercepted, err := c.interceptRequest(method, url, payloadBuffer, contentType, customHeaders); intercepted {
+		return resp, err
+	}
+
+	// Only requests with an ETag are changed, so the firmware quirks don't
+	// need to be looked up for others
+	_, hasETag := customHeaders["If-Match"]
+	quirks := c.currentQuirks(hasETag)
+	customHeaders = quirks.applyRequestQuirks(method, url, customHeaders)
+
 	endpoint := fmt.Sprintf("%s%s", c.endpoint, url)
 	req, err := http.NewRequestWithContext(c.ctx, method, endpoint, payloadBuffer)
 	if err != nil {
@@ -574,7 +928,14 @@ func (c *APIClient) runRawRequestWithHeaders(method, url string, payloadBuffer i
 	}
 
 	// Add auth info if authenticated
-	if c.auth != nil {
+	var bearerToken string
+	if c.bearer != nil {
+		bearerToken, err = c.bearer.accessToken()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	} else if c.auth != nil {
 		if c.auth.Token != "" {
 			req.Header.Set("X-Auth-Token", c.auth.Token)
 		} else if c.auth.BasicAuth && c.auth.Username != "" && c.auth.Password != "" {
@@ -613,6 +974,26 @@ func (c *APIClient) runRawRequestWithHeaders(method, url string, payloadBuffer i
 		}
 	}
 
+	if c.bearer != nil {
+		if resp.StatusCode != http.StatusUnauthorized {
+			c.bearer.accept(bearerToken)
+		} else if c.bearer.reject(bearerToken) {
+			// Retry once with a refreshed token
+			schemas.DeferredCleanupHTTPResponse(resp)
+			if payloadBuffer != nil {
+				if _, err := payloadBuffer.Seek(0, io.SeekStart); err != nil {
+					return nil, err
+				}
+			}
+			return c.runRawRequestWithHeaders(method, url, payloadBuffer, contentType, customHeaders)
+		}
+	}
+
+	if resp.StatusCode == http.StatusAccepted && resp.Header.Get("Location") == "" {
+		quirks = c.currentQuirks(true)
+	}
+	quirks.applyResponseQuirks(resp)
+
 	// A 304 Not Modified is the successful outcome of a conditional GET: the
 	// caller sent If-None-Match and their cached representation is still valid.
 	// Return the response intact (so the caller can read the Etag header) along
@@ -622,7 +1003,8 @@ func (c *APIClient) runRawRequestWithHeaders(method, url string, payloadBuffer i
 		return resp, schemas.ErrNotModified
 	}
 
-	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 202 && resp.StatusCode != 204 {
+	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 202 && resp.StatusCode != 204 &&
+		resp.StatusCode != http.StatusPartialContent {
 		defer schemas.DeferredCleanupHTTPResponse(resp)
 		payload, err := io.ReadAll(resp.Body)
 		if err != nil {
@@ -680,6 +1062,9 @@ func (c *APIClient) Logout() {
 			// Clean up invalid session token and ID upon successful Logout
 			c.auth.Session = ""
 			c.auth.Token = ""
+			if c.sessionStore != nil {
+				_ = c.sessionStore.Delete(SessionStoreKey(c.endpoint, c.auth.Username))
+			}
 		}
 
 		c.HTTPClient.CloseIdleConnections()
@@ -0,0 +1,116 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// bearerExpiryDelta is how long before its expiry a bearer token is refreshed,
+// so a token does not expire while a request is in flight.
+const bearerExpiryDelta = 30 * time.Second
+
+// BearerToken is an OAuth2 access token used for bearer authentication.
+type BearerToken struct {
+	// AccessToken is the token sent in the Authorization header.
+	AccessToken string
+	// Expiry is when the token expires. A zero value means the token does not
+	// expire, and it is only refreshed if the service rejects it.
+	Expiry time.Time
+}
+
+// BearerTokenSource provides OAuth2 access tokens. An oauth2.TokenSource from
+// golang.org/x/oauth2 can be adapted with BearerTokenSourceFunc:
+//
+//	gofish.BearerTokenSourceFunc(func() (*gofish.BearerToken, error) {
+//		t, err := ts.Token()
+//		if err != nil {
+//			return nil, err
+//		}
+//		return &gofish.BearerToken{AccessToken: t.AccessToken, Expiry: t.Expiry}, nil
+//	})
+type BearerTokenSource interface {
+	// Token returns a valid access token. It is called when no token has
+	// been retrieved yet, the current token has expired, or the service
+	// rejected the current token.
+	Token() (*BearerToken, error)
+}
+
+// BearerTokenSourceFunc adapts a function to a BearerTokenSource.
+type BearerTokenSourceFunc func() (*BearerToken, error)
+
+// Token calls f.
+func (f BearerTokenSourceFunc) Token() (*BearerToken, error) {
+	return f()
+}
+
+// bearerAuth caches the token from a BearerTokenSource.
+type bearerAuth struct {
+	mu     sync.Mutex
+	source BearerTokenSource
+	token  *BearerToken
+	// refreshed is set when the current token was retrieved after the
+	// service rejected the previous one.
+	refreshed bool
+	// rejected is set when the service rejected the current token.
+	rejected bool
+}
+
+// accessToken returns the current access token, retrieving a new one from
+// the source if needed.
+func (b *bearerAuth) accessToken() (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.token != nil && !b.rejected &&
+		(b.token.Expiry.IsZero() || time.Until(b.token.Expiry) > bearerExpiryDelta) {
+		return b.token.AccessToken, nil
+	}
+
+	token, err := b.source.Token()
+	if err != nil {
+		return "", err
+	}
+	if token == nil || token.AccessToken == "" {
+		return "", errors.New("bearer token source returned no access token")
+	}
+
+	b.refreshed = b.rejected
+	b.rejected = false
+	b.token = token
+	return token.AccessToken, nil
+}
+
+// reject records that the service rejected the token. It returns true if
+// the request should be retried, either because a newer token is already
+// available or because a new token should be requested. A token retrieved
+// because the previous one was rejected is not retried again.
+func (b *bearerAuth) reject(used string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.token == nil || b.token.AccessToken != used {
+		return true
+	}
+	if b.refreshed {
+		return false
+	}
+
+	b.rejected = true
+	return true
+}
+
+// accept records that the service accepted the token, allowing it to be
+// refreshed again if the service rejects it later.
+func (b *bearerAuth) accept(used string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.token != nil && b.token.AccessToken == used {
+		b.refreshed = false
+	}
+}
@@ -0,0 +1,172 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrReadOnly is returned when a read-only client is asked to modify the
+// service.
+var ErrReadOnly = errors.New("client is read-only")
+
+// ReadOnlyError is returned for a request refused by a read-only client.
+type ReadOnlyError struct {
+	Method string
+	URL    string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("%s: refusing %s %s", ErrReadOnly, e.Method, e.URL)
+}
+
+// Unwrap allows errors.Is to match ErrReadOnly.
+func (e *ReadOnlyError) Unwrap() error {
+	return ErrReadOnly
+}
+
+// PlannedRequest is a modifying request recorded, rather than sent, by a
+// client in dry-run mode.
+type PlannedRequest struct {
+	Method string
+	URL    string
+	// Headers are the request headers, excluding authentication.
+	Headers map[string]string
+	// Body is the request payload.
+	Body []byte
+}
+
+func (p *PlannedRequest) String() string {
+	if len(p.Body) == 0 {
+		return p.Method + " " + p.URL
+	}
+	return fmt.Sprintf("%s %s %s", p.Method, p.URL, p.Body)
+}
+
+// requestPlan collects the requests made by a dry-run client. It is shared by
+// copies of the client.
+type requestPlan struct {
+	mu       sync.Mutex
+	requests []PlannedRequest
+}
+
+// isModifying returns whether the method changes the service.
+func isModifying(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// isSessionRequest returns whether the request logs in or out, which is
+// allowed in read-only and dry-run modes.
+func (c *APIClient) isSessionRequest(method, url string) bool {
+	switch method {
+	case http.MethodPost:
+		sessions := "/redfish/v1/SessionService/Sessions"
+		if c.Service != nil && c.Service.sessions != "" {
+			sessions = c.Service.sessions
+		}
+		return strings.TrimSuffix(url, "/") == strings.TrimSuffix(sessions, "/")
+	case http.MethodDelete:
+		return c.auth != nil && c.auth.Session != "" && url == c.auth.Session
+	}
+	return false
+}
+
+// interceptRequest applies the read-only and dry-run modes. If the request
+// should not be sent, it returns true along with the response or error to use
+// instead.
+func (c *APIClient) interceptRequest(method, url string, payloadBuffer io.ReadSeeker, contentType string,
+	customHeaders map[string]string) (*http.Response, bool, error) {
+	if (!c.readOnly && c.plan == nil) || !isModifying(method) || c.isSessionRequest(method, url) {
+		return nil, false, nil
+	}
+
+	if c.readOnly {
+		return nil, true, &ReadOnlyError{Method: method, URL: url}
+	}
+
+	planned := PlannedRequest{Method: method, URL: url, Headers: make(map[string]string)}
+	for k, v := range customHeaders {
+		if k != "" {
+			planned.Headers[k] = v
+		}
+	}
+	if contentType != "" {
+		planned.Headers["Content-Type"] = contentType
+	}
+	if payloadBuffer != nil {
+		body, err := io.ReadAll(payloadBuffer)
+		if err != nil {
+			return nil, true, err
+		}
+		planned.Body = body
+	}
+
+	c.plan.mu.Lock()
+	c.plan.requests = append(c.plan.requests, planned)
+	c.plan.mu.Unlock()
+
+	return &http.Response{
+		Status:     "204 No Content",
+		StatusCode: http.StatusNoContent,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(bytes.NewReader(nil)),
+	}, true, nil
+}
+
+// ReadOnly returns whether the client refuses to modify the service.
+func (c *APIClient) ReadOnly() bool {
+	return c.readOnly
+}
+
+// DryRun returns whether the client records modifying requests instead of
+// sending them.
+func (c *APIClient) DryRun() bool {
+	return c.plan != nil
+}
+
+// PlannedRequests returns the modifying requests recorded by a dry-run client,
+// in the order they were made.
+func (c *APIClient) PlannedRequests() []PlannedRequest {
+	if c.plan == nil {
+		return nil
+	}
+
+	c.plan.mu.Lock()
+	defer c.plan.mu.Unlock()
+	return append([]PlannedRequest(nil), c.plan.requests...)
+}
+
+// Plan runs fn on a dry-run client and returns the modifying requests made
+// while it ran, such as by calling Update, Reset or UpdateBiosAttributes, so
+// they can be reviewed before being applied with a normal client. Requests
+// made concurrently by other users of the client are included as well.
+func (c *APIClient) Plan(fn func() error) ([]PlannedRequest, error) {
+	if c.plan == nil {
+		return nil, errors.New("client is not in dry-run mode")
+	}
+
+	c.plan.mu.Lock()
+	start := len(c.plan.requests)
+	c.plan.mu.Unlock()
+
+	err := fn()
+
+	c.plan.mu.Lock()
+	defer c.plan.mu.Unlock()
+	return append([]PlannedRequest(nil), c.plan.requests[start:]...), err
+}
@@ -0,0 +1,102 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+func newModesServer(t *testing.T, modifying *[]string) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/SessionService/Sessions":
+			w.Header().Set("X-Auth-Token", "token")
+			w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/1")
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodDelete && r.URL.Path == "/redfish/v1/SessionService/Sessions/1":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method != http.MethodGet:
+			*modifying = append(*modifying, r.Method+" "+r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/redfish/v1/":
+			w.Write([]byte(`{"Systems": {"@odata.id": "/redfish/v1/Systems"}, ` + //nolint
+				`"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}}`))
+		case r.URL.Path == "/redfish/v1/Systems":
+			w.Write([]byte(`{"Members": [{"@odata.id": "/redfish/v1/Systems/1"}]}`)) //nolint
+		case r.URL.Path == "/redfish/v1/Systems/1":
+			w.Write([]byte(`{"@odata.id": "/redfish/v1/Systems/1", "Id": "1", "AssetTag": "old", ` + //nolint
+				`"Actions": {"#ComputerSystem.Reset": {"target": "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestReadOnlyClient verifies modifying requests are refused while logging in
+// and out still works.
+func TestReadOnlyClient(t *testing.T) {
+	var modifying []string
+	ts := newModesServer(t, &modifying)
+
+	client, err := Connect(ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client(), Username: "admin", Password: "secret", ReadOnly: true})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	_, err = client.Patch("/redfish/v1/Systems/1", map[string]string{"AssetTag": "new"})
+	var readOnlyErr *ReadOnlyError
+	if !errors.As(err, &readOnlyErr) || !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("expected a read-only error, got: %v", err)
+	}
+	schemas.AssertEqual(t, http.MethodPatch, readOnlyErr.Method)
+
+	client.Logout()
+	schemas.AssertEqual(t, 0, len(modifying))
+}
+
+// TestDryRunClient verifies modifying requests are recorded instead of sent.
+func TestDryRunClient(t *testing.T) {
+	var modifying []string
+	ts := newModesServer(t, &modifying)
+
+	client, err := Connect(ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client(), Username: "admin", Password: "secret", DryRun: true})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	systems, err := client.Service.Systems()
+	if err != nil {
+		t.Fatalf("failed to get systems: %v", err)
+	}
+	system := systems[0]
+	system.DisableEtagMatch(true)
+
+	planned, err := client.Plan(func() error {
+		system.AssetTag = "new"
+		if err := system.Update(); err != nil {
+			return err
+		}
+		_, err := system.Reset(schemas.ForceRestartResetType)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+
+	schemas.AssertEqual(t, 2, len(planned))
+	schemas.AssertEqual(t, `PATCH /redfish/v1/Systems/1 {"AssetTag":"new"}`, strings.TrimSpace(planned[0].String()))
+	schemas.AssertEqual(t, "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset", planned[1].URL)
+	schemas.AssertEqual(t, "application/json", planned[1].Headers["Content-Type"])
+	schemas.AssertEqual(t, 2, len(client.PlannedRequests()))
+	schemas.AssertEqual(t, 0, len(modifying))
+}
@@ -6,9 +6,13 @@ package gofish
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -235,3 +239,155 @@ func TestClientRunRawRequestNoURL(t *testing.T) {
 		t.Errorf("Unexpected error response: %s", err.Error())
 	}
 }
+
+// TestRenewSession verifies a new session is created with the original
+// credentials and replaces the current session token.
+func TestRenewSession(t *testing.T) {
+	sessions := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/":
+			w.Write([]byte(`{"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}}`)) //nolint
+		case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/SessionService/Sessions":
+			if r.Header.Get("X-Auth-Token") != "" {
+				t.Errorf("stale token should not be sent when creating a session")
+			}
+			sessions++
+			w.Header().Set("X-Auth-Token", fmt.Sprintf("token-%d", sessions))
+			w.Header().Set("Location", fmt.Sprintf("/redfish/v1/SessionService/Sessions/%d", sessions))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := Connect(ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client(), Username: "admin", Password: "secret"})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	if err := client.RenewSession(); err != nil {
+		t.Fatalf("failed to renew session: %v", err)
+	}
+
+	session, err := client.GetSession()
+	if err != nil {
+		t.Fatalf("failed to get session: %v", err)
+	}
+	schemas.AssertEqual(t, "token-2", session.Token)
+	schemas.AssertEqual(t, "/redfish/v1/SessionService/Sessions/2", session.ID)
+}
+
+// TestConnectPasswordChangeRequired verifies a restricted session is returned
+// when the service requires a password change, and that changing the password
+// creates a full session.
+func TestConnectPasswordChangeRequired(t *testing.T) {
+	var passwords, tokens []string
+	sessions := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/":
+			w.Write([]byte(`{"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}}`)) //nolint
+		case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/SessionService/Sessions":
+			var body schemas.SessionCreateParameters
+			json.NewDecoder(r.Body).Decode(&body) //nolint
+			passwords = append(passwords, body.Password)
+			tokens = append(tokens, body.Token)
+			sessions++
+			w.Header().Set("X-Auth-Token", fmt.Sprintf("token-%d", sessions))
+			w.Header().Set("Location", fmt.Sprintf("/redfish/v1/SessionService/Sessions/%d", sessions))
+			w.WriteHeader(http.StatusCreated)
+			if body.Password == "initial" {
+				w.Write([]byte(`{"@Message.ExtendedInfo": [{"MessageId": "Base.1.8.PasswordChangeRequired", ` + //nolint
+					`"MessageArgs": ["/redfish/v1/AccountService/Accounts/2"]}]}`))
+			}
+		case r.Method == http.MethodPatch && r.URL.Path == "/redfish/v1/AccountService/Accounts/2":
+			if r.Header.Get("X-Auth-Token") != "token-1" {
+				t.Errorf("password should be changed using the restricted session")
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete && r.URL.Path == "/redfish/v1/SessionService/Sessions/1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client, err := Connect(ClientConfig{
+		Endpoint:   ts.URL,
+		HTTPClient: ts.Client(),
+		Username:   "admin",
+		Password:   "initial",
+		TokenSource: func() (string, error) {
+			return fmt.Sprintf("%06d", len(tokens)), nil
+		},
+	})
+	if !errors.Is(err, schemas.ErrPasswordChangeRequired) {
+		t.Fatalf("expected password change required, got: %v", err)
+	}
+	if client == nil {
+		t.Fatal("expected a restricted client to be returned")
+	}
+
+	if err := client.ChangeRequiredPassword("updated"); err != nil {
+		t.Fatalf("failed to change password: %v", err)
+	}
+
+	session, err := client.GetSession()
+	if err != nil {
+		t.Fatalf("failed to get session: %v", err)
+	}
+	schemas.AssertEqual(t, "token-2", session.Token)
+	schemas.AssertEqual(t, "initial,updated", strings.Join(passwords, ","))
+	schemas.AssertEqual(t, "000000,000001", strings.Join(tokens, ","))
+}
+
+// TestBearerTokenSource verifies requests use the bearer token and that a
+// rejected token is refreshed and the request retried.
+func TestBearerTokenSource(t *testing.T) {
+	var seen []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		seen = append(seen, auth)
+		if auth == "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method == http.MethodPatch {
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != `{"AssetTag":"rack-1"}` {
+				t.Errorf("unexpected retried body: %s", body)
+			}
+		}
+		w.Write([]byte(`{"@odata.id": "/redfish/v1/"}`)) //nolint
+	}))
+	defer ts.Close()
+
+	issued := 0
+	client, err := Connect(ClientConfig{
+		Endpoint:   ts.URL,
+		HTTPClient: ts.Client(),
+		BearerTokenSource: BearerTokenSourceFunc(func() (*BearerToken, error) {
+			issued++
+			// The first token is already expired so it is refreshed before
+			// the second request
+			return &BearerToken{
+				AccessToken: fmt.Sprintf("token-%d", issued),
+				Expiry:      time.Now().Add(time.Duration(issued-1) * time.Hour),
+			}, nil
+		}),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	resp, err := client.Patch("/redfish/v1/Systems/1", map[string]string{"AssetTag": "rack-1"})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	schemas.AssertEqual(t, "Bearer token-1,Bearer token-2,Bearer token-3", strings.Join(seen, ","))
+}
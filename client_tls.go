@@ -0,0 +1,173 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SPKIHash returns the base64 encoded SHA-256 hash of the certificate's
+// SubjectPublicKeyInfo, in the form used by ClientConfig.PinnedSPKIHashes.
+// It can be computed from a PEM certificate with:
+//
+//	openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+func SPKIHash(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// hasTLSOptions returns true if any of the TLS settings are configured.
+func (config *ClientConfig) hasTLSOptions() bool {
+	return config.ClientCertificate != nil || len(config.ClientCertificatePEM) > 0 ||
+		len(config.ClientKeyPEM) > 0 || config.ClientKey != nil ||
+		config.RootCAs != nil || len(config.PinnedSPKIHashes) > 0
+}
+
+// clientCertificate builds the TLS client certificate from the config. A key
+// without a certificate, or a key that wouldn't be used, is an error rather
+// than connecting without the client certificate intended.
+func (config *ClientConfig) clientCertificate() (*tls.Certificate, error) {
+	hasKey := len(config.ClientKeyPEM) > 0 || config.ClientKey != nil
+	switch {
+	case config.ClientCertificate != nil && (hasKey || len(config.ClientCertificatePEM) > 0):
+		return nil, errors.New("ClientCertificate already includes its key, so can't be combined with the PEM certificate or key")
+	case config.ClientCertificate != nil:
+		return config.ClientCertificate, nil
+	case len(config.ClientKeyPEM) > 0 && config.ClientKey != nil:
+		return nil, errors.New("only one of ClientKeyPEM and ClientKey can be set")
+	case len(config.ClientCertificatePEM) == 0 && hasKey:
+		return nil, errors.New("a client key was given without ClientCertificatePEM")
+	case len(config.ClientCertificatePEM) == 0:
+		return nil, nil
+	}
+
+	if config.ClientKey != nil {
+		cert := tls.Certificate{PrivateKey: config.ClientKey}
+		rest := config.ClientCertificatePEM
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type == "CERTIFICATE" {
+				cert.Certificate = append(cert.Certificate, block.Bytes)
+			}
+		}
+		if len(cert.Certificate) == 0 {
+			return nil, errors.New("no client certificate found in PEM data")
+		}
+
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		if !publicKeysEqual(leaf.PublicKey, config.ClientKey.Public()) {
+			return nil, errors.New("client key does not match the client certificate")
+		}
+		cert.Leaf = leaf
+		return &cert, nil
+	}
+
+	keyPEM := config.ClientKeyPEM
+	if len(keyPEM) == 0 {
+		// Allow the key to be bundled with the certificate
+		keyPEM = config.ClientCertificatePEM
+	}
+	cert, err := tls.X509KeyPair(config.ClientCertificatePEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+// publicKeysEqual compares two public keys.
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	key, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(b)
+}
+
+// applyTLSOptions returns a copy of base with the client certificate, root CAs
+// and certificate pinning from the config applied.
+func (config *ClientConfig) applyTLSOptions(base *tls.Config) (*tls.Config, error) {
+	var tlsConfig *tls.Config
+	if base != nil {
+		tlsConfig = base.Clone()
+	} else {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	cert, err := config.clientCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+
+	if config.RootCAs != nil {
+		tlsConfig.RootCAs = config.RootCAs
+	}
+
+	if len(config.PinnedSPKIHashes) > 0 {
+		pins := make(map[string]bool, len(config.PinnedSPKIHashes))
+		for _, pin := range config.PinnedSPKIHashes {
+			pins[strings.TrimPrefix(pin, "sha256/")] = true
+		}
+
+		// The pin replaces the normal chain validation so self-signed
+		// certificates can be trusted without adding them to a CA pool.
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyPinnedChain(&cs, pins)
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyPinnedChain checks the service's certificate matches a pin. The rest
+// of the presented chain is not verified by the handshake, so a pinned
+// certificate other than the leaf is only trusted once the leaf is verified
+// to chain up to it, for the server name connected to.
+func verifyPinnedChain(cs *tls.ConnectionState, pins map[string]bool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server did not present a certificate")
+	}
+
+	leaf := cs.PeerCertificates[0]
+	if pins[SPKIHash(leaf)] {
+		return nil
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		if !pins[SPKIHash(cert)] {
+			continue
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(cert)
+		_, err := leaf.Verify(x509.VerifyOptions{
+			DNSName:       cs.ServerName,
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		if err == nil {
+			return nil
+		}
+	}
+	return errors.New("server certificate does not match any pinned public key")
+}
@@ -0,0 +1,245 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClientCertificate creates a self-signed client certificate and key.
+func testClientCertificate(t *testing.T) (certPEM []byte, key *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "automation"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), key
+}
+
+// newMutualTLSServer starts a TLS server that requires a client certificate
+// and reports the common name of the certificate presented.
+func newMutualTLSServer(t *testing.T, clientNames *[]string) *httptest.Server {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			*clientNames = append(*clientNames, r.TLS.PeerCertificates[0].Subject.CommonName)
+		}
+		w.Write([]byte(`{"@odata.id": "/redfish/v1/"}`)) //nolint
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MinVersion: tls.VersionTLS12}
+	ts.StartTLS()
+	return ts
+}
+
+// TestConnectClientCertificate verifies a client certificate with an
+// unexportable key is presented and the server is trusted through a custom
+// root CA pool.
+func TestConnectClientCertificate(t *testing.T) {
+	var clientNames []string
+	ts := newMutualTLSServer(t, &clientNames)
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+
+	certPEM, key := testClientCertificate(t)
+	_, err := Connect(ClientConfig{
+		Endpoint:             ts.URL,
+		ClientCertificatePEM: certPEM,
+		ClientKey:            key,
+		RootCAs:              roots,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	if strings.Join(clientNames, ",") != "automation" {
+		t.Errorf("expected the client certificate to be presented, got: %v", clientNames)
+	}
+}
+
+// TestConnectPinnedCertificate verifies the server is only trusted when its
+// public key matches a pin.
+func TestConnectPinnedCertificate(t *testing.T) {
+	var clientNames []string
+	ts := newMutualTLSServer(t, &clientNames)
+	defer ts.Close()
+
+	certPEM, key := testClientCertificate(t)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	_, err = Connect(ClientConfig{
+		Endpoint:             ts.URL,
+		ClientCertificatePEM: certPEM,
+		ClientKeyPEM:         keyPEM,
+		PinnedSPKIHashes:     []string{"sha256/" + SPKIHash(ts.Certificate())},
+	})
+	if err != nil {
+		t.Fatalf("failed to connect with matching pin: %v", err)
+	}
+
+	_, err = Connect(ClientConfig{
+		Endpoint:             ts.URL,
+		ClientCertificatePEM: certPEM,
+		ClientKeyPEM:         keyPEM,
+		PinnedSPKIHashes:     []string{"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not match any pinned public key") {
+		t.Errorf("expected pin mismatch error, got: %v", err)
+	}
+}
+
+// TestConnectClientKeyMismatch verifies a signer that does not match the
+// client certificate is rejected.
+func TestConnectClientKeyMismatch(t *testing.T) {
+	certPEM, _ := testClientCertificate(t)
+	_, otherKey := testClientCertificate(t)
+
+	_, err := Connect(ClientConfig{
+		Endpoint:             "https://127.0.0.1:1",
+		ClientCertificatePEM: certPEM,
+		ClientKey:            otherKey,
+	})
+	if err == nil || !strings.Contains(err.Error(), "client key does not match") {
+		t.Errorf("expected key mismatch error, got: %v", err)
+	}
+}
+
+// TestConnectClientKeyWithoutCertificate verifies a client key without its
+// certificate, or a key that wouldn't be used, is rejected.
+func TestConnectClientKeyWithoutCertificate(t *testing.T) {
+	certPEM, key := testClientCertificate(t)
+	block, _ := pem.Decode(certPEM)
+	cert := tls.Certificate{Certificate: [][]byte{block.Bytes}, PrivateKey: key}
+
+	tests := []struct {
+		name   string
+		config ClientConfig
+		errMsg string
+	}{
+		{"signer only", ClientConfig{ClientKey: key}, "without ClientCertificatePEM"},
+		{"PEM key only", ClientConfig{ClientKeyPEM: certPEM}, "without ClientCertificatePEM"},
+		{"both keys", ClientConfig{ClientCertificatePEM: certPEM, ClientKeyPEM: certPEM, ClientKey: key}, "only one of"},
+		{"certificate and key", ClientConfig{ClientCertificate: &cert, ClientKey: key}, "can't be combined"},
+	}
+	for _, tt := range tests {
+		tt.config.Endpoint = "https://127.0.0.1:1"
+		_, err := Connect(tt.config)
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got: %v", tt.name, tt.errMsg, err)
+		}
+	}
+}
+
+// testServerCertificate creates a certificate for 127.0.0.1, signed by the
+// parent or self-signed if parent is nil.
+func testServerCertificate(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "bmc"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// newChainTLSServer starts a TLS server presenting the chain.
+func newChainTLSServer(t *testing.T, key *ecdsa.PrivateKey, chain ...*x509.Certificate) *httptest.Server {
+	certificate := tls.Certificate{PrivateKey: key}
+	for _, cert := range chain {
+		certificate.Certificate = append(certificate.Certificate, cert.Raw)
+	}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"@odata.id": "/redfish/v1/"}`)) //nolint
+	}))
+	ts.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12}
+	ts.StartTLS()
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestConnectPinnedForgedChain verifies a pinned certificate appended to the
+// chain of another certificate is not trusted.
+func TestConnectPinnedForgedChain(t *testing.T) {
+	victim, _ := testServerCertificate(t, nil, nil, false)
+	forged, forgedKey := testServerCertificate(t, nil, nil, false)
+	ts := newChainTLSServer(t, forgedKey, forged, victim)
+
+	_, err := Connect(ClientConfig{
+		Endpoint:         ts.URL,
+		PinnedSPKIHashes: []string{SPKIHash(victim)},
+	})
+	if err == nil || !strings.Contains(err.Error(), "does not match any pinned public key") {
+		t.Errorf("expected pin mismatch error, got: %v", err)
+	}
+}
+
+// TestConnectPinnedIssuer verifies a pinned CA is trusted when the service's
+// certificate is issued by it.
+func TestConnectPinnedIssuer(t *testing.T) {
+	ca, caKey := testServerCertificate(t, nil, nil, true)
+	leaf, leafKey := testServerCertificate(t, ca, caKey, false)
+	ts := newChainTLSServer(t, leafKey, leaf, ca)
+
+	_, err := Connect(ClientConfig{
+		Endpoint:         ts.URL,
+		PinnedSPKIHashes: []string{SPKIHash(ca)},
+	})
+	if err != nil {
+		t.Fatalf("failed to connect with pinned issuer: %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+// Package collector exports the sensor readings and health of Redfish
+// services as metrics. It follows the design of a Prometheus collector, with
+// Describe and Collect methods that can be adapted to the Prometheus client
+// library, and can also write the Prometheus text exposition format itself so
+// no extra dependencies are needed.
+//
+// Resources are discovered on the first scrape and then re-read with
+// conditional GETs, so unchanged resources cost the service a 304 response
+// rather than a full read. The resources are discovered again periodically to
+// pick up added or removed components.
+package collector
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// defaultRediscoverInterval is how often the resources are discovered again
+// if not set in the Options.
+const defaultRediscoverInterval = 10 * time.Minute
+
+// Target is a Redfish service to collect metrics from.
+type Target struct {
+	// Name is the value of the "target" label of the metrics of this service.
+	Name string
+	// Client is the connection to the service.
+	Client *gofish.APIClient
+}
+
+// Options are the options of a Collector.
+type Options struct {
+	// RediscoverInterval is how often the chassis, systems and their
+	// components are discovered again. In between only the known resources
+	// are refreshed. The default is 10 minutes.
+	RediscoverInterval time.Duration
+	// ErrorHandler is called with the errors reading a target, if set. The
+	// metrics of the resources that could be read are still collected.
+	// Errors sending the metrics from ServeHTTP are passed with an empty
+	// target.
+	ErrorHandler func(target string, err error)
+}
+
+// Collector collects metrics from one or more Redfish services.
+type Collector struct {
+	opts    Options
+	targets []*targetState
+}
+
+// targetState is the state kept between scrapes of a target.
+type targetState struct {
+	Target
+
+	mu         sync.Mutex
+	discovered time.Time
+	chassis    []*chassisState
+	systems    []*systemState
+}
+
+type chassisState struct {
+	chassis       *schemas.Chassis
+	sensors       []*schemas.Sensor
+	power         *schemas.Power
+	thermal       *schemas.Thermal
+	powerSupplies []*schemas.PowerSupply
+	fans          []*schemas.ThermalFan
+}
+
+type systemState struct {
+	system           *schemas.ComputerSystem
+	processors       []*schemas.Processor
+	processorMetrics []*schemas.ProcessorMetrics
+	memory           []*schemas.Memory
+	memoryMetrics    []*schemas.MemoryMetrics
+}
+
+// NewCollector creates a collector for the targets.
+func NewCollector(opts *Options, targets ...Target) *Collector {
+	c := &Collector{}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.RediscoverInterval <= 0 {
+		c.opts.RediscoverInterval = defaultRediscoverInterval
+	}
+	for _, target := range targets {
+		c.targets = append(c.targets, &targetState{Target: target})
+	}
+	return c
+}
+
+// Describe sends the descriptions of all metrics the collector may collect.
+func (c *Collector) Describe(ch chan<- *Desc) {
+	for _, desc := range allDescs {
+		ch <- desc
+	}
+}
+
+// Collect reads the targets concurrently and sends their metrics. It returns
+// once all targets have been read.
+func (c *Collector) Collect(ch chan<- Metric) {
+	var wg sync.WaitGroup
+	for _, target := range c.targets {
+		wg.Add(1)
+		go func(target *targetState) {
+			defer wg.Done()
+			c.collectTarget(target, ch)
+		}(target)
+	}
+	wg.Wait()
+}
+
+func (c *Collector) collectTarget(target *targetState, ch chan<- Metric) {
+	target.mu.Lock()
+	defer target.mu.Unlock()
+
+	up := 1.0
+	var err error
+	if target.discovered.IsZero() || time.Since(target.discovered) >= c.opts.RediscoverInterval {
+		err = target.discover()
+	} else if target.refresh() != nil {
+		// Anything that failed to refresh may have been removed, so fall
+		// back to discovering everything again
+		err = target.discover()
+	}
+	if err != nil {
+		var discoveryErr *discoveryError
+		if errors.As(err, &discoveryErr) {
+			up = 0
+		}
+		if c.opts.ErrorHandler != nil {
+			c.opts.ErrorHandler(target.Name, err)
+		}
+	}
+
+	ch <- newMetric(upDesc, up, target.Name)
+	if up == 0 {
+		return
+	}
+	for _, chassis := range target.chassis {
+		chassis.collect(target.Name, ch)
+	}
+	for _, system := range target.systems {
+		system.collect(target.Name, ch)
+	}
+}
+
+// discoveryError is returned if the chassis or systems of a target could not
+// be listed, so no metrics can be collected.
+type discoveryError struct {
+	err error
+}
+
+func (e *discoveryError) Error() string {
+	return "discovering resources: " + e.err.Error()
+}
+
+func (e *discoveryError) Unwrap() error {
+	return e.err
+}
+
+// discover reads all the resources of the target. Errors reading components
+// are returned after reading as much as possible.
+func (t *targetState) discover() error {
+	t.chassis = nil
+	t.systems = nil
+	t.discovered = time.Time{}
+
+	service := t.Client.GetService()
+	chassisList, err := service.Chassis()
+	if err != nil {
+		return &discoveryError{err}
+	}
+	systems, err := service.Systems()
+	if err != nil {
+		return &discoveryError{err}
+	}
+
+	var errs []error
+	for _, chassis := range chassisList {
+		state, err := discoverChassis(chassis)
+		errs = append(errs, err)
+		t.chassis = append(t.chassis, state)
+	}
+	for _, system := range systems {
+		state, err := discoverSystem(system)
+		errs = append(errs, err)
+		t.systems = append(t.systems, state)
+	}
+
+	t.discovered = time.Now()
+	return errors.Join(errs...)
+}
+
+func discoverChassis(chassis *schemas.Chassis) (*chassisState, error) {
+	state := &chassisState{chassis: chassis}
+	var errs []error
+	var err error
+
+	state.sensors, err = chassis.Sensors()
+	errs = append(errs, err)
+
+	powerSubsystem, err := chassis.PowerSubsystem()
+	errs = append(errs, err)
+	if powerSubsystem != nil {
+		state.powerSupplies, err = powerSubsystem.PowerSupplies()
+		errs = append(errs, err)
+	}
+	thermalSubsystem, err := chassis.ThermalSubsystem()
+	errs = append(errs, err)
+	if thermalSubsystem != nil {
+		state.fans, err = thermalSubsystem.Fans()
+		errs = append(errs, err)
+	}
+
+	// The deprecated resources are only read if the newer ones don't provide
+	// the same data
+	if len(state.sensors) == 0 || powerSubsystem == nil {
+		state.power, err = chassis.Power()
+		errs = append(errs, err)
+	}
+	if len(state.sensors) == 0 || thermalSubsystem == nil {
+		state.thermal, err = chassis.Thermal()
+		errs = append(errs, err)
+	}
+
+	return state, errors.Join(errs...)
+}
+
+func discoverSystem(system *schemas.ComputerSystem) (*systemState, error) {
+	state := &systemState{system: system}
+	var errs []error
+	var err error
+
+	state.processors, err = system.Processors()
+	errs = append(errs, err)
+	for _, processor := range state.processors {
+		metrics, err := processor.Metrics()
+		errs = append(errs, err)
+		state.processorMetrics = append(state.processorMetrics, metrics)
+	}
+
+	state.memory, err = system.Memory()
+	errs = append(errs, err)
+	for _, memory := range state.memory {
+		metrics, err := memory.Metrics()
+		errs = append(errs, err)
+		state.memoryMetrics = append(state.memoryMetrics, metrics)
+	}
+
+	return state, errors.Join(errs...)
+}
+
+// refresh re-reads the known resources of the target with conditional GETs.
+func (t *targetState) refresh() error {
+	var r refresher
+	for _, chassis := range t.chassis {
+		refreshObject(&r, &chassis.chassis)
+		refreshObjects(&r, chassis.sensors)
+		refreshObject(&r, &chassis.power)
+		refreshObject(&r, &chassis.thermal)
+		refreshObjects(&r, chassis.powerSupplies)
+		refreshObjects(&r, chassis.fans)
+	}
+	for _, system := range t.systems {
+		refreshObject(&r, &system.system)
+		refreshObjects(&r, system.processors)
+		refreshObjects(&r, system.processorMetrics)
+		refreshObjects(&r, system.memory)
+		refreshObjects(&r, system.memoryMetrics)
+	}
+	return r.wait()
+}
+
+// refresher refreshes objects concurrently. The client limits the number of
+// concurrent requests.
+type refresher struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *refresher) wait() error {
+	r.wg.Wait()
+	return errors.Join(r.errs...)
+}
+
+// refreshObject replaces *obj with its current version if it has changed.
+func refreshObject[T any, PT schemas.GenericSchemaObjectPointer[T]](r *refresher, obj *PT) {
+	if *obj == nil {
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		updated, err := schemas.Refresh[T, PT](*obj)
+		if errors.Is(err, schemas.ErrNotModified) {
+			return
+		}
+		if err != nil {
+			r.mu.Lock()
+			r.errs = append(r.errs, err)
+			r.mu.Unlock()
+			return
+		}
+		*obj = updated
+	}()
+}
+
+func refreshObjects[T any, PT schemas.GenericSchemaObjectPointer[T]](r *refresher, objs []PT) {
+	for i := range objs {
+		refreshObject(r, &objs[i])
+	}
+}
@@ -0,0 +1,230 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish"
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+var bmcResources = map[string]string{
+	"/redfish/v1/": `{"Chassis": {"@odata.id": "/redfish/v1/Chassis"}, "Systems": {"@odata.id": "/redfish/v1/Systems"}}`,
+
+	"/redfish/v1/Chassis": `{"Members": [{"@odata.id": "/redfish/v1/Chassis/1"}]}`,
+	"/redfish/v1/Chassis/1": `{"@odata.id": "/redfish/v1/Chassis/1", "Id": "1", "Status": {"Health": "OK"},
+		"Sensors": {"@odata.id": "/redfish/v1/Chassis/1/Sensors"},
+		"Power": {"@odata.id": "/redfish/v1/Chassis/1/Power"},
+		"Thermal": {"@odata.id": "/redfish/v1/Chassis/1/Thermal"}}`,
+	"/redfish/v1/Chassis/1/Sensors": `{"Members": [{"@odata.id": "/redfish/v1/Chassis/1/Sensors/CPU1Temp"}]}`,
+	"/redfish/v1/Chassis/1/Sensors/CPU1Temp": `{"@odata.id": "/redfish/v1/Chassis/1/Sensors/CPU1Temp", "Id": "CPU1Temp",
+		"Name": "CPU 1 Temp", "Reading": 45, "ReadingType": "Temperature", "ReadingUnits": "Cel",
+		"PhysicalContext": "CPU", "Status": {"Health": "OK"}}`,
+	"/redfish/v1/Chassis/1/Power": `{"@odata.id": "/redfish/v1/Chassis/1/Power",
+		"PowerControl": [{"MemberId": "0", "PowerConsumedWatts": 300}],
+		"PowerSupplies": [{"MemberId": "0", "Name": "PSU1", "PowerInputWatts": 200, "Status": {"Health": "Warning"}}]}`,
+	"/redfish/v1/Chassis/1/Thermal": `{"@odata.id": "/redfish/v1/Chassis/1/Thermal",
+		"Temperatures": [{"MemberId": "0", "ReadingCelsius": 40}],
+		"Fans": [{"MemberId": "0", "Name": "Fan1", "Reading": 3000, "ReadingUnits": "RPM", "Status": {"Health": "OK"}}]}`,
+
+	"/redfish/v1/Systems": `{"Members": [{"@odata.id": "/redfish/v1/Systems/1"}]}`,
+	"/redfish/v1/Systems/1": `{"@odata.id": "/redfish/v1/Systems/1", "Id": "1", "Status": {"Health": "Critical"},
+		"Processors": {"@odata.id": "/redfish/v1/Systems/1/Processors"},
+		"Memory": {"@odata.id": "/redfish/v1/Systems/1/Memory"}}`,
+	"/redfish/v1/Systems/1/Processors": `{"Members": [{"@odata.id": "/redfish/v1/Systems/1/Processors/CPU1"}]}`,
+	"/redfish/v1/Systems/1/Processors/CPU1": `{"@odata.id": "/redfish/v1/Systems/1/Processors/CPU1", "Id": "CPU1",
+		"Status": {"Health": "OK"}, "Metrics": {"@odata.id": "/redfish/v1/Systems/1/Processors/CPU1/ProcessorMetrics"}}`,
+	"/redfish/v1/Systems/1/Processors/CPU1/ProcessorMetrics": `{"@odata.id": "/redfish/v1/Systems/1/Processors/CPU1/ProcessorMetrics",
+		"TemperatureCelsius": 50, "CorrectableCoreErrorCount": 3}`,
+	"/redfish/v1/Systems/1/Memory": `{"Members": [{"@odata.id": "/redfish/v1/Systems/1/Memory/DIMM1"}]}`,
+	"/redfish/v1/Systems/1/Memory/DIMM1": `{"@odata.id": "/redfish/v1/Systems/1/Memory/DIMM1", "Id": "DIMM1",
+		"Metrics": {"@odata.id": "/redfish/v1/Systems/1/Memory/DIMM1/MemoryMetrics"}}`,
+	"/redfish/v1/Systems/1/Memory/DIMM1/MemoryMetrics": `{"@odata.id": "/redfish/v1/Systems/1/Memory/DIMM1/MemoryMetrics",
+		"LifeTime": {"CorrectableECCErrorCount": 7}}`,
+}
+
+// fakeBMC serves resources with ETags and answers conditional GETs.
+type fakeBMC struct {
+	mu        sync.Mutex
+	resources map[string]string
+	versions  map[string]int
+	reads     map[int]int
+}
+
+func newFakeBMC(t *testing.T) (*fakeBMC, *httptest.Server) {
+	bmc := &fakeBMC{resources: make(map[string]string), versions: make(map[string]int), reads: make(map[int]int)}
+	for uri, body := range bmcResources {
+		bmc.resources[uri] = body
+	}
+	ts := httptest.NewServer(bmc)
+	t.Cleanup(ts.Close)
+	return bmc, ts
+}
+
+func (b *fakeBMC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	body, ok := b.resources[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	etag := fmt.Sprintf(`"%d"`, b.versions[r.URL.Path])
+	w.Header().Set("Etag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		b.reads[http.StatusNotModified]++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	b.reads[http.StatusOK]++
+	w.Write([]byte(body)) //nolint
+}
+
+// update changes a resource and resets the read counts.
+func (b *fakeBMC) update(uri, oldValue, newValue string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resources[uri] = strings.Replace(b.resources[uri], oldValue, newValue, 1)
+	b.versions[uri]++
+	b.reads = make(map[int]int)
+}
+
+func (b *fakeBMC) readCounts() (ok, notModified int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reads[http.StatusOK], b.reads[http.StatusNotModified]
+}
+
+func scrape(t *testing.T, c *Collector) string {
+	var sb strings.Builder
+	if _, err := c.WriteTo(&sb); err != nil {
+		t.Fatalf("Error writing metrics: %s", err)
+	}
+	return sb.String()
+}
+
+func requireLines(t *testing.T, output string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Missing %q in output:\n%s", line, output)
+		}
+	}
+}
+
+// TestCollector tests metrics are collected and unchanged resources are
+// refreshed with conditional GETs.
+func TestCollector(t *testing.T) {
+	bmc, ts := newFakeBMC(t)
+	client, err := gofish.Connect(gofish.ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client()})
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+
+	var errs []error
+	collector := NewCollector(&Options{ErrorHandler: func(_ string, err error) { errs = append(errs, err) }},
+		Target{Name: "bmc1", Client: client})
+	output := scrape(t, collector)
+	if len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	requireLines(t, output,
+		`# TYPE redfish_sensor_reading gauge`,
+		`redfish_up{target="bmc1"} 1`,
+		`redfish_chassis_health{target="bmc1",chassis="1",health="OK"} 1`,
+		`redfish_sensor_reading{target="bmc1",chassis="1",sensor="CPU1Temp",name="CPU 1 Temp",physical_context="CPU",reading_type="Temperature",units="Cel"} 45`,
+		`redfish_power_supply_health{target="bmc1",chassis="1",power_supply="0",name="PSU1",health="Warning"} 1`,
+		`redfish_power_supply_health{target="bmc1",chassis="1",power_supply="0",name="PSU1",health="OK"} 0`,
+		`redfish_power_supply_input_watts{target="bmc1",chassis="1",power_supply="0",name="PSU1"} 200`,
+		`redfish_fan_health{target="bmc1",chassis="1",fan="0",name="Fan1",health="OK"} 1`,
+		`redfish_system_health{target="bmc1",system="1",health="Critical"} 1`,
+		`# TYPE redfish_processor_correctable_core_errors_total counter`,
+		`redfish_processor_correctable_core_errors_total{target="bmc1",system="1",processor="CPU1"} 3`,
+		`redfish_processor_temperature_celsius{target="bmc1",system="1",processor="CPU1"} 50`,
+		`redfish_memory_correctable_ecc_errors_total{target="bmc1",system="1",memory="DIMM1"} 7`,
+	)
+	// The sensors make the deprecated readings redundant
+	if strings.Contains(output, "redfish_temperature_celsius") || strings.Contains(output, "redfish_power_consumed_watts") {
+		t.Errorf("Unexpected deprecated readings in output:\n%s", output)
+	}
+
+	bmc.update("/redfish/v1/Chassis/1/Sensors/CPU1Temp", `"Reading": 45`, `"Reading": 47.5`)
+	output = scrape(t, collector)
+	requireLines(t, output, `redfish_sensor_reading{target="bmc1",chassis="1",sensor="CPU1Temp",name="CPU 1 Temp",physical_context="CPU",reading_type="Temperature",units="Cel"} 47.5`)
+	ok, notModified := bmc.readCounts()
+	schemas.AssertEqual(t, 1, ok)
+	schemas.AssertEqual(t, 8, notModified)
+}
+
+// TestCollectorLegacyReadings tests the deprecated Power and Thermal readings
+// are collected if there are no sensors, and targets that can't be read are
+// reported as down.
+func TestCollectorLegacyReadings(t *testing.T) {
+	bmc, ts := newFakeBMC(t)
+	delete(bmc.resources, "/redfish/v1/Chassis/1/Sensors")
+	// Members without a MemberId are told apart by their index
+	bmc.resources["/redfish/v1/Chassis/1/Thermal"] = `{"@odata.id": "/redfish/v1/Chassis/1/Thermal",
+		"Temperatures": [{"MemberId": "0", "ReadingCelsius": 40}],
+		"Fans": [{"Name": "Fan1", "Reading": 3000, "ReadingUnits": "RPM", "Status": {"Health": "OK"}},
+			{"Name": "Fan2", "Reading": 3100, "ReadingUnits": "RPM", "Status": {"Health": "OK"}}]}`
+	client, err := gofish.Connect(gofish.ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client()})
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+	var errs []error
+	collector := NewCollector(&Options{ErrorHandler: func(_ string, err error) { errs = append(errs, err) }},
+		Target{Name: "bmc1", Client: client})
+
+	output := scrape(t, collector)
+	requireLines(t, output,
+		`redfish_temperature_celsius{target="bmc1",chassis="1",sensor="0",name="",physical_context=""} 40`,
+		`redfish_power_consumed_watts{target="bmc1",chassis="1",sensor="0",name="",physical_context=""} 300`,
+		`redfish_fan_reading{target="bmc1",chassis="1",fan="0",name="Fan1",physical_context="",units="RPM"} 3000`,
+		`redfish_fan_reading{target="bmc1",chassis="1",fan="1",name="Fan2",physical_context="",units="RPM"} 3100`,
+		`redfish_fan_health{target="bmc1",chassis="1",fan="1",name="Fan2",health="OK"} 1`,
+	)
+	// The missing sensors collection is reported, but doesn't stop collection
+	schemas.AssertEqual(t, 1, len(errs))
+
+	bmc.mu.Lock()
+	delete(bmc.resources, "/redfish/v1/Systems")
+	bmc.mu.Unlock()
+	collector.opts.RediscoverInterval = time.Nanosecond
+	output = scrape(t, collector)
+	schemas.AssertEqual(t, "# HELP redfish_up Whether the resources of the Redfish service could be listed.\n"+
+		"# TYPE redfish_up gauge\n"+
+		"redfish_up{target=\"bmc1\"} 0\n", output)
+	var discoveryErr *discoveryError
+	if !errors.As(errs[1], &discoveryErr) {
+		t.Errorf("Expected a discovery error, got: %v", errs[1])
+	}
+}
+
+// TestCollectorServeHTTP tests the metrics are served in the Prometheus text
+// exposition format.
+func TestCollectorServeHTTP(t *testing.T) {
+	_, ts := newFakeBMC(t)
+	client, err := gofish.Connect(gofish.ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client()})
+	if err != nil {
+		t.Fatalf("Error connecting: %s", err)
+	}
+	collector := NewCollector(nil, Target{Name: "bmc1", Client: client})
+
+	recorder := httptest.NewRecorder()
+	collector.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	schemas.AssertEqual(t, http.StatusOK, recorder.Code)
+	schemas.AssertEqual(t, ExpositionContentType, recorder.Header().Get("Content-Type"))
+	requireLines(t, recorder.Body.String(), `redfish_up{target="bmc1"} 1`)
+}
@@ -0,0 +1,73 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/stmcginnis/gofish/internal/exposition"
+)
+
+// ExpositionContentType is the content type of the Prometheus text
+// exposition format written by WriteTo.
+const ExpositionContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteTo collects the metrics and writes them in the Prometheus text
+// exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	ch := make(chan Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+
+	families := make(map[*Desc]*exposition.Family)
+	for metric := range ch {
+		family, ok := families[metric.Desc]
+		if !ok {
+			family = &exposition.Family{Name: metric.Desc.Name, Help: metric.Desc.Help, Type: string(metric.Desc.Type)}
+			families[metric.Desc] = family
+		}
+		labels := make([]exposition.Label, len(metric.Desc.Labels))
+		for i, name := range metric.Desc.Labels {
+			labels[i] = exposition.Label{Name: name, Value: metric.LabelValues[i]}
+		}
+		family.Samples = append(family.Samples, exposition.Sample{Labels: labels, Value: metric.Value})
+	}
+
+	bw := bufio.NewWriter(w)
+	ew := exposition.NewWriter(bw)
+	for _, desc := range allDescs {
+		if family, ok := families[desc]; ok {
+			ew.WriteFamily(family)
+		}
+	}
+	n, err := ew.Result()
+	if err == nil {
+		err = bw.Flush()
+	}
+	return n, err
+}
+
+// ServeHTTP collects the metrics and writes them in the Prometheus text
+// exposition format, so the collector can be used as the handler of a
+// metrics endpoint. A 500 response is returned if the metrics can't be
+// written, and errors sending them are passed to the ErrorHandler.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ExpositionContentType)
+	if _, err := buf.WriteTo(w); err != nil && c.opts.ErrorHandler != nil {
+		c.opts.ErrorHandler("", fmt.Errorf("unable to send metrics: %w", err))
+	}
+}
@@ -0,0 +1,353 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package collector
+
+import (
+	"strconv"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// ValueType is the type of a metric.
+type ValueType string
+
+const (
+	// GaugeValue is a value that can go up and down.
+	GaugeValue ValueType = "gauge"
+	// CounterValue is a value that only increases, unless it is reset.
+	CounterValue ValueType = "counter"
+)
+
+// Desc describes a metric.
+type Desc struct {
+	// Name is the name of the metric.
+	Name string
+	// Help is the description of the metric.
+	Help string
+	// Type is the type of the metric.
+	Type ValueType
+	// Labels are the names of the labels of the metric.
+	Labels []string
+}
+
+// Metric is a single value of a metric.
+type Metric struct {
+	// Desc is the description of the metric.
+	Desc *Desc
+	// Value is the value of the metric.
+	Value float64
+	// LabelValues are the values of the labels, in the order of Desc.Labels.
+	LabelValues []string
+}
+
+func newMetric(desc *Desc, value float64, labelValues ...string) Metric {
+	return Metric{Desc: desc, Value: value, LabelValues: labelValues}
+}
+
+// Health states are exported as one metric per state, with the value 1 for
+// the current state and 0 for the others.
+var healthStates = []schemas.Health{schemas.OKHealth, schemas.WarningHealth, schemas.CriticalHealth}
+
+var (
+	upDesc = &Desc{
+		Name:   "redfish_up",
+		Help:   "Whether the resources of the Redfish service could be listed.",
+		Type:   GaugeValue,
+		Labels: []string{"target"},
+	}
+
+	chassisHealthDesc = &Desc{
+		Name:   "redfish_chassis_health",
+		Help:   "Health of the chassis.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "health"},
+	}
+	sensorReadingDesc = &Desc{
+		Name:   "redfish_sensor_reading",
+		Help:   "Reading of the sensor, in the units of the units label.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "sensor", "name", "physical_context", "reading_type", "units"},
+	}
+	sensorHealthDesc = &Desc{
+		Name:   "redfish_sensor_health",
+		Help:   "Health of the sensor.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "sensor", "name", "physical_context", "health"},
+	}
+	powerConsumedDesc = &Desc{
+		Name:   "redfish_power_consumed_watts",
+		Help:   "Power consumed, from the deprecated Power resource.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "sensor", "name", "physical_context"},
+	}
+	voltageDesc = &Desc{
+		Name:   "redfish_voltage_volts",
+		Help:   "Voltage reading, from the deprecated Power resource.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "sensor", "name", "physical_context"},
+	}
+	temperatureDesc = &Desc{
+		Name:   "redfish_temperature_celsius",
+		Help:   "Temperature reading, from the deprecated Thermal resource.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "sensor", "name", "physical_context"},
+	}
+	fanReadingDesc = &Desc{
+		Name:   "redfish_fan_reading",
+		Help:   "Fan speed, from the deprecated Thermal resource, in the units of the units label.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "fan", "name", "physical_context", "units"},
+	}
+	fanHealthDesc = &Desc{
+		Name:   "redfish_fan_health",
+		Help:   "Health of the fan.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "fan", "name", "health"},
+	}
+	powerSupplyHealthDesc = &Desc{
+		Name:   "redfish_power_supply_health",
+		Help:   "Health of the power supply.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "power_supply", "name", "health"},
+	}
+	powerSupplyInputDesc = &Desc{
+		Name:   "redfish_power_supply_input_watts",
+		Help:   "Input power of the power supply.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "power_supply", "name"},
+	}
+	powerSupplyOutputDesc = &Desc{
+		Name:   "redfish_power_supply_output_watts",
+		Help:   "Output power of the power supply.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "chassis", "power_supply", "name"},
+	}
+
+	systemHealthDesc = &Desc{
+		Name:   "redfish_system_health",
+		Help:   "Health of the computer system.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "system", "health"},
+	}
+	processorHealthDesc = &Desc{
+		Name:   "redfish_processor_health",
+		Help:   "Health of the processor.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "system", "processor", "health"},
+	}
+	processorTemperatureDesc = &Desc{
+		Name:   "redfish_processor_temperature_celsius",
+		Help:   "Temperature of the processor.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "system", "processor"},
+	}
+	processorPowerDesc = &Desc{
+		Name:   "redfish_processor_consumed_power_watts",
+		Help:   "Power consumed by the processor.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "system", "processor"},
+	}
+	processorCorrectableCoreErrorsDesc = &Desc{
+		Name:   "redfish_processor_correctable_core_errors_total",
+		Help:   "Correctable core errors of the processor.",
+		Type:   CounterValue,
+		Labels: []string{"target", "system", "processor"},
+	}
+	processorUncorrectableCoreErrorsDesc = &Desc{
+		Name:   "redfish_processor_uncorrectable_core_errors_total",
+		Help:   "Uncorrectable core errors of the processor.",
+		Type:   CounterValue,
+		Labels: []string{"target", "system", "processor"},
+	}
+	processorCorrectableOtherErrorsDesc = &Desc{
+		Name:   "redfish_processor_correctable_other_errors_total",
+		Help:   "Correctable errors of the processor other than core errors.",
+		Type:   CounterValue,
+		Labels: []string{"target", "system", "processor"},
+	}
+	processorUncorrectableOtherErrorsDesc = &Desc{
+		Name:   "redfish_processor_uncorrectable_other_errors_total",
+		Help:   "Uncorrectable errors of the processor other than core errors.",
+		Type:   CounterValue,
+		Labels: []string{"target", "system", "processor"},
+	}
+	memoryHealthDesc = &Desc{
+		Name:   "redfish_memory_health",
+		Help:   "Health of the memory device.",
+		Type:   GaugeValue,
+		Labels: []string{"target", "system", "memory", "health"},
+	}
+	memoryCorrectableErrorsDesc = &Desc{
+		Name:   "redfish_memory_correctable_ecc_errors_total",
+		Help:   "Correctable ECC errors of the memory device over its lifetime.",
+		Type:   CounterValue,
+		Labels: []string{"target", "system", "memory"},
+	}
+	memoryUncorrectableErrorsDesc = &Desc{
+		Name:   "redfish_memory_uncorrectable_ecc_errors_total",
+		Help:   "Uncorrectable ECC errors of the memory device over its lifetime.",
+		Type:   CounterValue,
+		Labels: []string{"target", "system", "memory"},
+	}
+)
+
+var allDescs = []*Desc{
+	upDesc,
+	chassisHealthDesc,
+	sensorReadingDesc,
+	sensorHealthDesc,
+	powerConsumedDesc,
+	voltageDesc,
+	temperatureDesc,
+	fanReadingDesc,
+	fanHealthDesc,
+	powerSupplyHealthDesc,
+	powerSupplyInputDesc,
+	powerSupplyOutputDesc,
+	systemHealthDesc,
+	processorHealthDesc,
+	processorTemperatureDesc,
+	processorPowerDesc,
+	processorCorrectableCoreErrorsDesc,
+	processorUncorrectableCoreErrorsDesc,
+	processorCorrectableOtherErrorsDesc,
+	processorUncorrectableOtherErrorsDesc,
+	memoryHealthDesc,
+	memoryCorrectableErrorsDesc,
+	memoryUncorrectableErrorsDesc,
+}
+
+// sendHealth sends the health state metrics of a resource. The health label
+// is the last label of the desc. Nothing is sent if the health is not known.
+func sendHealth(ch chan<- Metric, desc *Desc, health schemas.Health, labelValues ...string) {
+	if health == "" {
+		return
+	}
+	for _, state := range healthStates {
+		value := 0.0
+		if state == health {
+			value = 1
+		}
+		ch <- newMetric(desc, value, append(labelValues[:len(labelValues):len(labelValues)], string(state))...)
+	}
+}
+
+// sendValue sends a metric if the value is known.
+func sendValue[N float32 | float64 | int](ch chan<- Metric, desc *Desc, value *N, labelValues ...string) {
+	if value != nil {
+		ch <- newMetric(desc, float64(*value), labelValues...)
+	}
+}
+
+// memberID is the identifier of a member of a resource array, or of a
+// resource collection member. Services don't always set the MemberId of array
+// members, so the index is used if there is no other identifier, to keep the
+// label sets of the members distinct.
+func memberID(memberID, id string, index int) string {
+	switch {
+	case memberID != "":
+		return memberID
+	case id != "":
+		return id
+	}
+	return strconv.Itoa(index)
+}
+
+func (s *chassisState) collect(target string, ch chan<- Metric) {
+	chassisID := s.chassis.ID
+	sendHealth(ch, chassisHealthDesc, s.chassis.Status.Health, target, chassisID)
+
+	for _, sensor := range s.sensors {
+		labels := []string{target, chassisID, sensor.ID, sensor.Name, string(sensor.PhysicalContext)}
+		sendValue(ch, sensorReadingDesc, sensor.Reading, append(labels, string(sensor.ReadingType), sensor.ReadingUnits)...)
+		sendHealth(ch, sensorHealthDesc, sensor.Status.Health, labels...)
+	}
+
+	for i, supply := range s.powerSupplies {
+		collectPowerSupply(ch, supply, i, target, chassisID)
+	}
+	for i, fan := range s.fans {
+		sendHealth(ch, fanHealthDesc, fan.Status.Health, target, chassisID, memberID(fan.MemberID, fan.ID, i), fan.Name)
+	}
+
+	// Readings from the deprecated resources duplicate the sensors, and
+	// their power supplies and fans the subsystems
+	legacyReadings := len(s.sensors) == 0
+	if s.power != nil {
+		if legacyReadings {
+			for i := range s.power.PowerControl {
+				control := &s.power.PowerControl[i]
+				sendValue(ch, powerConsumedDesc, control.PowerConsumedWatts,
+					target, chassisID, memberID(control.MemberID, control.ID, i), control.Name, string(control.PhysicalContext))
+			}
+			for i := range s.power.Voltages {
+				voltage := &s.power.Voltages[i]
+				sendValue(ch, voltageDesc, voltage.ReadingVolts,
+					target, chassisID, memberID(voltage.MemberID, voltage.ID, i), voltage.Name, string(voltage.PhysicalContext))
+			}
+		}
+		if len(s.powerSupplies) == 0 {
+			for i := range s.power.PowerSupplies {
+				collectPowerSupply(ch, &s.power.PowerSupplies[i], i, target, chassisID)
+			}
+		}
+	}
+	if s.thermal != nil {
+		if legacyReadings {
+			for i := range s.thermal.Temperatures {
+				temperature := &s.thermal.Temperatures[i]
+				sendValue(ch, temperatureDesc, temperature.ReadingCelsius,
+					target, chassisID, memberID(temperature.MemberID, temperature.ID, i), temperature.Name, string(temperature.PhysicalContext))
+			}
+			for i := range s.thermal.Fans {
+				fan := &s.thermal.Fans[i]
+				sendValue(ch, fanReadingDesc, fan.Reading,
+					target, chassisID, memberID(fan.MemberID, fan.ID, i), fan.Name, string(fan.PhysicalContext), string(fan.ReadingUnits))
+			}
+		}
+		if len(s.fans) == 0 {
+			for i := range s.thermal.Fans {
+				fan := &s.thermal.Fans[i]
+				sendHealth(ch, fanHealthDesc, fan.Status.Health, target, chassisID, memberID(fan.MemberID, fan.ID, i), fan.Name)
+			}
+		}
+	}
+}
+
+func collectPowerSupply(ch chan<- Metric, supply *schemas.PowerSupply, index int, target, chassisID string) {
+	id := memberID(supply.MemberID, supply.ID, index)
+	sendHealth(ch, powerSupplyHealthDesc, supply.Status.Health, target, chassisID, id, supply.Name)
+	sendValue(ch, powerSupplyInputDesc, supply.PowerInputWatts, target, chassisID, id, supply.Name)
+	sendValue(ch, powerSupplyOutputDesc, supply.PowerOutputWatts, target, chassisID, id, supply.Name)
+}
+
+func (s *systemState) collect(target string, ch chan<- Metric) {
+	systemID := s.system.ID
+	sendHealth(ch, systemHealthDesc, s.system.Status.Health, target, systemID)
+
+	for i, processor := range s.processors {
+		sendHealth(ch, processorHealthDesc, processor.Status.Health, target, systemID, processor.ID)
+		metrics := s.processorMetrics[i]
+		if metrics == nil {
+			continue
+		}
+		sendValue(ch, processorTemperatureDesc, metrics.TemperatureCelsius, target, systemID, processor.ID)
+		sendValue(ch, processorPowerDesc, metrics.ConsumedPowerWatt, target, systemID, processor.ID)
+		sendValue(ch, processorCorrectableCoreErrorsDesc, metrics.CorrectableCoreErrorCount, target, systemID, processor.ID)
+		sendValue(ch, processorUncorrectableCoreErrorsDesc, metrics.UncorrectableCoreErrorCount, target, systemID, processor.ID)
+		sendValue(ch, processorCorrectableOtherErrorsDesc, metrics.CorrectableOtherErrorCount, target, systemID, processor.ID)
+		sendValue(ch, processorUncorrectableOtherErrorsDesc, metrics.UncorrectableOtherErrorCount, target, systemID, processor.ID)
+	}
+
+	for i, memory := range s.memory {
+		sendHealth(ch, memoryHealthDesc, memory.Status.Health, target, systemID, memory.ID)
+		metrics := s.memoryMetrics[i]
+		if metrics == nil {
+			continue
+		}
+		sendValue(ch, memoryCorrectableErrorsDesc, metrics.LifeTime.CorrectableECCErrorCount, target, systemID, memory.ID)
+		sendValue(ch, memoryUncorrectableErrorsDesc, metrics.LifeTime.UncorrectableECCErrorCount, target, systemID, memory.ID)
+	}
+}
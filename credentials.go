@@ -0,0 +1,128 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// DefaultUsernameEnv is the environment variable read by EnvCredentials
+	// if no variable name is given.
+	DefaultUsernameEnv = "REDFISH_USERNAME"
+	// DefaultPasswordEnv is the environment variable read by EnvCredentials
+	// if no variable name is given.
+	DefaultPasswordEnv = "REDFISH_PASSWORD"
+)
+
+// Credentials holds the user name and password used to authenticate.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// CredentialProvider supplies credentials for an endpoint. It is consulted
+// when connecting and whenever the session needs to be renewed, so rotated
+// credentials are picked up without restarting.
+type CredentialProvider interface {
+	Credentials(ctx context.Context, endpoint string) (*Credentials, error)
+}
+
+// StaticCredentials is a CredentialProvider that returns fixed credentials
+// held in memory.
+type StaticCredentials Credentials
+
+// Credentials returns the static credentials.
+func (s StaticCredentials) Credentials(_ context.Context, _ string) (*Credentials, error) {
+	return &Credentials{Username: s.Username, Password: s.Password}, nil
+}
+
+// EnvCredentials is a CredentialProvider that reads credentials from
+// environment variables.
+type EnvCredentials struct {
+	// UsernameVar is the variable holding the user name. Defaults to
+	// REDFISH_USERNAME.
+	UsernameVar string
+	// PasswordVar is the variable holding the password. Defaults to
+	// REDFISH_PASSWORD.
+	PasswordVar string
+}
+
+// Credentials reads the credentials from the environment.
+func (e EnvCredentials) Credentials(_ context.Context, _ string) (*Credentials, error) {
+	usernameVar := e.UsernameVar
+	if usernameVar == "" {
+		usernameVar = DefaultUsernameEnv
+	}
+	passwordVar := e.PasswordVar
+	if passwordVar == "" {
+		passwordVar = DefaultPasswordEnv
+	}
+
+	username, ok := os.LookupEnv(usernameVar)
+	if !ok || username == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", usernameVar)
+	}
+
+	return &Credentials{Username: username, Password: os.Getenv(passwordVar)}, nil
+}
+
+// FileCredentials is a CredentialProvider that reads credentials from a JSON
+// file containing "Username" and "Password" properties. The file is read each
+// time credentials are needed.
+type FileCredentials struct {
+	Path string
+}
+
+// Credentials reads the credentials from the file.
+func (f FileCredentials) Credentials(_ context.Context, _ string) (*Credentials, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCredentials(data)
+}
+
+// ExecCredentials is a CredentialProvider that runs a command, such as a
+// password manager CLI, to get credentials. The command must write a JSON
+// object containing "Username" and "Password" properties to stdout. The
+// endpoint is passed to the command in the REDFISH_ENDPOINT environment
+// variable.
+type ExecCredentials struct {
+	Command string
+	Args    []string
+}
+
+// Credentials runs the command and parses its output.
+func (e ExecCredentials) Credentials(ctx context.Context, endpoint string) (*Credentials, error) {
+	cmd := exec.CommandContext(ctx, e.Command, e.Args...) //nolint:gosec
+	cmd.Env = append(os.Environ(), "REDFISH_ENDPOINT="+endpoint)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseCredentials(out)
+}
+
+func parseCredentials(data []byte) (*Credentials, error) {
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("invalid credentials: %w", err)
+	}
+	if creds.Username == "" {
+		return nil, errors.New("invalid credentials: no username provided")
+	}
+	return &creds, nil
+}
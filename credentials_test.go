@@ -0,0 +1,68 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// TestEnvCredentials tests reading credentials from the environment.
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("BMC_USER", "operator")
+	t.Setenv("BMC_PASS", "secret")
+
+	creds, err := EnvCredentials{UsernameVar: "BMC_USER", PasswordVar: "BMC_PASS"}.Credentials(context.Background(), "")
+	if err != nil {
+		t.Fatalf("failed to get credentials: %v", err)
+	}
+	schemas.AssertEqual(t, "operator", creds.Username)
+	schemas.AssertEqual(t, "secret", creds.Password)
+
+	_, err = EnvCredentials{UsernameVar: "BMC_MISSING"}.Credentials(context.Background(), "")
+	schemas.RequireErrorContains(t, err, "BMC_MISSING is not set")
+}
+
+// TestFileCredentials tests reading credentials from a file.
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "creds.json")
+	if err := os.WriteFile(path, []byte(`{"Username": "admin", "Password": "hunter2"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := FileCredentials{Path: path}.Credentials(context.Background(), "")
+	if err != nil {
+		t.Fatalf("failed to get credentials: %v", err)
+	}
+	schemas.AssertEqual(t, "admin", creds.Username)
+	schemas.AssertEqual(t, "hunter2", creds.Password)
+}
+
+// TestExecCredentials tests getting credentials from a command.
+func TestExecCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	provider := ExecCredentials{
+		Command: "sh",
+		Args:    []string{"-c", `echo "{\"Username\": \"svc\", \"Password\": \"$REDFISH_ENDPOINT\"}"`},
+	}
+	creds, err := provider.Credentials(context.Background(), "https://bmc1")
+	if err != nil {
+		t.Fatalf("failed to get credentials: %v", err)
+	}
+	schemas.AssertEqual(t, "svc", creds.Username)
+	schemas.AssertEqual(t, "https://bmc1", creds.Password)
+
+	_, err = ExecCredentials{Command: "sh", Args: []string{"-c", "echo denied >&2; exit 1"}}.
+		Credentials(context.Background(), "")
+	schemas.RequireErrorContains(t, err, "denied")
+}
@@ -0,0 +1,142 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+// Package exposition writes metrics in the Prometheus text exposition and
+// OpenMetrics text formats.
+package exposition
+
+import (
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Label is a label of a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a value of a metric.
+type Sample struct {
+	// Labels are the labels of the sample, in the order they are written.
+	Labels []Label
+	// Value is the value of the sample.
+	Value float64
+	// Timestamp is when the value was read, or zero if not known.
+	Timestamp time.Time
+}
+
+// Family is a metric and its samples.
+type Family struct {
+	// Name is the name of the metric.
+	Name string
+	// Help is the description of the metric, if any.
+	Help string
+	// Type is the type of the metric, such as "gauge".
+	Type string
+	// Unit is the OpenMetrics unit of the metric, if any.
+	Unit string
+	// Samples are the values of the metric.
+	Samples []Sample
+}
+
+// Writer writes metric families. It keeps the first error, after which
+// nothing more is written.
+type Writer struct {
+	w   io.Writer
+	n   int64
+	err error
+	// OpenMetrics writes the OpenMetrics format instead of the Prometheus
+	// text format.
+	OpenMetrics bool
+	// OmitTimestamps leaves out the sample timestamps.
+	OmitTimestamps bool
+}
+
+// NewWriter creates a Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteFamily writes the family. A series can only have one sample in an
+// exposition, so only the newest sample of each label set is written. Samples
+// are written in the order of their labels, for a stable output.
+func (w *Writer) WriteFamily(family *Family) {
+	if family.Help != "" {
+		w.writeString("# HELP " + family.Name + " " + family.Help + "\n")
+	}
+	w.writeString("# TYPE " + family.Name + " " + family.Type + "\n")
+	if w.OpenMetrics && family.Unit != "" {
+		w.writeString("# UNIT " + family.Name + " " + family.Unit + "\n")
+	}
+
+	newest := make(map[string]Sample)
+	for _, sample := range family.Samples {
+		labels := formatLabels(sample.Labels)
+		if current, found := newest[labels]; found && sample.Timestamp.Before(current.Timestamp) {
+			continue
+		}
+		newest[labels] = sample
+	}
+
+	lines := make([]string, 0, len(newest))
+	for labels, sample := range newest {
+		line := family.Name + labels + " " + strconv.FormatFloat(sample.Value, 'g', -1, 64)
+		if !w.OmitTimestamps && !sample.Timestamp.IsZero() {
+			if w.OpenMetrics {
+				line += " " + strconv.FormatFloat(float64(sample.Timestamp.UnixMilli())/1000, 'f', -1, 64)
+			} else {
+				line += " " + strconv.FormatInt(sample.Timestamp.UnixMilli(), 10)
+			}
+		}
+		lines = append(lines, line)
+	}
+	slices.Sort(lines)
+	for _, line := range lines {
+		w.writeString(line + "\n")
+	}
+}
+
+// WriteEOF ends an OpenMetrics exposition.
+func (w *Writer) WriteEOF() {
+	if w.OpenMetrics {
+		w.writeString("# EOF\n")
+	}
+}
+
+// Result returns the number of bytes written and the first error.
+func (w *Writer) Result() (int64, error) {
+	return w.n, w.err
+}
+
+func (w *Writer) writeString(s string) {
+	if w.err != nil {
+		return
+	}
+	n, err := io.WriteString(w.w, s)
+	w.n += int64(n)
+	w.err = err
+}
+
+// formatLabels formats the labels as written after the metric name.
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, label := range labels {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(label.Name + `="` + labelValueEscaper.Replace(label.Value) + `"`)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package exposition
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWriteFamily tests families are written with escaped labels, keeping
+// the newest sample of each label set.
+func TestWriteFamily(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 8, 0, 0, 500000000, time.UTC)
+	family := &Family{
+		Name: "redfish_reading_celsius",
+		Help: "Reading of the sensor.",
+		Type: "gauge",
+		Unit: "celsius",
+		Samples: []Sample{
+			{Labels: []Label{{"sensor", `Inlet "1"`}}, Value: 24, Timestamp: timestamp.Add(time.Minute)},
+			{Labels: []Label{{"sensor", "CPU1"}}, Value: 45, Timestamp: timestamp},
+			{Labels: []Label{{"sensor", `Inlet "1"`}}, Value: 23, Timestamp: timestamp},
+		},
+	}
+
+	var sb strings.Builder
+	w := NewWriter(&sb)
+	w.WriteFamily(family)
+	w.WriteEOF()
+	if _, err := w.Result(); err != nil {
+		t.Fatalf("Error writing: %s", err)
+	}
+	expected := `# HELP redfish_reading_celsius Reading of the sensor.
+# TYPE redfish_reading_celsius gauge
+redfish_reading_celsius{sensor="CPU1"} 45 1714550400500
+redfish_reading_celsius{sensor="Inlet \"1\""} 24 1714550460500
+`
+	if sb.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+
+	sb.Reset()
+	w = NewWriter(&sb)
+	w.OpenMetrics = true
+	w.OmitTimestamps = true
+	w.WriteFamily(family)
+	w.WriteEOF()
+	expected = `# HELP redfish_reading_celsius Reading of the sensor.
+# TYPE redfish_reading_celsius gauge
+# UNIT redfish_reading_celsius celsius
+redfish_reading_celsius{sensor="CPU1"} 45
+redfish_reading_celsius{sensor="Inlet \"1\""} 24
+# EOF
+`
+	if sb.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
@@ -8,6 +8,14 @@ import (
 	"github.com/stmcginnis/gofish/schemas"
 )
 
+// Dell job states that indicate the job has finished.
+const (
+	completedJobState           = "Completed"
+	completedWithErrorsJobState = "CompletedWithErrors"
+	failedJobState              = "Failed"
+	rebootFailedJobState        = "RebootFailed"
+)
+
 type Job struct {
 	schemas.Entity
 	schemas.Message
@@ -35,3 +43,35 @@ type Job struct {
 func GetJob(c schemas.Client, uri string) (*Job, error) {
 	return schemas.GetObject[Job](c, uri)
 }
+
+// Poll retrieves the current status of the job so that it can be waited on
+// with schemas.Await. If c is nil, the job's own client is used.
+func (j *Job) Poll(c schemas.Client) (*schemas.WorkStatus, error) {
+	if c == nil {
+		c = j.GetClient()
+	}
+
+	job, err := GetJob(c, j.ODataID)
+	if err != nil {
+		return nil, err
+	}
+
+	percent := uint(max(job.PercentComplete, 0))
+	status := &schemas.WorkStatus{
+		State:           job.JobState,
+		PercentComplete: &percent,
+	}
+	if job.Message.Message != "" || job.MessageID != "" {
+		status.Messages = []schemas.Message{job.Message}
+	}
+
+	switch job.JobState {
+	case completedJobState:
+		status.Done = true
+	case completedWithErrorsJobState, failedJobState, rebootFailedJobState:
+		status.Done = true
+		status.Failure = schemas.ErrTaskException
+	}
+
+	return status, nil
+}
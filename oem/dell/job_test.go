@@ -0,0 +1,70 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package dell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+const jobBody = `{
+    "@odata.id": "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/Jobs/JID_123",
+    "Id": "JID_123",
+    "Name": "Firmware Update: BIOS",
+    "JobState": "%s",
+    "JobType": "FirmwareUpdate",
+    "Message": "%s",
+    "MessageId": "%s",
+    "PercentComplete": %d
+}`
+
+func jobCall(state, message, messageID string, percent int) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(jobBody, state, message, messageID, percent))),
+		Header:     make(http.Header),
+	}
+}
+
+// TestJobAwait tests waiting for a Dell job to fail.
+func TestJobAwait(t *testing.T) {
+	testClient := &schemas.TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				jobCall("Running", "Job in progress.", "RED031", 40),
+				jobCall("Failed", "Unable to apply the update.", "RED007", 100),
+			},
+		},
+	}
+
+	job := &Job{Entity: schemas.Entity{ODataID: "/redfish/v1/Managers/iDRAC.Embedded.1/Oem/Dell/Jobs/JID_123"}}
+
+	var percents []uint
+	status, err := schemas.Await(context.Background(), testClient, job, &schemas.AwaitOptions{
+		PollRate:   time.Millisecond,
+		OnProgress: func(s *schemas.WorkStatus) { percents = append(percents, *s.PercentComplete) },
+	})
+
+	if !errors.Is(err, schemas.ErrTaskException) {
+		t.Fatalf("Expected ErrTaskException, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Unable to apply the update.") {
+		t.Errorf("Expected job message in error, got: %s", err)
+	}
+	if status.State != "Failed" {
+		t.Errorf("Unexpected final state: %s", status.State)
+	}
+	if fmt.Sprint(percents) != "[40 100]" {
+		t.Errorf("Unexpected progress: %v", percents)
+	}
+}
@@ -0,0 +1,249 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// PasswordRotation describes a password change for an account.
+type PasswordRotation struct {
+	// UserName is the account to change. Defaults to the account the client
+	// is authenticated as.
+	UserName string
+	// CurrentPassword is the account's current password, used to roll back
+	// if the new password cannot be verified. Defaults to the client's
+	// password when rotating the client's own account.
+	CurrentPassword string
+	// NewPassword is the password to set.
+	NewPassword string
+}
+
+// String returns a description of the rotation without the passwords.
+func (r PasswordRotation) String() string { //nolint:gocritic
+	return fmt.Sprintf("password rotation for %q", r.UserName)
+}
+
+// LogValue keeps the passwords out of structured logs.
+func (r PasswordRotation) LogValue() slog.Value { //nolint:gocritic
+	return slog.GroupValue(slog.String("UserName", r.UserName))
+}
+
+// PasswordRotationResult reports the outcome of a password rotation. It never
+// contains the passwords.
+type PasswordRotationResult struct {
+	// Endpoint is the service the rotation was performed on.
+	Endpoint string
+	// UserName is the account that was changed.
+	UserName string
+	// Method is how the password was changed, either "ChangePassword" or
+	// "PATCH".
+	Method string
+	// Changed is true if the service accepted the new password.
+	Changed bool
+	// Verified is true if a new session could be created with the new
+	// password.
+	Verified bool
+	// PasswordChangeRequired is true if the service accepted the new
+	// password but requires it to be changed again before granting access.
+	PasswordChangeRequired bool
+	// RolledBack is true if verification failed and the old password was
+	// restored.
+	RolledBack bool
+	// Err is the reason the rotation failed, if it did.
+	Err error
+}
+
+// PasswordRotationTarget is a client and the rotation to perform with it.
+type PasswordRotationTarget struct {
+	Client   *APIClient
+	Rotation PasswordRotation
+}
+
+// RotatePasswords rotates passwords across many services, running up to
+// concurrency rotations at once. A result is returned for every target, in
+// the same order. Rotations still waiting to start when ctx is cancelled are
+// not started, and report the context's error.
+func RotatePasswords(ctx context.Context, targets []PasswordRotationTarget, concurrency int) []*PasswordRotationResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]*PasswordRotationResult, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rotation := targets[i].Rotation
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+			}
+			if err := ctx.Err(); err != nil {
+				results[i] = &PasswordRotationResult{
+					Endpoint: targets[i].Client.endpoint,
+					UserName: rotation.UserName,
+					Err:      err,
+				}
+				return
+			}
+
+			results[i] = targets[i].Client.RotatePassword(ctx, &rotation)
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// RotatePassword changes an account password, then verifies the new password
+// by creating a separate session with it. If verification fails the old
+// password is restored. The client's own session is kept usable: when the
+// client's account is rotated its stored password is updated, and the session
+// is renewed if the service ended it.
+//
+// Request dumps are disabled for the requests made, so the passwords are never
+// written to the client's DumpWriter.
+func (c *APIClient) RotatePassword(ctx context.Context, rotation *PasswordRotation) *PasswordRotationResult {
+	quiet := c.WithContext(ctx)
+	quiet.dumpWriter = nil
+
+	result := &PasswordRotationResult{Endpoint: c.endpoint, UserName: rotation.UserName}
+
+	ownAccount := c.auth != nil && (rotation.UserName == "" || rotation.UserName == c.auth.Username)
+	if ownAccount {
+		result.UserName = c.auth.Username
+	}
+	if result.UserName == "" {
+		result.Err = errors.New("no account given to rotate")
+		return result
+	}
+
+	oldPassword := rotation.CurrentPassword
+	if oldPassword == "" && ownAccount {
+		oldPassword = c.auth.Password
+	}
+
+	// A client holding a restricted session can only change its own password
+	if ownAccount && c.passwordChangeAccount != "" {
+		result.Method = "PATCH"
+		result.Err = c.ChangeRequiredPassword(rotation.NewPassword)
+		result.Changed = result.Err == nil
+		result.Verified = result.Changed
+		return result
+	}
+
+	accountService, err := quiet.Service.AccountService()
+	if err != nil || accountService == nil {
+		result.Err = fmt.Errorf("unable to get account service: %w", err)
+		return result
+	}
+	if err := accountService.ValidatePassword(rotation.NewPassword); err != nil {
+		result.Err = err
+		return result
+	}
+
+	account, err := accountService.FindAccount(result.UserName)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if account == nil {
+		result.Err = fmt.Errorf("account %s not found", result.UserName)
+		return result
+	}
+
+	sessionPassword := oldPassword
+	if c.auth != nil && c.auth.Password != "" {
+		sessionPassword = c.auth.Password
+	}
+
+	result.Method, err = account.SetPassword(rotation.NewPassword, sessionPassword)
+	if err != nil {
+		result.Err = fmt.Errorf("unable to change password: %w", err)
+		return result
+	}
+	result.Changed = true
+
+	if ownAccount {
+		c.auth.Password = rotation.NewPassword
+		quiet.keepSessionAlive()
+	}
+
+	err = quiet.verifyCredentials(result.UserName, rotation.NewPassword)
+	if errors.Is(err, schemas.ErrPasswordChangeRequired) {
+		result.PasswordChangeRequired = true
+		err = nil
+	}
+	if err == nil {
+		result.Verified = true
+		return result
+	}
+	result.Err = fmt.Errorf("unable to verify new password: %w", err)
+
+	if oldPassword == "" {
+		return result
+	}
+
+	// Restore the old password using the still valid session
+	if ownAccount {
+		sessionPassword = rotation.NewPassword
+	}
+	if _, rollbackErr := account.SetPassword(oldPassword, sessionPassword); rollbackErr != nil {
+		result.Err = errors.Join(result.Err, fmt.Errorf("unable to roll back password: %w", rollbackErr))
+		return result
+	}
+	result.RolledBack = true
+	if ownAccount {
+		c.auth.Password = oldPassword
+		quiet.keepSessionAlive()
+	}
+
+	return result
+}
+
+// keepSessionAlive renews the client's session if the service ended it, such
+// as services that end sessions when the account password changes.
+func (c *APIClient) keepSessionAlive() {
+	if c.auth == nil || c.auth.BasicAuth || c.auth.Session == "" {
+		return
+	}
+
+	resp, err := c.Get(c.auth.Session)
+	schemas.DeferredCleanupHTTPResponse(resp)
+	if err != nil {
+		_ = c.renewSession(false)
+	}
+}
+
+// verifyCredentials checks the credentials by creating, then removing, a new
+// session with them.
+func (c *APIClient) verifyCredentials(username, password string) error {
+	verifier := c.WithContext(c.ctx)
+	verifier.dumpWriter = nil
+	verifier.auth = nil
+	verifier.bearer = nil
+
+	auth, err := verifier.Service.CreateSession(username, password)
+	var passwordErr *schemas.PasswordChangeRequiredError
+	if errors.As(err, &passwordErr) {
+		auth = passwordErr.Auth
+	}
+	if auth != nil && auth.Session != "" {
+		verifier.auth = auth
+		_ = verifier.Service.DeleteSession(auth.Session)
+	}
+
+	return err
+}
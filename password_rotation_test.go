@@ -0,0 +1,215 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// rotationServer is a minimal service that tracks account passwords and
+// sessions. Changing a password ends all of that account's sessions.
+type rotationServer struct {
+	mu        sync.Mutex
+	passwords map[string]string
+	sessions  map[string]string
+	next      int
+	// ignorePatch accepts password changes without applying them.
+	ignorePatch bool
+	patches     []string
+}
+
+func newRotationServer(t *testing.T, s *rotationServer) *httptest.Server {
+	s.sessions = make(map[string]string)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/":
+			w.Write([]byte(`{"AccountService": {"@odata.id": "/redfish/v1/AccountService"}, ` + //nolint
+				`"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/SessionService/Sessions":
+			var body schemas.SessionCreateParameters
+			json.NewDecoder(r.Body).Decode(&body) //nolint
+			if s.passwords[body.UserName] != body.Password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			s.next++
+			id := fmt.Sprintf("/redfish/v1/SessionService/Sessions/%d", s.next)
+			s.sessions[id] = body.UserName
+			w.Header().Set("X-Auth-Token", id)
+			w.Header().Set("Location", id)
+			w.WriteHeader(http.StatusCreated)
+		case s.sessions[r.Header.Get("X-Auth-Token")] == "":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/AccountService":
+			w.Write([]byte(`{"@odata.id": "/redfish/v1/AccountService", "MinPasswordLength": 8, ` + //nolint
+				`"Accounts": {"@odata.id": "/redfish/v1/AccountService/Accounts"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/AccountService/Accounts":
+			w.Write([]byte(`{"Members": [{"@odata.id": "/redfish/v1/AccountService/Accounts/1", ` + //nolint
+				`"Id": "1", "UserName": "admin"}, {"@odata.id": "/redfish/v1/AccountService/Accounts/2", ` +
+				`"Id": "2", "UserName": "operator"}]}`))
+		case r.Method == http.MethodGet && s.sessions[r.URL.Path] != "":
+			w.Write([]byte(`{}`)) //nolint
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/redfish/v1/AccountService/Accounts/"):
+			var body struct{ Password string }
+			json.NewDecoder(r.Body).Decode(&body) //nolint
+			user := map[string]string{"1": "admin", "2": "operator"}[r.URL.Path[len("/redfish/v1/AccountService/Accounts/"):]]
+			s.patches = append(s.patches, user)
+			if !s.ignorePatch {
+				s.passwords[user] = body.Password
+				for id, owner := range s.sessions {
+					if owner == user {
+						delete(s.sessions, id)
+					}
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete:
+			delete(s.sessions, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestRotateOwnPassword verifies the client's own password is rotated and the
+// client keeps working after the service ends its session.
+func TestRotateOwnPassword(t *testing.T) {
+	server := &rotationServer{passwords: map[string]string{"admin": "old-password"}}
+	ts := newRotationServer(t, server)
+
+	client, err := Connect(ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client(), Username: "admin", Password: "old-password"})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	result := client.RotatePassword(context.Background(), &PasswordRotation{NewPassword: "new-password"})
+	if result.Err != nil {
+		t.Fatalf("failed to rotate password: %v", result.Err)
+	}
+	schemas.AssertEqual(t, "admin", result.UserName)
+	schemas.AssertEqual(t, http.MethodPatch, result.Method)
+	schemas.AssertEqual(t, true, result.Verified)
+	schemas.AssertEqual(t, "new-password", server.passwords["admin"])
+
+	if _, err := client.Service.AccountService(); err != nil {
+		t.Errorf("client should still be usable after rotation: %v", err)
+	}
+	// Only the client's own session should remain
+	schemas.AssertEqual(t, 1, len(server.sessions))
+}
+
+// TestRotatePasswordRollback verifies the old password is restored when the
+// new password cannot be used to log in.
+func TestRotatePasswordRollback(t *testing.T) {
+	server := &rotationServer{passwords: map[string]string{"admin": "admin-password", "operator": "old-password"}}
+	ts := newRotationServer(t, server)
+
+	client, err := Connect(ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client(), Username: "admin", Password: "admin-password"})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	server.ignorePatch = true
+
+	results := RotatePasswords(context.Background(), []PasswordRotationTarget{
+		{Client: client, Rotation: PasswordRotation{UserName: "operator", CurrentPassword: "old-password", NewPassword: "new-password"}},
+		{Client: client, Rotation: PasswordRotation{UserName: "operator", NewPassword: "short"}},
+	}, 1)
+
+	schemas.AssertEqual(t, 2, len(results))
+	schemas.AssertEqual(t, true, results[0].Changed)
+	schemas.AssertEqual(t, false, results[0].Verified)
+	schemas.AssertEqual(t, true, results[0].RolledBack)
+	schemas.RequireErrorContains(t, results[0].Err, "unable to verify new password")
+	schemas.AssertEqual(t, "operator,operator", strings.Join(server.patches, ","))
+
+	if results[1].Changed || results[1].Err == nil {
+		t.Errorf("expected the short password to be rejected locally, got: %+v", results[1])
+	}
+}
+
+// TestRotatePasswordsCancelled verifies rotations are not started once the
+// context is cancelled.
+func TestRotatePasswordsCancelled(t *testing.T) {
+	server := &rotationServer{passwords: map[string]string{"admin": "admin-password", "operator": "old-password"}}
+	ts := newRotationServer(t, server)
+
+	client, err := Connect(ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client(), Username: "admin", Password: "admin-password"})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := RotatePasswords(ctx, []PasswordRotationTarget{
+		{Client: client, Rotation: PasswordRotation{UserName: "operator", NewPassword: "new-password"}},
+		{Client: client, Rotation: PasswordRotation{UserName: "operator", NewPassword: "newer-password"}},
+	}, 1)
+
+	for _, result := range results {
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("expected the rotation to be cancelled, got: %v", result.Err)
+		}
+	}
+	schemas.AssertEqual(t, 0, len(server.patches))
+}
+
+// TestRotatePasswordStoredSession verifies a client using a stored session
+// still knows its password, so a failed rotation can be rolled back.
+func TestRotatePasswordStoredSession(t *testing.T) {
+	server := &rotationServer{passwords: map[string]string{"admin": "old-password"}}
+	ts := newRotationServer(t, server)
+
+	store, err := NewEncryptedFileSessionStore(filepath.Join(t.TempDir(), "sessions"), testSessionKey('r'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := ClientConfig{
+		Endpoint:           ts.URL,
+		HTTPClient:         ts.Client(),
+		CredentialProvider: StaticCredentials{Username: "admin", Password: "old-password"},
+		SessionStore:       store,
+	}
+	if _, err := Connect(config); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	client, err := Connect(config)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	schemas.AssertEqual(t, 1, server.next)
+
+	server.ignorePatch = true
+	result := client.RotatePassword(context.Background(), &PasswordRotation{NewPassword: "new-password"})
+	schemas.AssertEqual(t, true, result.RolledBack)
+	schemas.AssertEqual(t, "admin,admin", strings.Join(server.patches, ","))
+}
+
+// TestPasswordRotationRedacted verifies passwords are not included when a
+// rotation is printed.
+func TestPasswordRotationRedacted(t *testing.T) {
+	rotation := PasswordRotation{UserName: "admin", CurrentPassword: "old-secret", NewPassword: "new-secret"}
+	for _, s := range []string{fmt.Sprint(rotation), fmt.Sprintf("%v", &rotation), rotation.LogValue().String()} {
+		if strings.Contains(s, "secret") {
+			t.Errorf("rotation output contains a password: %s", s)
+		}
+	}
+}
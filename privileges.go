@@ -0,0 +1,143 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// PrivilegeChecker answers whether the client's account may perform
+// operations, using the service's privilege registry and the privileges of the
+// account's roles. Checking before starting a workflow allows failing with a
+// clear message rather than partway through on an HTTP 403.
+type PrivilegeChecker struct {
+	// Registry is the service's privilege registry.
+	Registry *schemas.PrivilegeRegistry
+	// UserName is the account the privileges belong to.
+	UserName string
+	// Roles are the role IDs of the account.
+	Roles []string
+	// Privileges are the privileges granted by the roles.
+	Privileges []string
+	// SelfURIs are the URIs of the account's own account and session, the
+	// only resources the ConfigureSelf privilege applies to.
+	SelfURIs []string
+}
+
+// PrivilegeChecker gets the privilege registry and the privileges of the
+// account the client is authenticated as.
+func (c *APIClient) PrivilegeChecker() (*PrivilegeChecker, error) {
+	accountService, err := c.Service.AccountService()
+	if err != nil {
+		return nil, err
+	}
+	if accountService == nil {
+		return nil, errors.New("service does not have an account service")
+	}
+
+	registry, err := accountService.PrivilegeMap()
+	if err != nil {
+		return nil, err
+	}
+	if registry == nil {
+		return nil, errors.New("service does not provide a privilege registry")
+	}
+
+	checker := &PrivilegeChecker{Registry: registry}
+	if err := checker.loadRoles(c, accountService); err != nil {
+		return nil, err
+	}
+
+	return checker, nil
+}
+
+// loadRoles finds the roles of the client's account, preferring the roles
+// reported by the session and falling back to the account's role.
+func (p *PrivilegeChecker) loadRoles(c *APIClient, accountService *schemas.AccountService) error {
+	if c.auth != nil {
+		p.UserName = c.auth.Username
+		if c.auth.Session != "" {
+			p.SelfURIs = append(p.SelfURIs, c.auth.Session)
+			session, err := schemas.GetSession(c, c.auth.Session)
+			if err == nil {
+				if session.UserName != "" {
+					p.UserName = session.UserName
+				}
+				p.Roles = session.Roles
+			}
+		}
+	}
+	if p.UserName == "" && len(p.Roles) == 0 {
+		return errors.New("unable to determine the account of the client")
+	}
+
+	if p.UserName != "" {
+		account, err := accountService.FindAccount(p.UserName)
+		if err != nil && len(p.Roles) == 0 {
+			return err
+		}
+		if account != nil {
+			p.SelfURIs = append(p.SelfURIs, account.ODataID)
+			if len(p.Roles) == 0 && account.RoleID != "" {
+				p.Roles = []string{account.RoleID}
+			}
+		}
+	}
+	if len(p.Roles) == 0 {
+		return fmt.Errorf("unable to determine the role of account %s", p.UserName)
+	}
+
+	roles, err := accountService.Roles()
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if !slices.Contains(p.Roles, role.RoleID) && !slices.Contains(p.Roles, role.ID) {
+			continue
+		}
+		for _, privilege := range role.Privileges() {
+			if !slices.Contains(p.Privileges, privilege) {
+				p.Privileges = append(p.Privileges, privilege)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Check returns an error matching schemas.ErrInsufficientPrivilege if the
+// operation is not allowed, or schemas.ErrNoPrivilegeMapping if the registry
+// does not describe it. Requests for one of the SelfURIs are marked as being
+// for the account's own resources.
+func (p *PrivilegeChecker) Check(req *schemas.PrivilegeRequest) error {
+	if !req.Self && req.URI != "" && slices.Contains(p.SelfURIs, req.URI) {
+		self := *req
+		self.Self = true
+		req = &self
+	}
+	return p.Registry.CheckPrivileges(req, p.Privileges)
+}
+
+// CanGet checks whether a resource of the entity type can be read.
+func (p *PrivilegeChecker) CanGet(entity, uri string) error {
+	return p.Check(&schemas.PrivilegeRequest{Entity: entity, Method: http.MethodGet, URI: uri})
+}
+
+// CanPatch checks whether the properties of a resource of the entity type can
+// be modified.
+func (p *PrivilegeChecker) CanPatch(entity, uri string, properties ...string) error {
+	return p.Check(&schemas.PrivilegeRequest{Entity: entity, Method: http.MethodPatch, URI: uri, Properties: properties})
+}
+
+// CanPost checks whether a resource of the entity type can be posted to, such
+// as to perform one of its actions.
+func (p *PrivilegeChecker) CanPost(entity, uri string) error {
+	return p.Check(&schemas.PrivilegeRequest{Entity: entity, Method: http.MethodPost, URI: uri})
+}
@@ -0,0 +1,84 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// TestPrivilegeChecker verifies the session's role privileges are used to
+// check operations.
+func TestPrivilegeChecker(t *testing.T) {
+	responses := map[string]string{
+		"/redfish/v1/": `{"AccountService": {"@odata.id": "/redfish/v1/AccountService"}, ` +
+			`"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}}`,
+		"/redfish/v1/AccountService": `{"@odata.id": "/redfish/v1/AccountService", ` +
+			`"PrivilegeMap": {"@odata.id": "/redfish/v1/AccountService/PrivilegeMap"}, ` +
+			`"Accounts": {"@odata.id": "/redfish/v1/AccountService/Accounts"}, ` +
+			`"Roles": {"@odata.id": "/redfish/v1/AccountService/Roles"}}`,
+		"/redfish/v1/AccountService/PrivilegeMap": `{"Mappings": [{"Entity": "ComputerSystem", "OperationMap": {` +
+			`"GET": [{"Privilege": ["Login"]}], "PATCH": [{"Privilege": ["ConfigureComponents"]}]}}, ` +
+			`{"Entity": "ManagerAccount", "OperationMap": {"PATCH": [{"Privilege": ["ConfigureUsers"]}]}, ` +
+			`"PropertyOverrides": [{"Targets": ["Password"], "OperationMap": {"PATCH": [{"Privilege": ["ConfigureSelf"]}]}}]}]}`,
+		"/redfish/v1/AccountService/Accounts": `{"Members": [` +
+			`{"@odata.id": "/redfish/v1/AccountService/Accounts/1", "Id": "1", "UserName": "root"}, ` +
+			`{"@odata.id": "/redfish/v1/AccountService/Accounts/2", "Id": "2", "UserName": "monitor"}]}`,
+		"/redfish/v1/AccountService/Roles": `{"Members": [` +
+			`{"@odata.id": "/redfish/v1/AccountService/Roles/ReadOnly", "Id": "ReadOnly", "RoleId": "ReadOnly", ` +
+			`"AssignedPrivileges": ["Login", "ConfigureSelf"]}, ` +
+			`{"@odata.id": "/redfish/v1/AccountService/Roles/Operator", "Id": "Operator", "RoleId": "Operator", ` +
+			`"AssignedPrivileges": ["Login", "ConfigureSelf", "ConfigureComponents"]}]}`,
+		"/redfish/v1/SessionService/Sessions/1": `{"UserName": "monitor", "Roles": ["ReadOnly"]}`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Header().Set("X-Auth-Token", "token")
+			w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/1")
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body)) //nolint
+	}))
+	defer ts.Close()
+
+	client, err := Connect(ClientConfig{Endpoint: ts.URL, HTTPClient: ts.Client(), Username: "monitor", Password: "secret"})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	checker, err := client.PrivilegeChecker()
+	if err != nil {
+		t.Fatalf("failed to get privileges: %v", err)
+	}
+	schemas.AssertEqual(t, "Login,ConfigureSelf", strings.Join(checker.Privileges, ","))
+
+	if err := checker.CanGet("ComputerSystem", "/redfish/v1/Systems/1"); err != nil {
+		t.Errorf("expected GET to be allowed: %v", err)
+	}
+	err = checker.CanPatch("ComputerSystem", "/redfish/v1/Systems/1", "AssetTag")
+	if !errors.Is(err, schemas.ErrInsufficientPrivilege) {
+		t.Errorf("expected PATCH to be denied, got: %v", err)
+	}
+
+	// ConfigureSelf only allows changing the account's own password
+	if err := checker.CanPatch("ManagerAccount", "/redfish/v1/AccountService/Accounts/2", "Password"); err != nil {
+		t.Errorf("expected changing the own password to be allowed: %v", err)
+	}
+	err = checker.CanPatch("ManagerAccount", "/redfish/v1/AccountService/Accounts/1", "Password")
+	if !errors.Is(err, schemas.ErrInsufficientPrivilege) {
+		t.Errorf("expected changing another password to be denied, got: %v", err)
+	}
+}
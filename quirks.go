@@ -0,0 +1,301 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// Quirks are workarounds for services that deviate from the Redfish
+// specification. They are applied by the APIClient to every request, so
+// individual objects do not need to be configured.
+type Quirks struct {
+	// StripEtagQuotes sends If-Match headers without surrounding quotes, for
+	// services that only match unquoted ETags.
+	StripEtagQuotes bool
+	// DisableEtagMatch never sends If-Match headers, for services that reject
+	// them.
+	DisableEtagMatch bool
+	// DisableEtagMatchOnActions does not send If-Match headers when invoking
+	// actions, for services that reject them on action targets.
+	DisableEtagMatchOnActions bool
+	// DisableExpand ignores the $expand support the service claims in its
+	// ProtocolFeaturesSupported, for services where it does not work.
+	DisableExpand bool
+	// SessionsURI is the sessions collection to use when the service does not
+	// link to it from the service root, or links to the wrong location.
+	SessionsURI string
+	// AcceptedIsComplete treats 202 Accepted responses without a Location
+	// header as completed, for services that return 202 for synchronous
+	// operations without providing a task monitor.
+	AcceptedIsComplete bool
+}
+
+// QuirkTarget identifies the service that quirks are looked up for.
+type QuirkTarget struct {
+	// Vendor is the service root Vendor.
+	Vendor string
+	// Product is the service root Product.
+	Product string
+	// RedfishVersion is the service root RedfishVersion.
+	RedfishVersion string
+	// FirmwareVersion is the FirmwareVersion of the first manager. It is only
+	// looked up after authenticating, the first time a quirk depending on it
+	// is needed, and is empty before then.
+	FirmwareVersion string
+}
+
+// QuirkProfile associates quirks with the services they apply to.
+type QuirkProfile struct {
+	// Name describes the profile.
+	Name string
+	// Vendor matches the service root Vendor, ignoring case. Empty matches
+	// any vendor.
+	Vendor string
+	// Product matches service root Products containing it, ignoring case.
+	// Empty matches any product.
+	Product string
+	// RedfishVersion matches RedfishVersions starting with it, such as
+	// "1.6". Empty matches any version.
+	RedfishVersion string
+	// FirmwareVersion matches manager FirmwareVersions starting with it.
+	// Empty matches any version.
+	FirmwareVersion string
+	// Match is an optional function for any other matching needed. It is
+	// called after the other criteria match.
+	Match func(*QuirkTarget) bool
+	// Quirks are applied to matching services.
+	Quirks Quirks
+}
+
+// Matches returns whether the profile applies to the target.
+func (p *QuirkProfile) Matches(target *QuirkTarget) bool {
+	if p.Vendor != "" && !strings.EqualFold(p.Vendor, target.Vendor) {
+		return false
+	}
+	if p.Product != "" && !strings.Contains(strings.ToLower(target.Product), strings.ToLower(p.Product)) {
+		return false
+	}
+	if p.RedfishVersion != "" && !strings.HasPrefix(target.RedfishVersion, p.RedfishVersion) {
+		return false
+	}
+	if p.FirmwareVersion != "" && !strings.HasPrefix(target.FirmwareVersion, p.FirmwareVersion) {
+		return false
+	}
+	return p.Match == nil || p.Match(target)
+}
+
+// QuirkRegistry holds the quirk profiles used to configure clients.
+type QuirkRegistry struct {
+	mu       sync.RWMutex
+	profiles []QuirkProfile
+}
+
+// NewQuirkRegistry creates a registry containing the profiles.
+func NewQuirkRegistry(profiles ...QuirkProfile) *QuirkRegistry {
+	return &QuirkRegistry{profiles: profiles}
+}
+
+// DefaultQuirks is the registry used by clients that are not configured with
+// their own. Profiles registered with it apply to all later connections.
+var DefaultQuirks = NewQuirkRegistry(
+	QuirkProfile{
+		Name:            "Dell iDRAC10",
+		Vendor:          "Dell",
+		FirmwareVersion: "1.",
+		Quirks:          Quirks{DisableEtagMatchOnActions: true},
+	},
+)
+
+// Register adds a profile to the registry. Profiles registered later take
+// precedence for the SessionsURI. Quirks enabled by any matching profile stay
+// enabled, so to turn off a built-in quirk set ClientConfig.Quirks instead.
+func (r *QuirkRegistry) Register(profile QuirkProfile) { //nolint:gocritic
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.profiles = append(r.profiles, profile)
+}
+
+// Lookup returns the quirks of all the profiles matching the target combined,
+// and whether any matched.
+func (r *QuirkRegistry) Lookup(target *QuirkTarget) (Quirks, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var quirks Quirks
+	matched := false
+	for i := range r.profiles {
+		if r.profiles[i].Matches(target) {
+			quirks.merge(&r.profiles[i].Quirks)
+			matched = true
+		}
+	}
+	return quirks, matched
+}
+
+// merge enables the quirks enabled in other, and takes its SessionsURI if set.
+func (q *Quirks) merge(other *Quirks) {
+	q.StripEtagQuotes = q.StripEtagQuotes || other.StripEtagQuotes
+	q.DisableEtagMatch = q.DisableEtagMatch || other.DisableEtagMatch
+	q.DisableEtagMatchOnActions = q.DisableEtagMatchOnActions || other.DisableEtagMatchOnActions
+	q.DisableExpand = q.DisableExpand || other.DisableExpand
+	q.AcceptedIsComplete = q.AcceptedIsComplete || other.AcceptedIsComplete
+	if other.SessionsURI != "" {
+		q.SessionsURI = other.SessionsURI
+	}
+}
+
+// usesFirmwareVersion returns whether any profile matching the target apart
+// from its firmware version depends on the firmware version, which requires
+// another request to find.
+func (r *QuirkRegistry) usesFirmwareVersion(target *QuirkTarget) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := range r.profiles {
+		profile := r.profiles[i]
+		if profile.FirmwareVersion == "" && profile.Match == nil {
+			continue
+		}
+		profile.FirmwareVersion = ""
+		profile.Match = nil
+		if profile.Matches(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// firmwareQuirks looks up the quirks that depend on the manager firmware
+// version. Finding the version takes extra requests, so it is only done the
+// first time the quirks are needed. It is shared by copies of the client.
+type firmwareQuirks struct {
+	once     sync.Once
+	registry *QuirkRegistry
+	target   *QuirkTarget
+	quirks   Quirks
+	matched  bool
+}
+
+// Quirks returns the quirks the client is applying.
+func (c *APIClient) Quirks() Quirks {
+	return c.currentQuirks(true)
+}
+
+// SetQuirks replaces the quirks the client is applying.
+func (c *APIClient) SetQuirks(quirks Quirks) { //nolint:gocritic
+	c.quirks = quirks
+	c.firmwareQuirks = nil
+	if c.Service != nil && quirks.SessionsURI != "" {
+		c.Service.sessions = quirks.SessionsURI
+	}
+}
+
+// currentQuirks returns the quirks to apply. The quirks depending on the
+// manager firmware version are looked up first if lookup is set and they are
+// still pending, otherwise they are only included once looked up.
+func (c *APIClient) currentQuirks(lookup bool) Quirks {
+	pending := c.firmwareQuirks
+	if pending == nil {
+		return c.quirks
+	}
+	if lookup {
+		pending.once.Do(func() {
+			pending.target.FirmwareVersion = c.managerFirmwareVersion()
+			pending.quirks, pending.matched = pending.registry.Lookup(pending.target)
+		})
+	}
+
+	quirks := c.quirks
+	if pending.matched {
+		quirks.merge(&pending.quirks)
+	}
+	return quirks
+}
+
+// managerFirmwareVersion returns the FirmwareVersion of the first manager, or
+// an empty string if it can't be found. Only the first manager is retrieved.
+func (c *APIClient) managerFirmwareVersion() string {
+	if c.Service == nil || c.Service.managers == "" {
+		return ""
+	}
+	// The requests must not look up the firmware quirks again
+	lookup := c.WithContext(c.ctx)
+	lookup.firmwareQuirks = nil
+
+	collection, err := schemas.GetCollection(lookup, c.Service.managers)
+	if err != nil || len(collection.ItemLinks) == 0 {
+		return ""
+	}
+	manager, err := schemas.GetObject[schemas.Manager](lookup, collection.ItemLinks[0])
+	if err != nil {
+		return ""
+	}
+	return manager.FirmwareVersion
+}
+
+// quirkTarget describes the client's service for quirk lookup, without the
+// manager firmware version.
+func (c *APIClient) quirkTarget() *QuirkTarget {
+	return &QuirkTarget{
+		Vendor:         c.Service.Vendor,
+		Product:        c.Service.Product,
+		RedfishVersion: c.Service.RedfishVersion,
+	}
+}
+
+// detectQuirks looks up the quirks for the service, unless they were set in
+// the config. Once authenticated, the quirks depending on the manager firmware
+// version are left to be looked up when first needed.
+func (c *APIClient) detectQuirks(config *ClientConfig, authenticated bool) {
+	if config.Quirks != nil || c.Service == nil {
+		return
+	}
+
+	registry := config.QuirkRegistry
+	if registry == nil {
+		registry = DefaultQuirks
+	}
+
+	target := c.quirkTarget()
+	if authenticated {
+		if registry.usesFirmwareVersion(target) {
+			c.firmwareQuirks = &firmwareQuirks{registry: registry, target: target}
+		}
+		return
+	}
+	if quirks, ok := registry.Lookup(target); ok {
+		c.SetQuirks(quirks)
+	}
+}
+
+// applyRequestQuirks returns the headers to send with a request.
+func (q *Quirks) applyRequestQuirks(method, url string, headers map[string]string) map[string]string {
+	etag, ok := headers["If-Match"]
+	if !ok || !(q.StripEtagQuotes || q.DisableEtagMatch || q.DisableEtagMatchOnActions) {
+		return headers
+	}
+
+	headers = maps.Clone(headers)
+	switch {
+	case q.DisableEtagMatch,
+		q.DisableEtagMatchOnActions && method == http.MethodPost && strings.Contains(url, "/Actions/"):
+		delete(headers, "If-Match")
+	case q.StripEtagQuotes:
+		headers["If-Match"] = strings.Trim(etag, `"`)
+	}
+	return headers
+}
+
+// applyResponseQuirks adjusts a successful response.
+func (q *Quirks) applyResponseQuirks(resp *http.Response) {
+	if q.AcceptedIsComplete && resp.StatusCode == http.StatusAccepted && resp.Header.Get("Location") == "" {
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+	}
+}
@@ -0,0 +1,173 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// TestQuirkRegistryLookup verifies the quirks of all matching profiles are
+// combined.
+func TestQuirkRegistryLookup(t *testing.T) {
+	registry := NewQuirkRegistry(QuirkProfile{
+		Vendor:  "Contoso",
+		Product: "bmc",
+		Quirks:  Quirks{StripEtagQuotes: true},
+	})
+
+	target := &QuirkTarget{Vendor: "contoso", Product: "Contoso BMC 2", RedfishVersion: "1.6.0"}
+	quirks, ok := registry.Lookup(target)
+	schemas.AssertEqual(t, true, ok)
+	schemas.AssertEqual(t, true, quirks.StripEtagQuotes)
+
+	registry.Register(QuirkProfile{Vendor: "Contoso", RedfishVersion: "1.6", Quirks: Quirks{DisableExpand: true}})
+	quirks, _ = registry.Lookup(target)
+	schemas.AssertEqual(t, true, quirks.StripEtagQuotes)
+	schemas.AssertEqual(t, true, quirks.DisableExpand)
+
+	_, ok = registry.Lookup(&QuirkTarget{Vendor: "Other"})
+	schemas.AssertEqual(t, false, ok)
+}
+
+// TestQuirksApplied verifies quirks detected from the service root and the
+// manager firmware version are applied to requests.
+func TestQuirksApplied(t *testing.T) {
+	ifMatch := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/":
+			w.Write([]byte(`{"Vendor": "Contoso", "Product": "Contoso BMC", "RedfishVersion": "1.8.0", ` + //nolint
+				`"ProtocolFeaturesSupported": {"ExpandQuery": {"NoLinks": true}}, ` +
+				`"Managers": {"@odata.id": "/redfish/v1/Managers"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/Managers":
+			w.Write([]byte(`{"Members": [{"@odata.id": "/redfish/v1/Managers/1"}]}`)) //nolint
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/Managers/1":
+			w.Write([]byte(`{"@odata.id": "/redfish/v1/Managers/1", "FirmwareVersion": "2.10.4"}`)) //nolint
+		case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/Sessions":
+			w.Header().Set("X-Auth-Token", "token")
+			w.Header().Set("Location", "/redfish/v1/Sessions/1")
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPost || r.Method == http.MethodPatch:
+			ifMatch[r.Method] = r.Header.Get("If-Match")
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	registry := NewQuirkRegistry(
+		QuirkProfile{Vendor: "Contoso", Quirks: Quirks{SessionsURI: "/redfish/v1/Sessions"}},
+		QuirkProfile{
+			Vendor:          "Contoso",
+			FirmwareVersion: "2.",
+			Quirks: Quirks{
+				SessionsURI:               "/redfish/v1/Sessions",
+				StripEtagQuotes:           true,
+				DisableEtagMatchOnActions: true,
+				AcceptedIsComplete:        true,
+				DisableExpand:             true,
+			},
+		},
+	)
+
+	client, err := Connect(ClientConfig{
+		Endpoint:      ts.URL,
+		HTTPClient:    ts.Client(),
+		Username:      "admin",
+		Password:      "secret",
+		QuirkRegistry: registry,
+		AutoExpand:    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	schemas.AssertEqual(t, true, client.Quirks().StripEtagQuotes)
+	// The quirk found from the firmware version stops $expand being used
+	schemas.AssertEqual(t, 0, len(client.Settings.DefaultQueryOptions))
+
+	headers := map[string]string{"If-Match": `"etag"`}
+	resp, err := client.PatchWithHeaders("/redfish/v1/Systems/1", map[string]string{}, headers)
+	if err != nil {
+		t.Fatalf("failed to patch: %v", err)
+	}
+	schemas.AssertEqual(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = client.PostWithHeaders("/redfish/v1/Systems/1/Actions/ComputerSystem.Reset", map[string]string{}, headers)
+	if err != nil {
+		t.Fatalf("failed to post: %v", err)
+	}
+	resp.Body.Close()
+
+	schemas.AssertEqual(t, "etag", ifMatch[http.MethodPatch])
+	schemas.AssertEqual(t, "", ifMatch[http.MethodPost])
+	schemas.AssertEqual(t, `"etag"`, headers["If-Match"])
+}
+
+// TestQuirksFirmwareLookedUpWhenNeeded verifies the manager firmware version
+// is only looked up once a request could be changed by a quirk depending on
+// it, and that only the first manager is retrieved.
+func TestQuirksFirmwareLookedUpWhenNeeded(t *testing.T) {
+	requests := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method+" "+r.URL.Path]++
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/":
+			w.Write([]byte(`{"Vendor": "Contoso", "Managers": {"@odata.id": "/redfish/v1/Managers"}, ` + //nolint
+				`"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/Managers":
+			w.Write([]byte(`{"Members": [{"@odata.id": "/redfish/v1/Managers/1"}, {"@odata.id": "/redfish/v1/Managers/2"}]}`)) //nolint
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/Managers/1":
+			w.Write([]byte(`{"@odata.id": "/redfish/v1/Managers/1", "FirmwareVersion": "2.10.4"}`)) //nolint
+		case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/SessionService/Sessions":
+			w.Header().Set("X-Auth-Token", "token")
+			w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/1")
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	registry := NewQuirkRegistry(QuirkProfile{
+		Vendor:          "Contoso",
+		FirmwareVersion: "2.",
+		Quirks:          Quirks{StripEtagQuotes: true},
+	})
+	client, err := Connect(ClientConfig{
+		Endpoint:      ts.URL,
+		HTTPClient:    ts.Client(),
+		Username:      "admin",
+		Password:      "secret",
+		QuirkRegistry: registry,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	resp, err := client.Post("/redfish/v1/Systems/1/Actions/ComputerSystem.Reset", map[string]string{})
+	if err != nil {
+		t.Fatalf("failed to post: %v", err)
+	}
+	resp.Body.Close()
+	schemas.AssertEqual(t, 0, requests["GET /redfish/v1/Managers"])
+
+	for i := 0; i < 2; i++ {
+		resp, err = client.PatchWithHeaders("/redfish/v1/Systems/1", map[string]string{}, map[string]string{"If-Match": `"etag"`})
+		if err != nil {
+			t.Fatalf("failed to patch: %v", err)
+		}
+		resp.Body.Close()
+	}
+	schemas.AssertEqual(t, 1, requests["GET /redfish/v1/Managers"])
+	schemas.AssertEqual(t, 1, requests["GET /redfish/v1/Managers/1"])
+	schemas.AssertEqual(t, 0, requests["GET /redfish/v1/Managers/2"])
+	schemas.AssertEqual(t, true, client.Quirks().StripEtagQuotes)
+}
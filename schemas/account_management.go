@@ -0,0 +1,448 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// AccountChange describes what was done to bring an account or role to the
+// requested state.
+type AccountChange string
+
+const (
+	// CreatedAccountChange indicates the account or role did not exist and was
+	// created.
+	CreatedAccountChange AccountChange = "Created"
+	// UpdatedAccountChange indicates the account or role existed and some of
+	// its properties were changed.
+	UpdatedAccountChange AccountChange = "Updated"
+	// UnchangedAccountChange indicates the account or role was already in the
+	// requested state.
+	UnchangedAccountChange AccountChange = "Unchanged"
+	// RemovedAccountChange indicates the account was removed.
+	RemovedAccountChange AccountChange = "Removed"
+)
+
+// ErrPasswordPolicy is returned when a password does not meet the account
+// service password policy.
+var ErrPasswordPolicy = errors.New("password does not meet policy")
+
+// AccountSpec describes the desired state of an account.
+type AccountSpec struct {
+	// UserName identifies the account.
+	UserName string
+	// Password is the password for the account. It is required when the
+	// account is created. For existing accounts it is only set if
+	// UpdatePassword is true or the current password has expired, since the
+	// current password cannot be read back to compare.
+	Password string
+	// UpdatePassword sets Password on an existing account.
+	UpdatePassword bool
+	// RoleID is the role to assign, such as Administrator, Operator or
+	// ReadOnly.
+	RoleID string
+	// Enabled controls whether the account is enabled. New accounts are
+	// enabled if this is nil, existing accounts are left unchanged.
+	Enabled *bool
+	// AccountTypes restricts the services the account can access. Left
+	// unchanged if empty.
+	AccountTypes []AccountTypes
+	// OEMAccountTypes are the OEM account types to assign when AccountTypes
+	// contains OEM. Left unchanged if empty.
+	OEMAccountTypes []string
+	// PasswordChangeRequired, if set, controls whether the user must change
+	// the password at the next login.
+	PasswordChangeRequired *bool
+}
+
+// AccountResult reports the outcome of EnsureAccount or RemoveAccount.
+type AccountResult struct {
+	// Account is the account after any changes. It is nil if the account was
+	// removed or did not exist.
+	Account *ManagerAccount
+	// Change describes what was done.
+	Change AccountChange
+	// Properties are the names of the properties that were set.
+	Properties []string
+}
+
+// RoleSpec describes the desired state of a role.
+type RoleSpec struct {
+	// RoleID identifies the role.
+	RoleID string
+	// AssignedPrivileges are the Redfish privileges of the role. The
+	// privileges of an existing role are left unchanged if this is nil, and
+	// removed if it is empty.
+	AssignedPrivileges []PrivilegeType
+	// OemPrivileges are the OEM privileges of the role. The OEM privileges of
+	// an existing role are left unchanged if this is nil, and removed if it is
+	// empty.
+	OemPrivileges []string
+}
+
+// RoleResult reports the outcome of EnsureRole.
+type RoleResult struct {
+	// Role is the role after any changes.
+	Role *Role
+	// Change describes what was done.
+	Change AccountChange
+	// Properties are the names of the properties that were set.
+	Properties []string
+}
+
+// ValidatePassword checks the password against the password length policy of
+// the account service, so a request with an unacceptable password is not
+// sent.
+func (a *AccountService) ValidatePassword(password string) error {
+	length := uint(utf8.RuneCountInString(password))
+	if a.MinPasswordLength > 0 && length < a.MinPasswordLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrPasswordPolicy, a.MinPasswordLength)
+	}
+	if a.MaxPasswordLength > 0 && length > a.MaxPasswordLength {
+		return fmt.Errorf("%w: must be at most %d characters", ErrPasswordPolicy, a.MaxPasswordLength)
+	}
+	return nil
+}
+
+// PasswordExpired returns true if the account's password has expired. The
+// account's PasswordExpiration is used, since it reflects both the account
+// service's PasswordExpirationDays and any expiration set for the account.
+func (a *AccountService) PasswordExpired(account *ManagerAccount) bool {
+	if account.PasswordExpiration == "" {
+		return false
+	}
+	expiration, err := time.Parse(time.RFC3339, account.PasswordExpiration)
+	return err == nil && time.Now().After(expiration)
+}
+
+// FindAccount returns the account with the user name, or nil if there is
+// none.
+func (a *AccountService) FindAccount(userName string) (*ManagerAccount, error) {
+	accounts, err := a.Accounts()
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		if account.UserName == userName {
+			return account, nil
+		}
+	}
+	return nil, nil
+}
+
+// EnsureAccount makes sure an account exists matching the spec, creating or
+// updating it as needed.
+//
+// Services with a fixed number of pre-allocated account slots, which list
+// accounts with an empty UserName, are handled by filling in the lowest
+// numbered empty slot instead of creating a new account.
+func (a *AccountService) EnsureAccount(spec *AccountSpec) (*AccountResult, error) {
+	if spec.UserName == "" {
+		return nil, errors.New("a user name is required")
+	}
+
+	accounts, err := a.Accounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var existing, emptySlot *ManagerAccount
+	for _, account := range accounts {
+		if account.UserName == spec.UserName {
+			existing = account
+			break
+		}
+		if account.UserName == "" && (emptySlot == nil || slotBefore(account, emptySlot)) {
+			emptySlot = account
+		}
+	}
+
+	if existing != nil {
+		return a.updateAccount(existing, spec)
+	}
+
+	if spec.Password == "" {
+		return nil, errors.New("a password is required to create an account")
+	}
+	if err := a.ValidatePassword(spec.Password); err != nil {
+		return nil, err
+	}
+
+	if emptySlot != nil {
+		payload := accountPayload(nil, spec, true)
+		payload["UserName"] = spec.UserName
+		payload["Password"] = spec.Password
+		if err := emptySlot.Patch(emptySlot.ODataID, payload); err != nil {
+			return nil, err
+		}
+		account, err := GetObject[ManagerAccount](a.GetClient(), emptySlot.ODataID)
+		return &AccountResult{Account: account, Change: CreatedAccountChange, Properties: propertyNames(payload)}, err
+	}
+
+	return a.createAccount(spec)
+}
+
+// slotBefore orders account slots by their numeric ID, since the collection
+// members are not retrieved in order.
+func slotBefore(a, b *ManagerAccount) bool {
+	if len(a.ID) != len(b.ID) {
+		return len(a.ID) < len(b.ID)
+	}
+	return a.ID < b.ID
+}
+
+// updateAccount patches the properties of the account that differ from the
+// spec.
+func (a *AccountService) updateAccount(account *ManagerAccount, spec *AccountSpec) (*AccountResult, error) {
+	payload := accountPayload(account, spec, false)
+	if spec.Password != "" && (spec.UpdatePassword || a.PasswordExpired(account)) {
+		if err := a.ValidatePassword(spec.Password); err != nil {
+			return nil, err
+		}
+		payload["Password"] = spec.Password
+	}
+
+	if len(payload) == 0 {
+		return &AccountResult{Account: account, Change: UnchangedAccountChange}, nil
+	}
+
+	if err := account.Patch(account.ODataID, payload); err != nil {
+		return nil, err
+	}
+	updated, err := GetObject[ManagerAccount](a.GetClient(), account.ODataID)
+	return &AccountResult{Account: updated, Change: UpdatedAccountChange, Properties: propertyNames(payload)}, err
+}
+
+// createAccount posts a new account to the accounts collection.
+func (a *AccountService) createAccount(spec *AccountSpec) (*AccountResult, error) {
+	payload := accountPayload(nil, spec, true)
+	payload["UserName"] = spec.UserName
+	payload["Password"] = spec.Password
+
+	baseEntity := &a.Entity
+
+	// As with CreateAccount, the ETag for creating an account comes from the
+	// accounts collection.
+	if !a.IsEtagMatchDisabled() {
+		accountsEntity, err := GetObject[Entity](a.GetClient(), a.accounts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get accounts entity: %w", err)
+		}
+		baseEntity = accountsEntity
+	}
+
+	resp, err := baseEntity.PostWithResponse(a.accounts, payload)
+	defer DeferredCleanupHTTPResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AccountResult{Change: CreatedAccountChange, Properties: propertyNames(payload)}
+	var account ManagerAccount
+	if err := json.NewDecoder(resp.Body).Decode(&account); err == nil && account.ODataID != "" {
+		account.SetClient(a.GetClient())
+		result.Account = &account
+	} else if location := resp.Header.Get("Location"); location != "" {
+		result.Account, err = GetObject[ManagerAccount](a.GetClient(), location)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// accountPayload returns the properties in the spec that differ from the
+// current account. If current is nil all properties in the spec are returned.
+// If includeDefaults is set, the account is enabled unless the spec says
+// otherwise.
+func accountPayload(current *ManagerAccount, spec *AccountSpec, includeDefaults bool) map[string]any {
+	payload := make(map[string]any)
+
+	enabled := true
+	if spec.Enabled != nil {
+		enabled = *spec.Enabled
+	}
+	if current == nil || current.Enabled != enabled {
+		if includeDefaults || spec.Enabled != nil {
+			payload["Enabled"] = enabled
+		}
+	}
+
+	if spec.RoleID != "" && (current == nil || current.RoleID != spec.RoleID) {
+		payload["RoleId"] = spec.RoleID
+	}
+	if len(spec.AccountTypes) > 0 && (current == nil || !sameElements(current.AccountTypes, spec.AccountTypes)) {
+		payload["AccountTypes"] = spec.AccountTypes
+	}
+	if len(spec.OEMAccountTypes) > 0 && (current == nil || !sameElements(current.OEMAccountTypes, spec.OEMAccountTypes)) {
+		payload["OEMAccountTypes"] = spec.OEMAccountTypes
+	}
+	if spec.PasswordChangeRequired != nil &&
+		(current == nil || current.PasswordChangeRequired != *spec.PasswordChangeRequired) {
+		payload["PasswordChangeRequired"] = *spec.PasswordChangeRequired
+	}
+
+	return payload
+}
+
+// RemoveAccount removes the account with the user name. Services with fixed
+// account slots that do not allow accounts to be deleted have the slot
+// cleared instead. Removing an account that does not exist is not an error.
+func (a *AccountService) RemoveAccount(userName string) (*AccountResult, error) {
+	account, err := a.FindAccount(userName)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return &AccountResult{Change: UnchangedAccountChange}, nil
+	}
+
+	resp, err := a.GetClient().DeleteWithHeaders(account.ODataID, account.Headers())
+	DeferredCleanupHTTPResponse(resp)
+	if err == nil {
+		return &AccountResult{Change: RemovedAccountChange}, nil
+	}
+
+	if !deleteRejected(err) {
+		return nil, err
+	}
+
+	// Fixed account slots are emptied rather than deleted
+	payload := map[string]any{
+		"Enabled":  false,
+		"UserName": "",
+	}
+	if err := account.Patch(account.ODataID, payload); err != nil {
+		return nil, err
+	}
+	return &AccountResult{Change: RemovedAccountChange, Properties: propertyNames(payload)}, nil
+}
+
+// deleteRejected returns true if the error shows the service does not allow
+// the resource to be deleted. Services with fixed account slots report this
+// with different status codes.
+func deleteRejected(err error) bool {
+	var redfishErr *Error
+	if !errors.As(err, &redfishErr) {
+		return false
+	}
+	switch redfishErr.HTTPReturnedStatusCode {
+	case http.StatusBadRequest, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// EnsureRole makes sure a role exists with the privileges in the spec,
+// creating or updating it as needed. Predefined roles cannot be changed.
+func (a *AccountService) EnsureRole(spec *RoleSpec) (*RoleResult, error) {
+	if spec.RoleID == "" {
+		return nil, errors.New("a role ID is required")
+	}
+
+	roles, err := a.Roles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		if role.RoleID != spec.RoleID && role.ID != spec.RoleID {
+			continue
+		}
+
+		// Only the privileges set in the spec are compared, so a nil list
+		// isn't sent as null and doesn't clear the role's privileges
+		payload := make(map[string]any)
+		if spec.AssignedPrivileges != nil && !sameElements(role.AssignedPrivileges, spec.AssignedPrivileges) {
+			payload["AssignedPrivileges"] = spec.AssignedPrivileges
+		}
+		if spec.OemPrivileges != nil && !sameElements(role.OemPrivileges, spec.OemPrivileges) {
+			payload["OemPrivileges"] = spec.OemPrivileges
+		}
+
+		if len(payload) == 0 {
+			return &RoleResult{Role: role, Change: UnchangedAccountChange}, nil
+		}
+		if role.IsPredefined {
+			return nil, fmt.Errorf("predefined role %s cannot be changed", spec.RoleID)
+		}
+
+		if err := role.Patch(role.ODataID, payload); err != nil {
+			return nil, err
+		}
+		updated, err := GetObject[Role](a.GetClient(), role.ODataID)
+		return &RoleResult{Role: updated, Change: UpdatedAccountChange, Properties: propertyNames(payload)}, err
+	}
+
+	if a.roles == "" {
+		return nil, errors.New("account service does not support roles")
+	}
+
+	payload := map[string]any{
+		"RoleId":             spec.RoleID,
+		"AssignedPrivileges": spec.AssignedPrivileges,
+	}
+	if len(spec.OemPrivileges) > 0 {
+		payload["OemPrivileges"] = spec.OemPrivileges
+	}
+	if spec.AssignedPrivileges == nil {
+		payload["AssignedPrivileges"] = []PrivilegeType{}
+	}
+
+	role, _, err := postObjectWithTask[Role](a.GetClient(), a.roles, payload, a.Headers(), false)
+	if err != nil {
+		return nil, err
+	}
+	return &RoleResult{Role: role, Change: CreatedAccountChange, Properties: propertyNames(payload)}, nil
+}
+
+// sameElements returns true if both slices contain the same elements,
+// ignoring order.
+func sameElements[T ~string](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := slices.Clone(a)
+	sortedB := slices.Clone(b)
+	slices.Sort(sortedA)
+	slices.Sort(sortedB)
+	return slices.Equal(sortedA, sortedB)
+}
+
+// propertyNames returns the sorted keys of the payload.
+func propertyNames(payload map[string]any) []string {
+	names := make([]string, 0, len(payload))
+	for name := range payload {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// SetPassword changes the password of the account. The ChangePassword action
+// is used if the service supports it, otherwise the Password property is
+// patched as older services require. currentPassword is the password of the
+// account the request is authenticated as, which the ChangePassword action
+// uses to confirm the change. The method used, either "ChangePassword" or
+// "PATCH", is returned.
+func (m *ManagerAccount) SetPassword(newPassword, currentPassword string) (string, error) {
+	if m.changePasswordTarget != "" {
+		_, err := m.ChangePassword(newPassword, currentPassword)
+		return "ChangePassword", err
+	}
+
+	payload := struct {
+		Password string
+	}{Password: newPassword}
+	return http.MethodPatch, m.Patch(m.ODataID, payload)
+}
@@ -0,0 +1,249 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const accountManagementServiceBody = `{
+		"@odata.id": "/redfish/v1/AccountService",
+		"Id": "AccountService",
+		"MinPasswordLength": 8,
+		"MaxPasswordLength": 20,
+		"Accounts": {"@odata.id": "/redfish/v1/AccountService/Accounts"},
+		"Roles": {"@odata.id": "/redfish/v1/AccountService/Roles"}
+	}`
+
+const slotAccountsBody = `{
+		"@odata.id": "/redfish/v1/AccountService/Accounts",
+		"Members": [
+			{"@odata.id": "/redfish/v1/AccountService/Accounts/1", "Id": "1",
+				"UserName": "root", "RoleId": "Administrator", "Enabled": true},
+			{"@odata.id": "/redfish/v1/AccountService/Accounts/2", "Id": "2",
+				"UserName": "", "RoleId": "None", "Enabled": false},
+			{"@odata.id": "/redfish/v1/AccountService/Accounts/3", "Id": "3",
+				"UserName": "", "RoleId": "None", "Enabled": false}
+		]
+	}`
+
+func accountManagementService(t *testing.T, testClient *TestClient) *AccountService {
+	var result AccountService
+	if err := json.Unmarshal([]byte(accountManagementServiceBody), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(testClient)
+	result.DisableEtagMatch(true)
+	return &result
+}
+
+// TestEnsureAccountSlot tests filling in an empty account slot.
+func TestEnsureAccountSlot(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				getCall(slotAccountsBody),
+				getCall(`{"@odata.id": "/redfish/v1/AccountService/Accounts/2", "Id": "2",
+					"UserName": "automation", "RoleId": "Operator", "Enabled": true}`),
+			},
+		},
+	}
+	result := accountManagementService(t, testClient)
+
+	accountResult, err := result.EnsureAccount(&AccountSpec{
+		UserName:     "automation",
+		Password:     "correct-horse",
+		RoleID:       "Operator",
+		AccountTypes: []AccountTypes{RedfishAccountTypes},
+	})
+	if err != nil {
+		t.Fatalf("Error ensuring account: %s", err)
+	}
+
+	AssertEqual(t, CreatedAccountChange, accountResult.Change)
+	AssertEqual(t, "automation", accountResult.Account.UserName)
+	AssertEqual(t, "AccountTypes,Enabled,Password,RoleId,UserName", strings.Join(accountResult.Properties, ","))
+
+	calls := testClient.CapturedCalls()
+	if calls[1].Action != http.MethodPatch || calls[1].URL != "/redfish/v1/AccountService/Accounts/2" {
+		t.Errorf("Expected the empty slot to be patched, got: %v", calls[1])
+	}
+}
+
+// TestEnsureAccountUpdate tests only changed properties are updated.
+func TestEnsureAccountUpdate(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {getCall(slotAccountsBody), getCall(`{"UserName": "root", "RoleId": "ReadOnly"}`)},
+		},
+	}
+	result := accountManagementService(t, testClient)
+
+	accountResult, err := result.EnsureAccount(&AccountSpec{UserName: "root", Password: "unchanged", RoleID: "ReadOnly"})
+	if err != nil {
+		t.Fatalf("Error ensuring account: %s", err)
+	}
+
+	AssertEqual(t, UpdatedAccountChange, accountResult.Change)
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, "map[RoleId:ReadOnly]", calls[1].Payload)
+
+	// A second call with the same state should not make changes
+	testClient = &TestClient{
+		CustomReturnForActions: map[string][]any{http.MethodGet: {getCall(slotAccountsBody)}},
+	}
+	result = accountManagementService(t, testClient)
+	accountResult, err = result.EnsureAccount(&AccountSpec{UserName: "root", RoleID: "Administrator"})
+	if err != nil {
+		t.Fatalf("Error ensuring account: %s", err)
+	}
+	AssertEqual(t, UnchangedAccountChange, accountResult.Change)
+	AssertEqual(t, 1, len(testClient.CapturedCalls()))
+}
+
+// TestEnsureAccountPasswordPolicy tests the password is checked locally.
+func TestEnsureAccountPasswordPolicy(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{http.MethodGet: {getCall(slotAccountsBody)}},
+	}
+	result := accountManagementService(t, testClient)
+
+	_, err := result.EnsureAccount(&AccountSpec{UserName: "new", Password: "short", RoleID: "Operator"})
+	if !errors.Is(err, ErrPasswordPolicy) {
+		t.Errorf("Expected password policy error, got: %v", err)
+	}
+	AssertEqual(t, 1, len(testClient.CapturedCalls()))
+}
+
+// TestRemoveAccountSlot tests clearing a slot when delete is not allowed.
+func TestRemoveAccountSlot(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		testClient := &TestClient{
+			CustomReturnForActions: map[string][]any{
+				http.MethodGet:    {getCall(slotAccountsBody)},
+				http.MethodDelete: {errorCall(status)},
+			},
+		}
+		result := accountManagementService(t, testClient)
+
+		accountResult, err := result.RemoveAccount("root")
+		if err != nil {
+			t.Fatalf("Error removing account: %s", err)
+		}
+
+		AssertEqual(t, RemovedAccountChange, accountResult.Change)
+		calls := testClient.CapturedCalls()
+		AssertEqual(t, http.MethodPatch, calls[2].Action)
+		AssertEqual(t, "map[Enabled:false UserName:]", calls[2].Payload)
+	}
+
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet:    {getCall(slotAccountsBody)},
+			http.MethodDelete: {errorCall(http.StatusForbidden)},
+		},
+	}
+	_, err := accountManagementService(t, testClient).RemoveAccount("root")
+	if err == nil {
+		t.Error("Expected a forbidden delete to be reported")
+	}
+}
+
+const rolesBody = `{"Members": [{"@odata.id": "/redfish/v1/AccountService/Roles/ReadOnly",
+		"Id": "ReadOnly", "RoleId": "ReadOnly", "IsPredefined": true, "AssignedPrivileges": ["Login"]}]}`
+
+// TestEnsureRole tests creating a custom role.
+func TestEnsureRole(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {getCall(rolesBody), getCall(rolesBody)},
+			http.MethodPost: {getCall(`{"@odata.id": "/redfish/v1/AccountService/Roles/Auditor", "Id": "Auditor",
+				"RoleId": "Auditor", "AssignedPrivileges": ["Login"], "OemPrivileges": ["ViewLogs"]}`)},
+		},
+	}
+	result := accountManagementService(t, testClient)
+
+	roleResult, err := result.EnsureRole(&RoleSpec{
+		RoleID:             "Auditor",
+		AssignedPrivileges: []PrivilegeType{LoginPrivilegeType},
+		OemPrivileges:      []string{"ViewLogs"},
+	})
+	if err != nil {
+		t.Fatalf("Error ensuring role: %s", err)
+	}
+
+	AssertEqual(t, CreatedAccountChange, roleResult.Change)
+	AssertEqual(t, "Auditor", roleResult.Role.RoleID)
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, "/redfish/v1/AccountService/Roles", calls[1].URL)
+
+	_, err = result.EnsureRole(&RoleSpec{RoleID: "ReadOnly", AssignedPrivileges: []PrivilegeType{ConfigureSelfPrivilegeType}})
+	RequireErrorContains(t, err, "predefined role ReadOnly cannot be changed")
+}
+
+// TestEnsureRoleUnsetPrivileges tests privileges left out of the spec are not
+// changed.
+func TestEnsureRoleUnsetPrivileges(t *testing.T) {
+	customRoleBody := `{"Members": [{"@odata.id": "/redfish/v1/AccountService/Roles/Auditor", "Id": "Auditor",
+		"RoleId": "Auditor", "AssignedPrivileges": ["Login"], "OemPrivileges": ["ViewLogs"]}]}`
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {getCall(customRoleBody), getCall(customRoleBody), getCall(`{"@odata.id":
+				"/redfish/v1/AccountService/Roles/Auditor", "RoleId": "Auditor", "AssignedPrivileges": ["Login"]}`)},
+		},
+	}
+	result := accountManagementService(t, testClient)
+
+	roleResult, err := result.EnsureRole(&RoleSpec{RoleID: "Auditor", AssignedPrivileges: []PrivilegeType{LoginPrivilegeType}})
+	if err != nil {
+		t.Fatalf("Error ensuring role: %s", err)
+	}
+	AssertEqual(t, UnchangedAccountChange, roleResult.Change)
+
+	roleResult, err = result.EnsureRole(&RoleSpec{RoleID: "Auditor", OemPrivileges: []string{}})
+	if err != nil {
+		t.Fatalf("Error ensuring role: %s", err)
+	}
+	AssertEqual(t, UpdatedAccountChange, roleResult.Change)
+	AssertEqual(t, []string{"OemPrivileges"}, roleResult.Properties)
+}
+
+// TestPasswordExpired tests the account's own expiration is used.
+func TestPasswordExpired(t *testing.T) {
+	service := accountManagementService(t, &TestClient{})
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+
+	AssertEqual(t, true, service.PasswordExpired(&ManagerAccount{PasswordExpiration: past}))
+	AssertEqual(t, false, service.PasswordExpired(&ManagerAccount{PasswordExpiration: future}))
+	AssertEqual(t, false, service.PasswordExpired(&ManagerAccount{}))
+}
+
+// TestSetPasswordAction tests the ChangePassword action is preferred.
+func TestSetPasswordAction(t *testing.T) {
+	var account ManagerAccount
+	body := `{"@odata.id": "/redfish/v1/AccountService/Accounts/1", "UserName": "root", "Actions": {
+		"#ManagerAccount.ChangePassword": {"target": "/redfish/v1/AccountService/Accounts/1/Actions/ManagerAccount.ChangePassword"}}}`
+	if err := json.Unmarshal([]byte(body), &account); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	testClient := &TestClient{}
+	account.SetClient(testClient)
+
+	method, err := account.SetPassword("new-password", "session-password")
+	if err != nil {
+		t.Fatalf("Error setting password: %s", err)
+	}
+
+	AssertEqual(t, "ChangePassword", method)
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, http.MethodPost, calls[0].Action)
+	AssertEqual(t, "map[NewPassword:new-password SessionAccountPassword:session-password]", calls[0].Payload)
+}
@@ -430,9 +430,9 @@ func (a *AccountService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (a *AccountService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (a *AccountService) writableFields() []string {
+	return []string{
 		"AccountLockoutCounterResetAfter",
 		"AccountLockoutCounterResetEnabled",
 		"AccountLockoutDuration",
@@ -447,8 +447,11 @@ func (a *AccountService) Update() error {
 		"RequireChangePasswordAction",
 		"ServiceEnabled",
 	}
+}
 
-	return a.UpdateFromRawData(a, a.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (a *AccountService) Update() error {
+	return a.UpdateFromRawData(a, a.RawData, a.writableFields())
 }
 
 // GetAccountService will get a AccountService instance from the service.
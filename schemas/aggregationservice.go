@@ -79,13 +79,16 @@ func (a *AggregationService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (a *AggregationService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (a *AggregationService) writableFields() []string {
+	return []string{
 		"ServiceEnabled",
 	}
+}
 
-	return a.UpdateFromRawData(a, a.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (a *AggregationService) Update() error {
+	return a.UpdateFromRawData(a, a.RawData, a.writableFields())
 }
 
 // GetAggregationService will get a AggregationService instance from the service.
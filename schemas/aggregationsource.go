@@ -136,9 +136,9 @@ func (a *AggregationSource) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (a *AggregationSource) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (a *AggregationSource) writableFields() []string {
+	return []string{
 		"AggregationType",
 		"HostName",
 		"ModbusTargetServerId",
@@ -146,8 +146,11 @@ func (a *AggregationSource) Update() error {
 		"Port",
 		"UserName",
 	}
+}
 
-	return a.UpdateFromRawData(a, a.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (a *AggregationSource) Update() error {
+	return a.UpdateFromRawData(a, a.RawData, a.writableFields())
 }
 
 // GetAggregationSource will get a AggregationSource instance from the service.
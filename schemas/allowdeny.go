@@ -105,9 +105,9 @@ func (a *AllowDeny) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (a *AllowDeny) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (a *AllowDeny) writableFields() []string {
+	return []string{
 		"AllowType",
 		"DestinationPortLower",
 		"DestinationPortUpper",
@@ -120,8 +120,11 @@ func (a *AllowDeny) Update() error {
 		"SourcePortUpper",
 		"StatefulSession",
 	}
+}
 
-	return a.UpdateFromRawData(a, a.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (a *AllowDeny) Update() error {
+	return a.UpdateFromRawData(a, a.RawData, a.writableFields())
 }
 
 // GetAllowDeny will get a AllowDeny instance from the service.
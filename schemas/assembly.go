@@ -55,13 +55,16 @@ func (a *Assembly) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (a *Assembly) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (a *Assembly) writableFields() []string {
+	return []string{
 		"Assemblies",
 	}
+}
 
-	return a.UpdateFromRawData(a, a.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (a *Assembly) Update() error {
+	return a.UpdateFromRawData(a, a.RawData, a.writableFields())
 }
 
 // GetAssembly will get a Assembly instance from the service.
@@ -188,14 +191,17 @@ func (a *AssemblyData) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (a *AssemblyData) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (a *AssemblyData) writableFields() []string {
+	return []string{
 		"LocationIndicatorActive",
 		"ReadyToRemove",
 	}
+}
 
-	return a.UpdateFromRawData(a, a.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (a *AssemblyData) Update() error {
+	return a.UpdateFromRawData(a, a.RawData, a.writableFields())
 }
 
 // GetAssemblyData will get a AssemblyData instance from the service.
@@ -181,14 +181,17 @@ func (a *AutomationNode) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (a *AutomationNode) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (a *AutomationNode) writableFields() []string {
+	return []string{
 		"MotionAxis",
 		"MotionProfile",
 	}
+}
 
-	return a.UpdateFromRawData(a, a.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (a *AutomationNode) Update() error {
+	return a.UpdateFromRawData(a, a.RawData, a.writableFields())
 }
 
 // GetAutomationNode will get a AutomationNode instance from the service.
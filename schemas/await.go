@@ -0,0 +1,331 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTaskException indicates a task or job completed with errors.
+	ErrTaskException = errors.New("task completed with errors")
+	// ErrTaskKilled indicates a task was killed by an operator.
+	ErrTaskKilled = errors.New("task was killed")
+	// ErrTaskCancelled indicates a task or job was cancelled.
+	ErrTaskCancelled = errors.New("task was cancelled")
+)
+
+// defaultAwaitPollRate is used when AwaitOptions.PollRate is not set and the
+// service does not provide a Retry-After value.
+const defaultAwaitPollRate = 10 * time.Second
+
+// Pollable is implemented by anything representing long-running work on the
+// service, such as a task monitor, Task, Job, or OEM job, so that it can be
+// waited on with Await.
+type Pollable interface {
+	// Poll retrieves the current status of the work from the service.
+	Poll(c Client) (*WorkStatus, error)
+}
+
+// WorkStatus is a snapshot of the status of a task or job.
+type WorkStatus struct {
+	// State is the TaskState, JobState, or OEM-specific state of the work.
+	State string
+	// Done indicates the work has finished, whether successfully or not.
+	Done bool
+	// Failure is one of ErrTaskException, ErrTaskKilled, or ErrTaskCancelled
+	// if the work finished unsuccessfully.
+	Failure error
+	// PercentComplete is the completion progress of the work, if reported.
+	PercentComplete *uint
+	// Messages are the messages associated with the work.
+	Messages []Message
+	// CreatedResources are the URIs of any resources created by the work.
+	CreatedResources []string
+	// Location is the Location header of the final task monitor response,
+	// if any.
+	Location string
+	// RetryAfter is the time the service asked to be polled again, if it did.
+	RetryAfter time.Time
+}
+
+// AwaitOptions controls how Await polls for completion. The timeout is
+// controlled by the context passed to Await.
+type AwaitOptions struct {
+	// PollRate is the interval between polls when the service does not
+	// provide a Retry-After value. Defaults to ten seconds.
+	PollRate time.Duration
+	// OnProgress, if set, is called each time the state or completion
+	// percentage of the work changes.
+	OnProgress func(*WorkStatus)
+	// ResolveMessage, if set, is used to get the text of messages that only
+	// contain a MessageId and arguments. See NewMessageResolver.
+	ResolveMessage func(Message) string
+}
+
+// TaskError is returned by Await when the work finished unsuccessfully. It
+// wraps the matching ErrTaskException, ErrTaskKilled, or ErrTaskCancelled
+// error so it can be checked with errors.Is.
+type TaskError struct {
+	// State is the final state of the work.
+	State string
+	// Messages are the final messages associated with the work, with their
+	// text resolved where possible.
+	Messages []Message
+	err      error
+}
+
+func (e *TaskError) Error() string {
+	var text []string
+	for i := range e.Messages {
+		if e.Messages[i].Message != "" {
+			text = append(text, e.Messages[i].Message)
+		} else if e.Messages[i].MessageID != "" {
+			text = append(text, e.Messages[i].MessageID)
+		}
+	}
+
+	if len(text) == 0 {
+		return fmt.Sprintf("%s (state %s)", e.err, e.State)
+	}
+	return fmt.Sprintf("%s (state %s): %s", e.err, e.State, strings.Join(text, "; "))
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.err
+}
+
+// Await polls the work until it finishes or the context is done. The service's
+// Retry-After values are honored when provided. On completion the final status
+// is returned; if the work did not complete successfully the status is
+// returned along with a *TaskError.
+func Await(ctx context.Context, c Client, work Pollable, opts *AwaitOptions) (*WorkStatus, error) {
+	if work == nil {
+		return nil, fmt.Errorf("nothing to await")
+	}
+	if opts == nil {
+		opts = &AwaitOptions{}
+	}
+	pollRate := opts.PollRate
+	if pollRate <= 0 {
+		pollRate = defaultAwaitPollRate
+	}
+
+	if tm, ok := work.(*TaskMonitorInfo); ok && time.Now().Before(tm.RetryAfter) {
+		select {
+		case <-time.After(time.Until(tm.RetryAfter)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var last *WorkStatus
+	for {
+		if err := ctx.Err(); err != nil {
+			return last, err
+		}
+
+		status, err := work.Poll(c)
+		if err != nil {
+			return last, err
+		}
+
+		if opts.OnProgress != nil && (last == nil || last.State != status.State ||
+			percent(last.PercentComplete) != percent(status.PercentComplete)) {
+			opts.OnProgress(status)
+		}
+		last = status
+
+		if status.Done {
+			if status.Failure == nil {
+				return status, nil
+			}
+
+			if opts.ResolveMessage != nil {
+				for i := range status.Messages {
+					if status.Messages[i].Message == "" {
+						status.Messages[i].Message = opts.ResolveMessage(status.Messages[i])
+					}
+				}
+			}
+			return status, &TaskError{State: status.State, Messages: status.Messages, err: status.Failure}
+		}
+
+		waitTime := pollRate
+		if !status.RetryAfter.IsZero() {
+			waitTime = time.Until(status.RetryAfter)
+		}
+
+		select {
+		case <-time.After(waitTime):
+		case <-ctx.Done():
+			return last, ctx.Err()
+		}
+	}
+}
+
+// percent returns the completion percentage, or -1 if it was not reported.
+func percent(p *uint) int {
+	if p == nil {
+		return -1
+	}
+	return int(*p)
+}
+
+// Poll retrieves the current status of the task monitor. While the service
+// responds with 202 Accepted the work is still running. Once it responds with
+// anything else the work is done, and the task, if known, is retrieved to get
+// its final state and messages.
+func (tm *TaskMonitorInfo) Poll(c Client) (*WorkStatus, error) {
+	if tm.TaskMonitor == "" {
+		return nil, fmt.Errorf("task monitor URI is missing")
+	}
+	if c == nil && tm.Task != nil {
+		c = tm.Task.GetClient()
+	}
+	if c == nil {
+		return nil, fmt.Errorf("no client available to poll task monitor")
+	}
+
+	resp, err := c.Get(tm.TaskMonitor)
+	defer DeferredCleanupHTTPResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusAccepted {
+		status := &WorkStatus{State: string(RunningTaskState)}
+		task := &Task{}
+		if err := json.NewDecoder(resp.Body).Decode(task); err == nil && task.TaskState != "" {
+			task.SetClient(c)
+			tm.Task = task
+			status = task.workStatus()
+			// The task monitor is authoritative on whether the work is done
+			status.Done = false
+			status.Failure = nil
+		}
+		if retryAfter, err := ParseRetryAfter(resp.Header.Get("Retry-After")); err == nil {
+			status.RetryAfter = retryAfter
+		}
+		return status, nil
+	}
+
+	location := resp.Header.Get("Location")
+	status := &WorkStatus{State: string(CompletedTaskState), Done: true}
+
+	// The final response is the result of the operation, though some services
+	// return the task itself instead.
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	task := &Task{}
+	if err := json.Unmarshal(body, task); err == nil && task.TaskState != "" {
+		status = task.workStatus()
+	} else if tm.Task != nil && tm.Task.ODataID != "" {
+		if task, err := GetObject[Task](c, tm.Task.ODataID); err == nil {
+			status = task.workStatus()
+		}
+	}
+
+	// The task can lag behind its monitor, trust the monitor if it's not done
+	if !status.Done {
+		status.State = string(CompletedTaskState)
+		status.Done = true
+	}
+
+	status.Location = location
+	if resp.StatusCode == http.StatusCreated && location != "" {
+		status.CreatedResources = append(status.CreatedResources, location)
+	}
+
+	return status, nil
+}
+
+// Poll retrieves the current status of the task. If the task has no
+// @odata.id but has a task monitor, the task monitor is polled instead. If c
+// is nil, the task's own client is used.
+func (t *Task) Poll(c Client) (*WorkStatus, error) {
+	if c == nil {
+		c = t.client
+	}
+	if t.ODataID == "" && t.TaskMonitor != "" {
+		return (&TaskMonitorInfo{TaskMonitor: t.TaskMonitor, Task: t}).Poll(c)
+	}
+
+	task, err := GetObject[Task](c, t.ODataID)
+	if err != nil {
+		return nil, err
+	}
+	return task.workStatus(), nil
+}
+
+func (t *Task) workStatus() *WorkStatus {
+	status := &WorkStatus{
+		State:            string(t.TaskState),
+		PercentComplete:  t.PercentComplete,
+		Messages:         t.Messages,
+		CreatedResources: t.createdResources,
+	}
+
+	switch t.TaskState {
+	case CompletedTaskState:
+		status.Done = true
+	case ExceptionTaskState:
+		status.Done = true
+		status.Failure = ErrTaskException
+	case KilledTaskState:
+		status.Done = true
+		status.Failure = ErrTaskKilled
+	case CancelledTaskState:
+		status.Done = true
+		status.Failure = ErrTaskCancelled
+	}
+
+	return status
+}
+
+// Poll retrieves the current status of the job. If c is nil, the job's own
+// client is used.
+func (j *Job) Poll(c Client) (*WorkStatus, error) {
+	if c == nil {
+		c = j.client
+	}
+
+	job, err := GetObject[Job](c, j.ODataID)
+	if err != nil {
+		return nil, err
+	}
+	return job.workStatus(), nil
+}
+
+func (j *Job) workStatus() *WorkStatus {
+	status := &WorkStatus{
+		State:            string(j.JobState),
+		PercentComplete:  j.PercentComplete,
+		Messages:         j.Messages,
+		CreatedResources: j.createdResources,
+	}
+
+	switch j.JobState {
+	case CompletedJobState:
+		status.Done = true
+	case ExceptionJobState, InvalidJobState:
+		status.Done = true
+		status.Failure = ErrTaskException
+	case CancelledJobState:
+		status.Done = true
+		status.Failure = ErrTaskCancelled
+	}
+
+	return status
+}
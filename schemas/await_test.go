@@ -0,0 +1,164 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const awaitRunningTaskBody = `{
+		"@odata.id": "/redfish/v1/TaskService/Tasks/1",
+		"Id": "1",
+		"Name": "Task 1",
+		"TaskState": "Running",
+		"PercentComplete": 50
+	}`
+
+const awaitCompletedTaskBody = `{
+		"@odata.id": "/redfish/v1/TaskService/Tasks/1",
+		"Id": "1",
+		"Name": "Task 1",
+		"TaskState": "Completed",
+		"PercentComplete": 100,
+		"Messages": [{"MessageId": "Base.1.8.Success", "Message": "Successfully Completed Request"}],
+		"Links": {"CreatedResources": [{"@odata.id": "/redfish/v1/Systems/1/Storage/1/Volumes/2"}]}
+	}`
+
+const awaitExceptionTaskBody = `{
+		"@odata.id": "/redfish/v1/TaskService/Tasks/1",
+		"Id": "1",
+		"Name": "Task 1",
+		"TaskState": "Exception",
+		"Messages": [{"MessageId": "Update.1.0.ApplyFailed", "MessageArgs": ["BIOS"]}]
+	}`
+
+// acceptedCall returns a 202 response with the given body and Retry-After header.
+func acceptedCall(body, retryAfter string) *http.Response {
+	header := make(http.Header)
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	return &http.Response{
+		StatusCode: http.StatusAccepted,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     header,
+	}
+}
+
+// TestAwaitTaskMonitor tests waiting for a task monitor to complete.
+func TestAwaitTaskMonitor(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				acceptedCall(awaitRunningTaskBody, "0"),
+				&http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader("")), Header: make(http.Header)},
+				getCall(awaitCompletedTaskBody),
+			},
+		},
+	}
+
+	var progress []string
+	status, err := Await(context.Background(), testClient,
+		&TaskMonitorInfo{TaskMonitor: "/redfish/v1/TaskService/TaskMonitors/1"},
+		&AwaitOptions{
+			PollRate:   time.Millisecond,
+			OnProgress: func(s *WorkStatus) { progress = append(progress, s.State) },
+		})
+	if err != nil {
+		t.Fatalf("Error awaiting task: %s", err)
+	}
+
+	if !status.Done || status.State != string(CompletedTaskState) {
+		t.Errorf("Unexpected final status: %+v", status)
+	}
+	if len(status.Messages) != 1 || status.Messages[0].Message != "Successfully Completed Request" {
+		t.Errorf("Unexpected messages: %v", status.Messages)
+	}
+	if len(status.CreatedResources) != 1 || status.CreatedResources[0] != "/redfish/v1/Systems/1/Storage/1/Volumes/2" {
+		t.Errorf("Unexpected created resources: %v", status.CreatedResources)
+	}
+	if strings.Join(progress, ",") != "Running,Completed" {
+		t.Errorf("Unexpected progress: %v", progress)
+	}
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 3 || calls[2].URL != "/redfish/v1/TaskService/Tasks/1" {
+		t.Errorf("Expected the task to be retrieved after completion, got: %v", calls)
+	}
+}
+
+// TestAwaitTaskException tests that failed tasks return a TaskError with the
+// resolved message text.
+func TestAwaitTaskException(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				getCall(awaitRunningTaskBody),
+				getCall(awaitExceptionTaskBody),
+			},
+		},
+	}
+
+	task := &Task{Entity: Entity{ODataID: "/redfish/v1/TaskService/Tasks/1"}}
+	status, err := Await(context.Background(), testClient, task, &AwaitOptions{
+		PollRate: time.Millisecond,
+		ResolveMessage: func(m Message) string {
+			return FormatRegistryMessage("Failed to apply update to %1.", m.MessageArgs)
+		},
+	})
+
+	if !errors.Is(err, ErrTaskException) {
+		t.Fatalf("Expected ErrTaskException, got %v", err)
+	}
+
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) {
+		t.Fatalf("Expected a TaskError, got %T", err)
+	}
+	if taskErr.State != string(ExceptionTaskState) {
+		t.Errorf("Unexpected state: %s", taskErr.State)
+	}
+	if !strings.Contains(err.Error(), "Failed to apply update to BIOS.") {
+		t.Errorf("Expected resolved message in error, got: %s", err)
+	}
+	if status == nil || !status.Done {
+		t.Errorf("Expected final status to be returned, got %+v", status)
+	}
+}
+
+// TestAwaitJob tests waiting for a job to complete.
+func TestAwaitJob(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				getCall(`{"@odata.id": "/redfish/v1/JobService/Jobs/1", "Id": "1", "JobState": "Pending"}`),
+				getCall(`{"@odata.id": "/redfish/v1/JobService/Jobs/1", "Id": "1", "JobState": "Cancelled"}`),
+			},
+		},
+	}
+
+	job := &Job{Entity: Entity{ODataID: "/redfish/v1/JobService/Jobs/1"}}
+	_, err := Await(context.Background(), testClient, job, &AwaitOptions{PollRate: time.Millisecond})
+	if !errors.Is(err, ErrTaskCancelled) {
+		t.Errorf("Expected ErrTaskCancelled, got %v", err)
+	}
+}
+
+// TestAwaitContextCancelled tests that Await stops when the context ends.
+func TestAwaitContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Await(ctx, &TestClient{}, &Task{}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context cancelled, got %v", err)
+	}
+}
@@ -224,14 +224,17 @@ func (ba *Battery) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (ba *Battery) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (ba *Battery) writableFields() []string {
+	return []string{
 		"LocationIndicatorActive",
 		"ServicedDate",
 	}
+}
 
-	return ba.UpdateFromRawData(ba, ba.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (ba *Battery) Update() error {
+	return ba.UpdateFromRawData(ba, ba.RawData, ba.writableFields())
 }
 
 // GetBattery will get a Battery instance from the service.
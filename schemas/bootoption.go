@@ -74,13 +74,16 @@ func (bo *BootOption) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (bo *BootOption) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (bo *BootOption) writableFields() []string {
+	return []string{
 		"BootOptionEnabled",
 	}
+}
 
-	return bo.UpdateFromRawData(bo, bo.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (bo *BootOption) Update() error {
+	return bo.UpdateFromRawData(bo, bo.RawData, bo.writableFields())
 }
 
 // GetBootOption will get a BootOption instance from the service.
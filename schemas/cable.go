@@ -227,9 +227,9 @@ func (c *Cable) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *Cable) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *Cable) writableFields() []string {
+	return []string{
 		"AssetTag",
 		"CableClass",
 		"CableStatus",
@@ -248,8 +248,11 @@ func (c *Cable) Update() error {
 		"UserLabel",
 		"Vendor",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *Cable) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetCable will get a Cable instance from the service.
@@ -265,13 +265,16 @@ func (c *Certificate) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *Certificate) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *Certificate) writableFields() []string {
+	return []string{
 		"Password",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *Certificate) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetCertificate will get a Certificate instance from the service.
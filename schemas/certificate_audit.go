@@ -0,0 +1,238 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"bytes"
+	"crypto/dsa" //nolint:staticcheck
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"time"
+)
+
+const (
+	// defaultExpiryWarning is how long before expiry a certificate is
+	// reported as expiring if no warning period was given.
+	defaultExpiryWarning = 30 * 24 * time.Hour
+
+	// defaultMinRSABits is the smallest RSA key size that is not reported as
+	// weak if no minimum was given.
+	defaultMinRSABits = 2048
+
+	// defaultMinECBits is the smallest elliptic curve key size that is not
+	// reported as weak if no minimum was given.
+	defaultMinECBits = 256
+)
+
+// CertificateAuditOptions controls how certificates are assessed.
+type CertificateAuditOptions struct {
+	// ExpiryWarning is how long before expiry a certificate is reported as
+	// expiring. Defaults to 30 days.
+	ExpiryWarning time.Duration
+	// MinRSABits is the smallest RSA key size that is considered strong.
+	// Defaults to 2048.
+	MinRSABits int
+	// MinECBits is the smallest elliptic curve key size that is considered
+	// strong. Defaults to 256.
+	MinECBits int
+	// Now returns the time used to evaluate expiry. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// CertificateAuditEntry contains the assessment of a single certificate.
+type CertificateAuditEntry struct {
+	// URI is the location of the certificate resource.
+	URI string
+	// Certificate is the certificate resource.
+	Certificate *Certificate
+	// X509 is the parsed certificate. It is nil if the certificate could not
+	// be parsed.
+	X509 *x509.Certificate
+	// UsageTypes are the usage types reported by the service.
+	UsageTypes []CertificateUsageType
+	// NotAfter is the time the certificate expires.
+	NotAfter time.Time
+	// ExpiresIn is the time remaining until the certificate expires. It is
+	// negative for expired certificates.
+	ExpiresIn time.Duration
+	// Expired is true if the certificate is no longer valid.
+	Expired bool
+	// Expiring is true if the certificate expires within the warning period.
+	Expiring bool
+	// KeyAlgorithm is the public key algorithm.
+	KeyAlgorithm string
+	// KeyBits is the size of the public key in bits.
+	KeyBits int
+	// WeakKey is true if the key is smaller than the configured minimum or
+	// uses an algorithm that is no longer considered secure.
+	WeakKey bool
+	// SignatureAlgorithm is the algorithm used to sign the certificate.
+	SignatureAlgorithm string
+	// WeakSignature is true if the signature uses MD5 or SHA-1.
+	WeakSignature bool
+	// SelfSigned is true if the certificate is signed by its own key.
+	SelfSigned bool
+	// Err is set if the certificate could not be parsed.
+	Err error
+}
+
+// HasProblem returns true if the certificate could not be parsed, is expired
+// or expiring, or uses a weak key or signature.
+func (e *CertificateAuditEntry) HasProblem() bool {
+	return e.Err != nil || e.Expired || e.Expiring || e.WeakKey || e.WeakSignature
+}
+
+// CertificateAuditReport contains the assessment of a set of certificates.
+type CertificateAuditReport struct {
+	// Entries contains one entry per certificate found.
+	Entries []*CertificateAuditEntry
+	// Errors contains any errors encountered retrieving certificates. The
+	// audit continues past locations that cannot be read.
+	Errors []error
+}
+
+// Problems returns the entries that need attention.
+func (r *CertificateAuditReport) Problems() []*CertificateAuditEntry {
+	var result []*CertificateAuditEntry
+	for _, entry := range r.Entries {
+		if entry.HasProblem() {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// Expiring returns the entries that are expired or expire within the
+// warning period.
+func (r *CertificateAuditReport) Expiring() []*CertificateAuditEntry {
+	var result []*CertificateAuditEntry
+	for _, entry := range r.Entries {
+		if entry.Expired || entry.Expiring {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// AuditCertificates assesses the certificates, skipping any that appear more
+// than once.
+func AuditCertificates(certs []*Certificate, opts *CertificateAuditOptions) *CertificateAuditReport {
+	report := &CertificateAuditReport{}
+	seen := make(map[string]bool)
+	for _, cert := range certs {
+		if cert == nil {
+			continue
+		}
+		if cert.ODataID != "" {
+			if seen[cert.ODataID] {
+				continue
+			}
+			seen[cert.ODataID] = true
+		}
+		report.Entries = append(report.Entries, AuditCertificate(cert, opts))
+	}
+	return report
+}
+
+// AuditCertificate assesses a single certificate.
+func AuditCertificate(cert *Certificate, opts *CertificateAuditOptions) *CertificateAuditEntry {
+	if opts == nil {
+		opts = &CertificateAuditOptions{}
+	}
+	warning := opts.ExpiryWarning
+	if warning <= 0 {
+		warning = defaultExpiryWarning
+	}
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+
+	entry := &CertificateAuditEntry{
+		URI:         cert.ODataID,
+		Certificate: cert,
+		UsageTypes:  cert.CertificateUsageTypes,
+	}
+
+	parsed, err := cert.X509()
+	if err != nil {
+		entry.Err = err
+		// Fall back to what the service reports about the certificate
+		if notAfter, perr := time.Parse(time.RFC3339, cert.ValidNotAfter); perr == nil {
+			entry.setExpiry(notAfter, now, warning)
+		}
+		entry.SignatureAlgorithm = cert.SignatureAlgorithm
+		return entry
+	}
+
+	entry.X509 = parsed
+	entry.setExpiry(parsed.NotAfter, now, warning)
+	entry.SignatureAlgorithm = parsed.SignatureAlgorithm.String()
+	entry.WeakSignature = isWeakSignature(parsed.SignatureAlgorithm)
+	entry.KeyAlgorithm = parsed.PublicKeyAlgorithm.String()
+	entry.KeyBits, entry.WeakKey = keyStrength(parsed, opts)
+	entry.SelfSigned = isSelfSigned(parsed)
+
+	return entry
+}
+
+func (e *CertificateAuditEntry) setExpiry(notAfter, now time.Time, warning time.Duration) {
+	e.NotAfter = notAfter
+	e.ExpiresIn = notAfter.Sub(now)
+	e.Expired = e.ExpiresIn <= 0
+	e.Expiring = !e.Expired && e.ExpiresIn <= warning
+}
+
+// isSelfSigned returns true if the certificate is signed by its own key. The
+// CA constraints checked by CheckSignatureFrom are not required, since many
+// services present self signed leaf certificates.
+func isSelfSigned(cert *x509.Certificate) bool {
+	if !bytes.Equal(cert.RawIssuer, cert.RawSubject) {
+		return false
+	}
+	return cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+// isWeakSignature returns true for signature algorithms using MD2, MD5 or
+// SHA-1.
+func isWeakSignature(alg x509.SignatureAlgorithm) bool {
+	switch alg {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA,
+		x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+		return true
+	default:
+		return false
+	}
+}
+
+// keyStrength returns the public key size and whether it is considered weak.
+func keyStrength(cert *x509.Certificate, opts *CertificateAuditOptions) (bits int, weak bool) {
+	minRSA := opts.MinRSABits
+	if minRSA <= 0 {
+		minRSA = defaultMinRSABits
+	}
+	minEC := opts.MinECBits
+	if minEC <= 0 {
+		minEC = defaultMinECBits
+	}
+
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		bits = key.N.BitLen()
+		return bits, bits < minRSA
+	case *ecdsa.PublicKey:
+		bits = key.Curve.Params().BitSize
+		return bits, bits < minEC
+	case ed25519.PublicKey:
+		return 256, false
+	case *dsa.PublicKey:
+		// DSA is deprecated regardless of size
+		return key.P.BitLen(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,108 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// auditCertificate creates a Certificate resource for a new key, signed by
+// the CA if one is given or self signed otherwise.
+func auditCertificate(t *testing.T, uri string, curve elliptic.Curve, notAfter time.Time, ca *LocalCA) *Certificate {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: uri},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	parent, signer := template, any(key)
+	if ca != nil {
+		parent, signer = ca.Certificate, ca.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Certificate{
+		Entity:                Entity{ODataID: uri},
+		CertificateType:       PEMCertificateType,
+		CertificateString:     EncodeCertificatesPEM([]*x509.Certificate{cert}),
+		CertificateUsageTypes: []CertificateUsageType{WebCertificateUsageType},
+	}
+}
+
+// TestAuditCertificates tests assessing a set of certificates.
+func TestAuditCertificates(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ca := testCA(t)
+
+	healthy := auditCertificate(t, "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1",
+		elliptic.P256(), now.Add(300*24*time.Hour), ca)
+	expiring := auditCertificate(t, "/redfish/v1/AccountService/LDAP/Certificates/1",
+		elliptic.P256(), now.Add(10*24*time.Hour), nil)
+	weak := auditCertificate(t, "/redfish/v1/Systems/1/Certificates/1",
+		elliptic.P224(), now.Add(300*24*time.Hour), nil)
+	expired := &Certificate{
+		Entity:            Entity{ODataID: "/redfish/v1/Systems/1/Boot/Certificates/1"},
+		CertificateType:   PKCS7CertificateType,
+		ValidNotAfter:     "2025-06-01T00:00:00Z",
+		CertificateString: "opaque",
+	}
+
+	report := AuditCertificates([]*Certificate{healthy, expiring, weak, expired, healthy},
+		&CertificateAuditOptions{Now: func() time.Time { return now }})
+
+	if len(report.Entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(report.Entries))
+	}
+
+	entry := report.Entries[0]
+	if entry.HasProblem() || entry.SelfSigned || entry.KeyBits != 256 || entry.KeyAlgorithm != "ECDSA" {
+		t.Errorf("Unexpected healthy entry: %+v", entry)
+	}
+	if entry.UsageTypes[0] != WebCertificateUsageType {
+		t.Errorf("Unexpected usage types: %v", entry.UsageTypes)
+	}
+
+	entry = report.Entries[1]
+	if !entry.Expiring || entry.Expired || !entry.SelfSigned {
+		t.Errorf("Expected self signed expiring entry: %+v", entry)
+	}
+
+	entry = report.Entries[2]
+	if !entry.WeakKey || entry.KeyBits != 224 {
+		t.Errorf("Expected weak key entry: %+v", entry)
+	}
+
+	entry = report.Entries[3]
+	if entry.Err == nil || !entry.Expired {
+		t.Errorf("Expected unparsed expired entry: %+v", entry)
+	}
+
+	if len(report.Problems()) != 3 {
+		t.Errorf("Expected 3 problems, got %d", len(report.Problems()))
+	}
+	if len(report.Expiring()) != 2 {
+		t.Errorf("Expected 2 expiring, got %d", len(report.Expiring()))
+	}
+}
@@ -0,0 +1,398 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultCertificateValidity is how long certificates signed by a LocalCA
+	// are valid for if no validity was given.
+	defaultCertificateValidity = 365 * 24 * time.Hour
+
+	// defaultCertificateVerifyPollRate is how often the TLS endpoint is
+	// checked while waiting for a new certificate to be served.
+	defaultCertificateVerifyPollRate = 5 * time.Second
+)
+
+// ErrUnsupportedCertificateType is returned when a certificate string is not
+// in a format that can be parsed locally.
+var ErrUnsupportedCertificateType = errors.New("unsupported certificate type")
+
+// ParseCertificateChain parses all PEM encoded certificates in data. The
+// certificates are returned in the order they appear, which for a chain is
+// normally the leaf certificate first.
+func ParseCertificateChain(data string) ([]*x509.Certificate, error) {
+	var result []*x509.Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, cert)
+	}
+
+	if len(result) == 0 {
+		return nil, errors.New("no PEM encoded certificates found")
+	}
+
+	return result, nil
+}
+
+// X509Chain parses the CertificateString of this certificate. Only PEM and
+// PEMchain certificate types are supported.
+func (c *Certificate) X509Chain() ([]*x509.Certificate, error) {
+	switch c.CertificateType {
+	case "", PEMCertificateType, PEMchainCertificateType:
+		return ParseCertificateChain(c.CertificateString)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCertificateType, c.CertificateType)
+	}
+}
+
+// X509 parses the CertificateString of this certificate and returns the
+// first, or leaf, certificate. This gives access to details such as the
+// NotAfter time and subject alternative names.
+func (c *Certificate) X509() (*x509.Certificate, error) {
+	chain, err := c.X509Chain()
+	if err != nil {
+		return nil, err
+	}
+	return chain[0], nil
+}
+
+// CSRSigner signs certificate signing requests.
+type CSRSigner interface {
+	// SignCSR signs the request and returns the issued certificate followed by
+	// any intermediate certificates needed to build the chain.
+	SignCSR(csr *x509.CertificateRequest) ([]*x509.Certificate, error)
+}
+
+// LocalCA is a CSRSigner that signs requests with a caller supplied CA
+// certificate and key.
+type LocalCA struct {
+	// Certificate is the CA certificate.
+	Certificate *x509.Certificate
+	// Key is the private key of the CA certificate.
+	Key crypto.Signer
+	// Validity is how long issued certificates are valid for. Defaults to
+	// one year.
+	Validity time.Duration
+	// ExtKeyUsage is the extended key usage of issued certificates. Defaults
+	// to server authentication.
+	ExtKeyUsage []x509.ExtKeyUsage
+	// IncludeCA adds the CA certificate to the returned chain.
+	IncludeCA bool
+}
+
+// SignCSR signs the request with the CA key.
+func (ca *LocalCA) SignCSR(csr *x509.CertificateRequest) ([]*x509.Certificate, error) {
+	if ca.Certificate == nil || ca.Key == nil {
+		return nil, errors.New("CA certificate and key are required")
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid certificate signing request: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	validity := ca.Validity
+	if validity <= 0 {
+		validity = defaultCertificateValidity
+	}
+	extKeyUsage := ca.ExtKeyUsage
+	if len(extKeyUsage) == 0 {
+		extKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	// Key encipherment is only meaningful for RSA keys, which are used to
+	// encrypt the key exchange.
+	keyUsage := x509.KeyUsageDigitalSignature
+	if csr.PublicKeyAlgorithm == x509.RSA {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:   serial,
+		Subject:        csr.Subject,
+		DNSNames:       csr.DNSNames,
+		IPAddresses:    csr.IPAddresses,
+		EmailAddresses: csr.EmailAddresses,
+		URIs:           csr.URIs,
+		NotBefore:      now.Add(-5 * time.Minute),
+		NotAfter:       now.Add(validity),
+		KeyUsage:       keyUsage,
+		ExtKeyUsage:    extKeyUsage,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Certificate, csr.PublicKey, ca.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*x509.Certificate{cert}
+	if ca.IncludeCA {
+		result = append(result, ca.Certificate)
+	}
+	return result, nil
+}
+
+// EncodeCertificatesPEM returns the PEM encoding of the certificates.
+func EncodeCertificatesPEM(certs []*x509.Certificate) string {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		_ = pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.String()
+}
+
+// CertificateRotationOptions controls how a certificate is rotated.
+type CertificateRotationOptions struct {
+	// CSR holds the parameters for the certificate signing request. The
+	// CertificateCollection parameter is filled in automatically.
+	CSR CertificateServiceGenerateCSRParameters
+	// Signer signs the certificate signing request. If nil, rotation stops
+	// once the CSR has been generated so it can be signed externally and
+	// installed with InstallCertificate.
+	Signer CSRSigner
+	// Replace is the URI of the certificate to replace with the
+	// ReplaceCertificate action. If empty, the new certificate is added by
+	// posting it to the certificate collection.
+	Replace string
+	// VerifyAddress is the host:port of the TLS endpoint that should serve the
+	// new certificate. If empty, the served certificate is not verified.
+	VerifyAddress string
+	// VerifyPollRate is the interval between checks of the TLS endpoint.
+	// Defaults to five seconds.
+	VerifyPollRate time.Duration
+	// AwaitOptions controls waiting for any task started to install the
+	// certificate.
+	AwaitOptions *AwaitOptions
+}
+
+// CertificateRotationResult contains the outcome of a certificate rotation.
+type CertificateRotationResult struct {
+	// CSR is the PEM encoded certificate signing request from the service.
+	CSR string
+	// Certificates is the signed certificate chain that was installed.
+	Certificates []*x509.Certificate
+	// CertificateURI is the URI of the installed certificate, if known.
+	CertificateURI string
+	// Verified is true if the TLS endpoint was seen serving the new
+	// certificate.
+	Verified bool
+}
+
+// RotateCertificate generates a new certificate signing request for the
+// certificate collection, signs it, installs the signed certificate and
+// optionally waits for the new certificate to be served over TLS.
+//
+// The collection is normally the HTTPS certificate collection of a manager,
+// available from Manager.HTTPSCertificateLocation.
+func (c *CertificateService) RotateCertificate(ctx context.Context, collection string, opts *CertificateRotationOptions) (*CertificateRotationResult, error) {
+	if opts == nil {
+		opts = &CertificateRotationOptions{}
+	}
+
+	params := opts.CSR
+	params.CertificateCollection = collection
+	csrResp, err := c.GenerateCSR(&params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CSR: %w", err)
+	}
+
+	result := &CertificateRotationResult{CSR: csrResp.CSRString}
+	if opts.Signer == nil {
+		return result, nil
+	}
+
+	block, _ := pem.Decode([]byte(csrResp.CSRString))
+	if block == nil {
+		return result, errors.New("service returned an invalid CSR")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return result, fmt.Errorf("failed to parse CSR: %w", err)
+	}
+
+	result.Certificates, err = opts.Signer.SignCSR(csr)
+	if err != nil {
+		return result, fmt.Errorf("failed to sign CSR: %w", err)
+	}
+	if len(result.Certificates) == 0 {
+		return result, errors.New("signer returned no certificates")
+	}
+
+	result.CertificateURI, err = c.InstallCertificate(ctx, collection, opts.Replace,
+		EncodeCertificatesPEM(result.Certificates), opts.AwaitOptions)
+	if err != nil {
+		return result, err
+	}
+
+	if opts.VerifyAddress != "" {
+		err = VerifyServedCertificate(ctx, opts.VerifyAddress, result.Certificates[0], opts.VerifyPollRate)
+		if err != nil {
+			return result, err
+		}
+		result.Verified = true
+	}
+
+	return result, nil
+}
+
+// InstallCertificate installs a PEM encoded certificate. If replace is set,
+// the certificate at that URI is replaced using the ReplaceCertificate
+// action. Otherwise the certificate is posted to the collection. The URI of
+// the installed certificate is returned if the service reports it.
+func (c *CertificateService) InstallCertificate(ctx context.Context, collection, replace, certPEM string, awaitOpts *AwaitOptions) (string, error) {
+	certType := PEMCertificateType
+	if chain, err := ParseCertificateChain(certPEM); err == nil && len(chain) > 1 {
+		certType = PEMchainCertificateType
+	}
+
+	if replace != "" {
+		taskInfo, err := c.ReplaceCertificate(&CertificateServiceReplaceCertificateParameters{
+			CertificateString: certPEM,
+			CertificateType:   certType,
+			CertificateURI:    replace,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to replace certificate: %w", err)
+		}
+		if taskInfo != nil {
+			if _, err := Await(ctx, c.client, taskInfo, awaitOpts); err != nil {
+				return "", fmt.Errorf("failed to replace certificate: %w", err)
+			}
+		}
+		return replace, nil
+	}
+
+	if collection == "" {
+		return "", errors.New("a certificate collection or certificate to replace is required")
+	}
+
+	payload := struct {
+		CertificateString string
+		CertificateType   CertificateType
+	}{
+		CertificateString: certPEM,
+		CertificateType:   certType,
+	}
+	resp, taskInfo, err := PostWithTask(c.client, collection, payload, c.Headers(), false)
+	defer DeferredCleanupHTTPResponse(resp)
+	if err != nil {
+		return "", fmt.Errorf("failed to add certificate: %w", err)
+	}
+	if taskInfo != nil {
+		status, err := Await(ctx, c.client, taskInfo, awaitOpts)
+		if err != nil {
+			return "", fmt.Errorf("failed to add certificate: %w", err)
+		}
+		if len(status.CreatedResources) > 0 {
+			return status.CreatedResources[0], nil
+		}
+		return "", nil
+	}
+
+	if resp != nil && resp.StatusCode == http.StatusCreated {
+		return resp.Header.Get("Location"), nil
+	}
+	return "", nil
+}
+
+// VerifyServedCertificate connects to the TLS endpoint at address until it
+// presents the expected certificate or the context ends. Services often
+// restart their web server to apply a new certificate, so connection failures
+// are retried.
+func VerifyServedCertificate(ctx context.Context, address string, expected *x509.Certificate, pollRate time.Duration) error {
+	if pollRate <= 0 {
+		pollRate = defaultCertificateVerifyPollRate
+	}
+
+	dialer := &tls.Dialer{
+		Config: &tls.Config{
+			// Only the presented certificate is compared, trust is not needed.
+			InsecureSkipVerify: true, //nolint:gosec
+		},
+	}
+
+	var lastErr error
+	for {
+		served, err := servedCertificate(ctx, dialer, address)
+		if err == nil {
+			if served.Equal(expected) {
+				return nil
+			}
+			lastErr = fmt.Errorf("endpoint is serving certificate with serial %s", served.SerialNumber)
+		} else {
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("new certificate not served by %s: %w (last result: %w)", address, ctx.Err(), lastErr)
+		case <-time.After(pollRate):
+		}
+	}
+}
+
+// servedCertificate returns the leaf certificate presented by address.
+func servedCertificate(ctx context.Context, dialer *tls.Dialer, address string) (*x509.Certificate, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return nil, errors.New("no certificate presented")
+	}
+	return certs[0], nil
+}
+
+// HTTPSCertificateLocation returns the URI of the certificate collection for
+// the manager's HTTPS service.
+func (m *Manager) HTTPSCertificateLocation() (string, error) {
+	protocol, err := m.NetworkProtocol()
+	if err != nil {
+		return "", err
+	}
+	if protocol == nil || protocol.HTTPS.certificates == "" {
+		return "", errors.New("manager does not report an HTTPS certificate collection")
+	}
+	return protocol.HTTPS.certificates, nil
+}
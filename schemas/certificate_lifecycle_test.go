@@ -0,0 +1,197 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA creates a self signed CA for signing test certificates.
+func testCA(t *testing.T) *LocalCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &LocalCA{Certificate: cert, Key: key, IncludeCA: true}
+}
+
+// testCSR creates a PEM encoded certificate signing request.
+func testCSR(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCSRWithKey(t, key)
+}
+
+// testCSRWithKey creates a PEM encoded certificate signing request for the key.
+func testCSRWithKey(t *testing.T, key crypto.Signer) string {
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "bmc.example.com"},
+		DNSNames: []string{"bmc.example.com"},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}))
+}
+
+// TestRotateCertificate tests generating, signing and replacing a certificate.
+func TestRotateCertificate(t *testing.T) {
+	var result CertificateService
+	err := json.NewDecoder(strings.NewReader(serviceBody)).Decode(&result)
+	if err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	csrResponse, _ := json.Marshal(map[string]string{"CSRString": testCSR(t)})
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodPost: {getCall(string(csrResponse)), nil},
+		},
+	}
+	result.SetClient(testClient)
+
+	ca := testCA(t)
+	rotation, err := result.RotateCertificate(context.Background(),
+		"/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates",
+		&CertificateRotationOptions{
+			CSR:     CertificateServiceGenerateCSRParameters{CommonName: "bmc.example.com"},
+			Signer:  ca,
+			Replace: "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1",
+		})
+	if err != nil {
+		t.Fatalf("Error rotating certificate: %s", err)
+	}
+
+	if len(rotation.Certificates) != 2 {
+		t.Fatalf("Expected a chain of 2 certificates, got %d", len(rotation.Certificates))
+	}
+	leaf := rotation.Certificates[0]
+	if err := leaf.CheckSignatureFrom(ca.Certificate); err != nil {
+		t.Errorf("Certificate not signed by CA: %s", err)
+	}
+	if len(leaf.DNSNames) != 1 || leaf.DNSNames[0] != "bmc.example.com" {
+		t.Errorf("Unexpected SANs: %v", leaf.DNSNames)
+	}
+	AssertEqual(t, "/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1", rotation.CertificateURI)
+
+	calls := testClient.CapturedCalls()
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 calls, got %d", len(calls))
+	}
+	if !strings.Contains(calls[0].Payload, "CertificateCollection:/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates") {
+		t.Errorf("Unexpected CSR payload: %s", calls[0].Payload)
+	}
+	if !strings.Contains(calls[1].Payload, "CertificateType:PEMchain") ||
+		!strings.Contains(calls[1].Payload, "CertificateUri:/redfish/v1/Managers/1/NetworkProtocol/HTTPS/Certificates/1") {
+		t.Errorf("Unexpected replace payload: %s", calls[1].Payload)
+	}
+}
+
+// TestLocalCAKeyUsage tests key encipherment is only allowed for RSA keys.
+func TestLocalCAKeyUsage(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key      crypto.Signer
+		expected x509.KeyUsage
+	}{
+		{ecKey, x509.KeyUsageDigitalSignature},
+		{rsaKey, x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment},
+	}
+	ca := testCA(t)
+	for _, tt := range tests {
+		block, _ := pem.Decode([]byte(testCSRWithKey(t, tt.key)))
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain, err := ca.SignCSR(csr)
+		if err != nil {
+			t.Fatalf("Error signing CSR: %s", err)
+		}
+		AssertEqual(t, tt.expected, chain[0].KeyUsage)
+	}
+}
+
+// TestCertificateX509 tests parsing the certificate string.
+func TestCertificateX509(t *testing.T) {
+	ca := testCA(t)
+	cert := &Certificate{
+		CertificateType:   PEMCertificateType,
+		CertificateString: EncodeCertificatesPEM([]*x509.Certificate{ca.Certificate}),
+	}
+
+	parsed, err := cert.X509()
+	if err != nil {
+		t.Fatalf("Error parsing certificate: %s", err)
+	}
+	AssertEqual(t, "Test CA", parsed.Subject.CommonName)
+
+	cert.CertificateType = PKCS7CertificateType
+	_, err = cert.X509()
+	RequireErrorContains(t, err, "unsupported certificate type")
+}
+
+// TestVerifyServedCertificate tests checking the certificate served by a TLS
+// endpoint.
+func TestVerifyServedCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "https://")
+
+	err := VerifyServedCertificate(context.Background(), address, server.Certificate(), time.Millisecond)
+	if err != nil {
+		t.Errorf("Expected served certificate to match: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = VerifyServedCertificate(ctx, address, testCA(t).Certificate, time.Millisecond)
+	RequireErrorContains(t, err, "new certificate not served")
+}
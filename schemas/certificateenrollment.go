@@ -152,16 +152,19 @@ func (c *CertificateEnrollment) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *CertificateEnrollment) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *CertificateEnrollment) writableFields() []string {
+	return []string{
 		"Enabled",
 		"RenewBeforeExpiryDays",
 		"ServerURI",
 		"VerifyCertificate",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *CertificateEnrollment) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetCertificateEnrollment will get a CertificateEnrollment instance from the service.
@@ -715,9 +715,9 @@ func (c *Chassis) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *Chassis) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *Chassis) writableFields() []string {
+	return []string{
 		"AssetTag",
 		"ElectricalSourceManagerURIs",
 		"ElectricalSourceNames",
@@ -732,8 +732,11 @@ func (c *Chassis) Update() error {
 		"RackUnits",
 		"ReadyToRemove",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *Chassis) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetChassis will get a Chassis instance from the service.
@@ -0,0 +1,440 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SensorReading is a reading of a chassis, in the same form whichever
+// resource the service reports it in.
+type SensorReading struct {
+	// Kind is the type of the reading, such as Temperature or Power.
+	Kind ReadingType
+	// Name is the name of the sensor, or of the resource and property the
+	// reading came from.
+	Name string
+	// Value is the reading, or nil if the service did not report it.
+	Value *float64
+	// Units are the UCUM units of the reading, such as "Cel", "W" or "RPM".
+	Units string
+	// Thresholds are the thresholds of the reading, if any.
+	Thresholds Thresholds
+	// ReadingRangeMin is the lowest possible reading, if known.
+	ReadingRangeMin *float64
+	// ReadingRangeMax is the highest possible reading, if known.
+	ReadingRangeMax *float64
+	// PhysicalContext is the area or device the reading applies to.
+	PhysicalContext PhysicalContext
+	// Health is the health of the sensor or of the resource of the reading.
+	Health Health
+	// State is the state of the sensor or of the resource of the reading.
+	State State
+	// SourceURI is the URI of the sensor, or of the resource and JSON pointer
+	// of the property the reading came from, such as
+	// "/redfish/v1/Chassis/1/Thermal#/Temperatures/0".
+	SourceURI string
+}
+
+// Readings gets the sensor readings of the chassis.
+//
+// Services report readings in the Sensors collection, in the PowerSubsystem
+// and ThermalSubsystem resources, in EnvironmentMetrics, or in the deprecated
+// Power and Thermal resources. The Sensors collection is used if the chassis
+// has one with members. Otherwise the readings are gathered from the power
+// supplies, fans, thermal metrics and environment metrics. The deprecated
+// Power and Thermal resources are read for the kinds of readings the
+// PowerSubsystem or ThermalSubsystem don't report, such as the temperatures of
+// a service that only reports fans in its ThermalSubsystem.
+//
+// Resources and collections are read with the query options of the client, so
+// $expand is used if the client was connected with AutoExpand and the service
+// supports it. The opts are applied in addition. If some resources could not be
+// read the readings of the others are returned together with the error.
+func (c *Chassis) Readings(opts ...QueryGroupOption) ([]*SensorReading, error) {
+	var errs []error
+	if c.sensors != "" {
+		sensors, err := GetCollectionObjects[Sensor](c.client, c.sensors, opts...)
+		errs = append(errs, err)
+		if len(sensors) > 0 {
+			slices.SortFunc(sensors, func(a, b *Sensor) int { return strings.Compare(a.ODataID, b.ODataID) })
+			readings := make([]*SensorReading, 0, len(sensors))
+			for _, sensor := range sensors {
+				readings = append(readings, sensor.SensorReading())
+			}
+			return readings, errors.Join(errs...)
+		}
+	}
+
+	// Services may implement both the subsystems and the deprecated
+	// resources, so the deprecated ones only add the kinds of readings the
+	// subsystems are missing
+	readings, err := c.powerSubsystemReadings(opts...)
+	errs = append(errs, err)
+	if c.power != "" && !hasReadingKinds(readings, PowerReadingType, VoltageReadingType) {
+		power, err := GetObject[Power](c.client, c.power, opts...)
+		if err == nil {
+			readings = addMissingKinds(readings, power.SensorReadings())
+		}
+		errs = append(errs, err)
+	}
+
+	thermal, err := c.thermalSubsystemReadings(opts...)
+	errs = append(errs, err)
+	if c.thermal != "" && !hasReadingKinds(thermal, TemperatureReadingType, RotationalReadingType) {
+		legacy, err := GetObject[Thermal](c.client, c.thermal, opts...)
+		if err == nil {
+			thermal = addMissingKinds(thermal, legacy.SensorReadings())
+		}
+		errs = append(errs, err)
+	}
+	readings = append(readings, thermal...)
+
+	if c.environmentMetrics != "" {
+		metrics, err := GetObject[EnvironmentMetrics](c.client, c.environmentMetrics, opts...)
+		if err == nil {
+			readings = append(readings, metrics.SensorReadings()...)
+		}
+		errs = append(errs, err)
+	}
+
+	return readings, errors.Join(errs...)
+}
+
+func (c *Chassis) powerSubsystemReadings(opts ...QueryGroupOption) ([]*SensorReading, error) {
+	if c.powerSubsystem == "" {
+		return nil, nil
+	}
+	subsystem, err := GetObject[PowerSubsystem](c.client, c.powerSubsystem, opts...)
+	if err != nil {
+		return nil, err
+	}
+	supplies, err := GetCollectionObjects[PowerSupplyUnit](c.client, subsystem.powerSupplies, opts...)
+	errs := []error{err}
+	slices.SortFunc(supplies, func(a, b *PowerSupplyUnit) int { return strings.Compare(a.ODataID, b.ODataID) })
+
+	var readings []*SensorReading
+	for _, supply := range supplies {
+		if supply.metrics == "" {
+			continue
+		}
+		metrics, err := GetObject[PowerSupplyMetrics](c.client, supply.metrics, opts...)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r := readingSource{name: supply.Name, uri: metrics.ODataID, status: supply.Status}
+		readings = append(readings,
+			r.excerpt(PowerReadingType, "W", "Input Power", "InputPowerWatts",
+				metrics.InputPowerWatts.DataSourceURI, metrics.InputPowerWatts.Reading),
+			r.excerpt(PowerReadingType, "W", "Output Power", "OutputPowerWatts",
+				metrics.OutputPowerWatts.DataSourceURI, metrics.OutputPowerWatts.Reading),
+			r.excerpt(VoltageReadingType, "V", "Input Voltage", "InputVoltage",
+				metrics.InputVoltage.DataSourceURI, metrics.InputVoltage.Reading),
+			r.excerpt(CurrentReadingType, "A", "Input Current", "InputCurrentAmps",
+				metrics.InputCurrentAmps.DataSourceURI, metrics.InputCurrentAmps.Reading),
+			r.excerpt(TemperatureReadingType, "Cel", "Temperature", "TemperatureCelsius",
+				metrics.TemperatureCelsius.DataSourceURI, metrics.TemperatureCelsius.Reading))
+	}
+	return compactReadings(readings), errors.Join(errs...)
+}
+
+func (c *Chassis) thermalSubsystemReadings(opts ...QueryGroupOption) ([]*SensorReading, error) {
+	if c.thermalSubsystem == "" {
+		return nil, nil
+	}
+	subsystem, err := GetObject[ThermalSubsystem](c.client, c.thermalSubsystem, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	var readings []*SensorReading
+	if subsystem.fans != "" {
+		fans, err := GetCollectionObjects[Fan](c.client, subsystem.fans, opts...)
+		errs = append(errs, err)
+		slices.SortFunc(fans, func(a, b *Fan) int { return strings.Compare(a.ODataID, b.ODataID) })
+		for _, fan := range fans {
+			r := readingSource{name: fan.Name, uri: fan.ODataID, physicalContext: fan.PhysicalContext, status: fan.Status}
+			readings = append(readings,
+				r.excerpt(PercentReadingType, "%", "Speed", "SpeedPercent",
+					fan.SpeedPercent.DataSourceURI, fan.SpeedPercent.Reading),
+				r.excerpt(RotationalReadingType, "RPM", "Speed", "SpeedPercent/SpeedRPM",
+					fan.SpeedPercent.DataSourceURI, fan.SpeedPercent.SpeedRPM))
+		}
+	}
+
+	if subsystem.thermalMetrics != "" {
+		metrics, err := GetObject[ThermalMetrics](c.client, subsystem.thermalMetrics, opts...)
+		if err == nil {
+			readings = append(readings, metrics.SensorReadings()...)
+		}
+		errs = append(errs, err)
+	}
+	return compactReadings(readings), errors.Join(errs...)
+}
+
+// hasReadingKinds returns whether there are readings of each of the kinds.
+// Fan speeds in percent count as rotational readings.
+func hasReadingKinds(readings []*SensorReading, kinds ...ReadingType) bool {
+	for _, kind := range kinds {
+		if !slices.ContainsFunc(readings, func(reading *SensorReading) bool {
+			return fanSpeedKind(reading.Kind) == kind
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// addMissingKinds adds the legacy readings of the kinds the readings don't
+// include.
+func addMissingKinds(readings, legacy []*SensorReading) []*SensorReading {
+	present := make(map[ReadingType]bool)
+	for _, reading := range readings {
+		present[fanSpeedKind(reading.Kind)] = true
+	}
+	for _, reading := range legacy {
+		if !present[fanSpeedKind(reading.Kind)] {
+			readings = append(readings, reading)
+		}
+	}
+	return readings
+}
+
+// fanSpeedKind treats fan speeds in percent and RPM as the same kind of
+// reading, as services report one or the other.
+func fanSpeedKind(kind ReadingType) ReadingType {
+	if kind == PercentReadingType {
+		return RotationalReadingType
+	}
+	return kind
+}
+
+// SensorReading converts the sensor to a SensorReading.
+func (s *Sensor) SensorReading() *SensorReading {
+	return &SensorReading{
+		Kind:            s.ReadingType,
+		Name:            s.Name,
+		Value:           s.Reading,
+		Units:           s.ReadingUnits,
+		Thresholds:      s.Thresholds,
+		ReadingRangeMin: s.ReadingRangeMin,
+		ReadingRangeMax: s.ReadingRangeMax,
+		PhysicalContext: s.PhysicalContext,
+		Health:          s.Status.Health,
+		State:           s.Status.State,
+		SourceURI:       s.ODataID,
+	}
+}
+
+// SensorReadings gets the power consumption, voltage and power supply
+// readings of the deprecated Power resource.
+func (p *Power) SensorReadings() []*SensorReading {
+	var readings []*SensorReading
+	for i := range p.PowerControl {
+		control := &p.PowerControl[i]
+		readings = append(readings, &SensorReading{
+			Kind:            PowerReadingType,
+			Name:            control.Name,
+			Value:           toFloat64(control.PowerConsumedWatts),
+			Units:           "W",
+			PhysicalContext: control.PhysicalContext,
+			Health:          control.Status.Health,
+			State:           control.Status.State,
+			SourceURI:       memberURI(control.ODataID, p.ODataID, "PowerControl", i),
+		})
+	}
+	for i := range p.Voltages {
+		voltage := &p.Voltages[i]
+		readings = append(readings, &SensorReading{
+			Kind:  VoltageReadingType,
+			Name:  voltage.Name,
+			Value: toFloat64(voltage.ReadingVolts),
+			Units: "V",
+			Thresholds: legacyThresholds(voltage.LowerThresholdNonCritical, voltage.LowerThresholdCritical,
+				voltage.LowerThresholdFatal, voltage.UpperThresholdNonCritical, voltage.UpperThresholdCritical,
+				voltage.UpperThresholdFatal),
+			ReadingRangeMin: toFloat64(voltage.MinReadingRange),
+			ReadingRangeMax: toFloat64(voltage.MaxReadingRange),
+			PhysicalContext: voltage.PhysicalContext,
+			Health:          voltage.Status.Health,
+			State:           voltage.Status.State,
+			SourceURI:       memberURI(voltage.ODataID, p.ODataID, "Voltages", i),
+		})
+	}
+	for i := range p.PowerSupplies {
+		supply := &p.PowerSupplies[i]
+		r := readingSource{
+			name:            supply.Name,
+			uri:             memberURI(supply.ODataID, p.ODataID, "PowerSupplies", i),
+			physicalContext: PowerSupplyPhysicalContext,
+			status:          supply.Status,
+		}
+		readings = append(readings,
+			r.excerpt(PowerReadingType, "W", "Input Power", "PowerInputWatts", "", toFloat64(supply.PowerInputWatts)),
+			r.excerpt(PowerReadingType, "W", "Output Power", "PowerOutputWatts", "", toFloat64(supply.PowerOutputWatts)),
+			r.excerpt(VoltageReadingType, "V", "Input Voltage", "LineInputVoltage", "", toFloat64(supply.LineInputVoltage)))
+	}
+	return compactReadings(readings)
+}
+
+// SensorReadings gets the temperature and fan readings of the deprecated
+// Thermal resource.
+func (t *Thermal) SensorReadings() []*SensorReading {
+	var readings []*SensorReading
+	for i := range t.Temperatures {
+		temperature := &t.Temperatures[i]
+		readings = append(readings, &SensorReading{
+			Kind:  TemperatureReadingType,
+			Name:  temperature.Name,
+			Value: temperature.ReadingCelsius,
+			Units: "Cel",
+			Thresholds: legacyThresholds(temperature.LowerThresholdNonCritical, temperature.LowerThresholdCritical,
+				temperature.LowerThresholdFatal, temperature.UpperThresholdNonCritical, temperature.UpperThresholdCritical,
+				temperature.UpperThresholdFatal),
+			ReadingRangeMin: temperature.MinReadingRangeTemp,
+			ReadingRangeMax: temperature.MaxReadingRangeTemp,
+			PhysicalContext: temperature.PhysicalContext,
+			Health:          temperature.Status.Health,
+			State:           temperature.Status.State,
+			SourceURI:       memberURI(temperature.ODataID, t.ODataID, "Temperatures", i),
+		})
+	}
+	for i := range t.Fans {
+		fan := &t.Fans[i]
+		kind, units := RotationalReadingType, "RPM"
+		if fan.ReadingUnits == PercentReadingUnits {
+			kind, units = PercentReadingType, "%"
+		}
+		name := fan.Name
+		if name == "" {
+			name = fan.FanName
+		}
+		readings = append(readings, &SensorReading{
+			Kind:  kind,
+			Name:  name,
+			Value: toFloat64(fan.Reading),
+			Units: units,
+			Thresholds: legacyThresholds(fan.LowerThresholdNonCritical, fan.LowerThresholdCritical,
+				fan.LowerThresholdFatal, fan.UpperThresholdNonCritical, fan.UpperThresholdCritical,
+				fan.UpperThresholdFatal),
+			ReadingRangeMin: toFloat64(fan.MinReadingRange),
+			ReadingRangeMax: toFloat64(fan.MaxReadingRange),
+			PhysicalContext: fan.PhysicalContext,
+			Health:          fan.Status.Health,
+			State:           fan.Status.State,
+			SourceURI:       memberURI(fan.ODataID, t.ODataID, "Fans", i),
+		})
+	}
+	return readings
+}
+
+// SensorReadings gets the temperature readings of the thermal metrics.
+func (t *ThermalMetrics) SensorReadings() []*SensorReading {
+	readings := make([]*SensorReading, 0, len(t.TemperatureReadingsCelsius))
+	for i := range t.TemperatureReadingsCelsius {
+		temperature := &t.TemperatureReadingsCelsius[i]
+		r := readingSource{name: temperature.DeviceName, uri: t.ODataID, physicalContext: temperature.PhysicalContext}
+		readings = append(readings, r.excerpt(TemperatureReadingType, "Cel", "Temperature",
+			fmt.Sprintf("TemperatureReadingsCelsius/%d", i), temperature.DataSourceURI, temperature.Reading))
+	}
+	return compactReadings(readings)
+}
+
+// SensorReadings gets the readings of the environment metrics.
+func (e *EnvironmentMetrics) SensorReadings() []*SensorReading {
+	r := readingSource{name: e.Name, uri: e.ODataID}
+	readings := []*SensorReading{
+		r.excerpt(TemperatureReadingType, "Cel", "Temperature", "TemperatureCelsius",
+			e.TemperatureCelsius.DataSourceURI, e.TemperatureCelsius.Reading),
+		r.excerpt(HumidityReadingType, "%", "Humidity", "HumidityPercent",
+			e.HumidityPercent.DataSourceURI, e.HumidityPercent.Reading),
+		r.excerpt(PowerReadingType, "W", "Power", "PowerWatts",
+			e.PowerWatts.DataSourceURI, e.PowerWatts.Reading),
+		r.excerpt(EnergykWhReadingType, "kW.h", "Energy", "EnergykWh",
+			e.EnergykWh.DataSourceURI, e.EnergykWh.Reading),
+		r.excerpt(VoltageReadingType, "V", "Voltage", "Voltage",
+			e.Voltage.DataSourceURI, e.Voltage.Reading),
+		r.excerpt(CurrentReadingType, "A", "Current", "CurrentAmps",
+			e.CurrentAmps.DataSourceURI, e.CurrentAmps.Reading),
+	}
+	for i := range e.FanSpeedsPercent {
+		fan := &e.FanSpeedsPercent[i]
+		fanSource := readingSource{name: fan.DeviceName, uri: e.ODataID, physicalContext: fan.PhysicalContext}
+		readings = append(readings, fanSource.excerpt(PercentReadingType, "%", "Speed",
+			fmt.Sprintf("FanSpeedsPercent/%d", i), fan.DataSourceURI, fan.Reading))
+	}
+	return compactReadings(readings)
+}
+
+// readingSource is a resource with readings in sensor excerpts.
+type readingSource struct {
+	name            string
+	uri             string
+	physicalContext PhysicalContext
+	status          Status
+}
+
+// excerpt creates a reading from a property of the resource, or returns nil
+// if the service did not report it. The source is the sensor the reading was
+// excerpted from, if the service provided it.
+func (r *readingSource) excerpt(kind ReadingType, units, label, pointer, dataSourceURI string, value *float64) *SensorReading {
+	if value == nil {
+		return nil
+	}
+	source := dataSourceURI
+	if source == "" {
+		source = r.uri + "#/" + pointer
+	}
+	return &SensorReading{
+		Kind:            kind,
+		Name:            strings.TrimSpace(r.name + " " + label),
+		Value:           value,
+		Units:           units,
+		PhysicalContext: r.physicalContext,
+		Health:          r.status.Health,
+		State:           r.status.State,
+		SourceURI:       source,
+	}
+}
+
+// compactReadings removes the readings that were not reported.
+func compactReadings(readings []*SensorReading) []*SensorReading {
+	return slices.DeleteFunc(readings, func(reading *SensorReading) bool { return reading == nil })
+}
+
+// memberURI gets the URI of a member of a resource array.
+func memberURI(odataID, resourceURI, property string, index int) string {
+	if odataID != "" {
+		return odataID
+	}
+	return fmt.Sprintf("%s#/%s/%d", resourceURI, property, index)
+}
+
+func toFloat64[N int | float32 | float64](value *N) *float64 {
+	if value == nil {
+		return nil
+	}
+	f := float64(*value)
+	return &f
+}
+
+// legacyThresholds converts the thresholds of the deprecated Power and
+// Thermal resources.
+func legacyThresholds[N int | float32 | float64](lowerNonCritical, lowerCritical, lowerFatal,
+	upperNonCritical, upperCritical, upperFatal *N) Thresholds {
+	return Thresholds{
+		LowerCaution:  Threshold{Reading: toFloat64(lowerNonCritical)},
+		LowerCritical: Threshold{Reading: toFloat64(lowerCritical)},
+		LowerFatal:    Threshold{Reading: toFloat64(lowerFatal)},
+		UpperCaution:  Threshold{Reading: toFloat64(upperNonCritical)},
+		UpperCritical: Threshold{Reading: toFloat64(upperCritical)},
+		UpperFatal:    Threshold{Reading: toFloat64(upperFatal)},
+	}
+}
@@ -0,0 +1,244 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeResourceClient serves fixed resources by URI.
+type fakeResourceClient struct {
+	*TestClient
+	mu        sync.Mutex
+	resources map[string]string
+	requests  []string
+}
+
+func (c *fakeResourceClient) Get(uri string) (*http.Response, error) {
+	return c.GetWithHeaders(uri, nil)
+}
+
+func (c *fakeResourceClient) GetWithHeaders(uri string, _ map[string]string) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, uri)
+	body, ok := c.resources[uri]
+	if !ok {
+		return nil, ConstructError(http.StatusNotFound, nil)
+	}
+	return getCall(body), nil
+}
+
+func readingsChassis(t *testing.T, c Client, links string) *Chassis {
+	var result Chassis
+	if err := json.Unmarshal([]byte(`{"@odata.id": "/redfish/v1/Chassis/1", "Id": "1", `+links+`}`), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(c)
+	return &result
+}
+
+// TestChassisReadingsSensors tests the Sensors collection is preferred and
+// read with $expand.
+func TestChassisReadingsSensors(t *testing.T) {
+	client := &fakeResourceClient{TestClient: &TestClient{}, resources: map[string]string{
+		"/redfish/v1/Chassis/1/Sensors?$expand=.": `{"Members": [{
+			"@odata.id": "/redfish/v1/Chassis/1/Sensors/VRM1", "Id": "VRM1", "Name": "VRM1 Voltage",
+			"Reading": 1.2, "ReadingType": "Voltage", "ReadingUnits": "V", "PhysicalContext": "VoltageRegulator",
+			"Status": {"Health": "OK", "State": "Enabled"}
+		}, {
+			"@odata.id": "/redfish/v1/Chassis/1/Sensors/CPU1Temp", "Id": "CPU1Temp", "Name": "CPU1 Temp",
+			"Reading": 45, "ReadingType": "Temperature", "ReadingUnits": "Cel", "PhysicalContext": "CPU",
+			"ReadingRangeMax": 120, "Thresholds": {"UpperCritical": {"Reading": 95, "Activation": "Increasing"}},
+			"Status": {"Health": "OK", "State": "Enabled"}
+		}]}`,
+	}}
+	chassis := readingsChassis(t, client, `"Sensors": {"@odata.id": "/redfish/v1/Chassis/1/Sensors"},
+		"Thermal": {"@odata.id": "/redfish/v1/Chassis/1/Thermal"}`)
+
+	readings, err := chassis.Readings(WithCollectionQueryOpts(WithExpand(ExpandOptionPeriod)))
+	if err != nil {
+		t.Fatalf("Error getting readings: %s", err)
+	}
+	AssertEqual(t, 2, len(readings))
+	AssertEqual(t, []string{"/redfish/v1/Chassis/1/Sensors?$expand=."}, client.requests)
+
+	reading := readings[0]
+	AssertEqual(t, "/redfish/v1/Chassis/1/Sensors/CPU1Temp", reading.SourceURI)
+	AssertEqual(t, TemperatureReadingType, reading.Kind)
+	AssertEqual(t, 45.0, *reading.Value)
+	AssertEqual(t, "Cel", reading.Units)
+	AssertEqual(t, 95.0, *reading.Thresholds.UpperCritical.Reading)
+	AssertEqual(t, 120.0, *reading.ReadingRangeMax)
+	AssertEqual(t, CPUPhysicalContext, reading.PhysicalContext)
+	AssertEqual(t, OKHealth, reading.Health)
+	AssertEqual(t, VoltageReadingType, readings[1].Kind)
+}
+
+// TestChassisReadingsLegacy tests readings are taken from the deprecated
+// Power and Thermal resources.
+func TestChassisReadingsLegacy(t *testing.T) {
+	client := &fakeResourceClient{TestClient: &TestClient{}, resources: map[string]string{
+		"/redfish/v1/Chassis/1/Sensors": `{"Members": []}`,
+		"/redfish/v1/Chassis/1/Power": `{"@odata.id": "/redfish/v1/Chassis/1/Power",
+			"PowerControl": [{"MemberId": "0", "Name": "System Power", "PowerConsumedWatts": 344}],
+			"Voltages": [{"@odata.id": "/redfish/v1/Chassis/1/Power#/Voltages/0", "Name": "VRM1",
+				"ReadingVolts": 12.5, "UpperThresholdCritical": 13.5, "Status": {"Health": "Warning"}}],
+			"PowerSupplies": [{"Name": "PSU1", "PowerInputWatts": 180, "LineInputVoltage": 230}]}`,
+		"/redfish/v1/Chassis/1/Thermal": `{"@odata.id": "/redfish/v1/Chassis/1/Thermal",
+			"Temperatures": [{"Name": "Inlet", "ReadingCelsius": 24, "UpperThresholdNonCritical": 40,
+				"LowerThresholdCritical": 5, "PhysicalContext": "Intake"}],
+			"Fans": [{"FanName": "Fan1", "Reading": 60, "ReadingUnits": "Percent", "LowerThresholdFatal": 5},
+				{"Name": "Fan2", "Reading": 4200, "ReadingUnits": "RPM"}]}`,
+	}}
+	chassis := readingsChassis(t, client, `"Sensors": {"@odata.id": "/redfish/v1/Chassis/1/Sensors"},
+		"Power": {"@odata.id": "/redfish/v1/Chassis/1/Power"},
+		"Thermal": {"@odata.id": "/redfish/v1/Chassis/1/Thermal"}`)
+
+	readings, err := chassis.Readings()
+	if err != nil {
+		t.Fatalf("Error getting readings: %s", err)
+	}
+
+	var sources []string
+	for _, reading := range readings {
+		sources = append(sources, reading.SourceURI)
+	}
+	AssertEqual(t, []string{
+		"/redfish/v1/Chassis/1/Power#/PowerControl/0",
+		"/redfish/v1/Chassis/1/Power#/Voltages/0",
+		"/redfish/v1/Chassis/1/Power#/PowerSupplies/0#/PowerInputWatts",
+		"/redfish/v1/Chassis/1/Power#/PowerSupplies/0#/LineInputVoltage",
+		"/redfish/v1/Chassis/1/Thermal#/Temperatures/0",
+		"/redfish/v1/Chassis/1/Thermal#/Fans/0",
+		"/redfish/v1/Chassis/1/Thermal#/Fans/1",
+	}, sources)
+
+	AssertEqual(t, 344.0, *readings[0].Value)
+	AssertEqual(t, 12.5, *readings[1].Value)
+	AssertEqual(t, 13.5, *readings[1].Thresholds.UpperCritical.Reading)
+	AssertEqual(t, WarningHealth, readings[1].Health)
+	AssertEqual(t, "PSU1 Input Power", readings[2].Name)
+	AssertEqual(t, PowerSupplyPhysicalContext, readings[2].PhysicalContext)
+
+	inlet := readings[4]
+	AssertEqual(t, TemperatureReadingType, inlet.Kind)
+	AssertEqual(t, 40.0, *inlet.Thresholds.UpperCaution.Reading)
+	AssertEqual(t, 5.0, *inlet.Thresholds.LowerCritical.Reading)
+	AssertEqual(t, IntakePhysicalContext, inlet.PhysicalContext)
+
+	AssertEqual(t, "Fan1", readings[5].Name)
+	AssertEqual(t, PercentReadingType, readings[5].Kind)
+	AssertEqual(t, "%", readings[5].Units)
+	AssertEqual(t, 5.0, *readings[5].Thresholds.LowerFatal.Reading)
+	AssertEqual(t, RotationalReadingType, readings[6].Kind)
+	AssertEqual(t, "RPM", readings[6].Units)
+}
+
+// TestChassisReadingsSubsystems tests readings are taken from the power
+// supplies, fans and environment metrics, and the deprecated resources are
+// not read.
+func TestChassisReadingsSubsystems(t *testing.T) {
+	client := &fakeResourceClient{TestClient: &TestClient{}, resources: map[string]string{
+		"/redfish/v1/Chassis/1/PowerSubsystem": `{"@odata.id": "/redfish/v1/Chassis/1/PowerSubsystem",
+			"PowerSupplies": {"@odata.id": "/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies"}}`,
+		"/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies": `{"Members": [
+			{"@odata.id": "/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/1"}]}`,
+		"/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/1": `{"@odata.id": "/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/1",
+			"Id": "1", "Name": "PSU1", "Status": {"Health": "Critical"},
+			"Metrics": {"@odata.id": "/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/1/Metrics"}}`,
+		"/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/1/Metrics": `{
+			"@odata.id": "/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/1/Metrics", "Id": "Metrics",
+			"InputPowerWatts": {"Reading": 210, "DataSourceUri": "/redfish/v1/Chassis/1/Sensors/PS1InputPower"},
+			"InputVoltage": {"Reading": 229.5}}`,
+		"/redfish/v1/Chassis/1/ThermalSubsystem": `{"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem",
+			"Fans": {"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/Fans"},
+			"ThermalMetrics": {"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/ThermalMetrics"}}`,
+		"/redfish/v1/Chassis/1/ThermalSubsystem/Fans": `{"Members": [
+			{"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1"}]}`,
+		"/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1": `{"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1",
+			"Id": "1", "Name": "Fan1", "PhysicalContext": "Fan", "SpeedPercent": {"Reading": 45, "SpeedRPM": 5400}}`,
+		"/redfish/v1/Chassis/1/ThermalSubsystem/ThermalMetrics": `{
+			"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/ThermalMetrics", "Id": "ThermalMetrics",
+			"TemperatureReadingsCelsius": [{"DeviceName": "Inlet", "PhysicalContext": "Intake", "Reading": 23,
+				"DataSourceUri": "/redfish/v1/Chassis/1/Sensors/Inlet"}, {"DeviceName": "Exhaust"}]}`,
+		"/redfish/v1/Chassis/1/EnvironmentMetrics": `{"@odata.id": "/redfish/v1/Chassis/1/EnvironmentMetrics",
+			"Id": "EnvironmentMetrics", "Name": "Chassis", "TemperatureCelsius": {"Reading": 31},
+			"FanSpeedsPercent": [{"DeviceName": "Fan2", "Reading": 50}]}`,
+	}}
+	chassis := readingsChassis(t, client, `"PowerSubsystem": {"@odata.id": "/redfish/v1/Chassis/1/PowerSubsystem"},
+		"ThermalSubsystem": {"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem"},
+		"EnvironmentMetrics": {"@odata.id": "/redfish/v1/Chassis/1/EnvironmentMetrics"},
+		"Power": {"@odata.id": "/redfish/v1/Chassis/1/Power"},
+		"Thermal": {"@odata.id": "/redfish/v1/Chassis/1/Thermal"}`)
+
+	readings, err := chassis.Readings()
+	if err != nil {
+		t.Fatalf("Error getting readings: %s", err)
+	}
+	if slices.Contains(client.requests, "/redfish/v1/Chassis/1/Power") ||
+		slices.Contains(client.requests, "/redfish/v1/Chassis/1/Thermal") {
+		t.Error("Expected the deprecated Power and Thermal resources not to be read")
+	}
+
+	AssertEqual(t, 7, len(readings))
+	AssertEqual(t, "/redfish/v1/Chassis/1/Sensors/PS1InputPower", readings[0].SourceURI)
+	AssertEqual(t, "PSU1 Input Power", readings[0].Name)
+	AssertEqual(t, CriticalHealth, readings[0].Health)
+	AssertEqual(t, "/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/1/Metrics#/InputVoltage", readings[1].SourceURI)
+	AssertEqual(t, VoltageReadingType, readings[1].Kind)
+
+	AssertEqual(t, 45.0, *readings[2].Value)
+	AssertEqual(t, "%", readings[2].Units)
+	AssertEqual(t, 5400.0, *readings[3].Value)
+	AssertEqual(t, "/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1#/SpeedPercent/SpeedRPM", readings[3].SourceURI)
+
+	AssertEqual(t, "Inlet Temperature", readings[4].Name)
+	AssertEqual(t, TemperatureReadingType, readings[4].Kind)
+	AssertEqual(t, 23.0, *readings[4].Value)
+	AssertEqual(t, IntakePhysicalContext, readings[4].PhysicalContext)
+	AssertEqual(t, "/redfish/v1/Chassis/1/Sensors/Inlet", readings[4].SourceURI)
+
+	AssertEqual(t, "Chassis Temperature", readings[5].Name)
+	AssertEqual(t, "Fan2 Speed", readings[6].Name)
+	AssertEqual(t, "/redfish/v1/Chassis/1/EnvironmentMetrics#/FanSpeedsPercent/0", readings[6].SourceURI)
+}
+
+// TestChassisReadingsLegacyFallback tests the deprecated Thermal resource is
+// read for the temperatures a ThermalSubsystem with only fans doesn't report,
+// and that resources are read with the query options.
+func TestChassisReadingsLegacyFallback(t *testing.T) {
+	client := &fakeResourceClient{TestClient: &TestClient{}, resources: map[string]string{
+		"/redfish/v1/Chassis/1/ThermalSubsystem?$expand=.": `{"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem",
+			"Fans": {"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/Fans"}}`,
+		"/redfish/v1/Chassis/1/ThermalSubsystem/Fans": `{"Members": [
+			{"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1"}]}`,
+		"/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1": `{"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1",
+			"Id": "1", "Name": "Fan1", "SpeedPercent": {"SpeedRPM": 5400}}`,
+		"/redfish/v1/Chassis/1/Thermal?$expand=.": `{"@odata.id": "/redfish/v1/Chassis/1/Thermal",
+			"Temperatures": [{"Name": "Inlet", "ReadingCelsius": 24}],
+			"Fans": [{"Name": "Fan1", "Reading": 60, "ReadingUnits": "Percent"}]}`,
+	}}
+	chassis := readingsChassis(t, client, `"ThermalSubsystem": {"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem"},
+		"Thermal": {"@odata.id": "/redfish/v1/Chassis/1/Thermal"}`)
+
+	readings, err := chassis.Readings(WithResourceQueryOpts(WithExpand(ExpandOptionPeriod)))
+	if err != nil {
+		t.Fatalf("Error getting readings: %s", err)
+	}
+
+	var sources []string
+	for _, reading := range readings {
+		sources = append(sources, reading.SourceURI)
+	}
+	AssertEqual(t, []string{
+		"/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1#/SpeedPercent/SpeedRPM",
+		"/redfish/v1/Chassis/1/Thermal#/Temperatures/0",
+	}, sources)
+}
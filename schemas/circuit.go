@@ -534,9 +534,9 @@ func (c *Circuit) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *Circuit) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *Circuit) writableFields() []string {
+	return []string{
 		"ConfigurationLocked",
 		"CriticalCircuit",
 		"ElectricalConsumerNames",
@@ -552,8 +552,11 @@ func (c *Circuit) Update() error {
 		"PowerRestorePolicy",
 		"UserLabel",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *Circuit) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetCircuit will get a Circuit instance from the service.
@@ -101,9 +101,9 @@ func (c *ClassOfService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *ClassOfService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *ClassOfService) writableFields() []string {
+	return []string{
 		"ClassOfServiceVersion",
 		"DataProtectionLinesOfService",
 		"DataSecurityLinesOfService",
@@ -111,8 +111,11 @@ func (c *ClassOfService) Update() error {
 		"IOConnectivityLinesOfService",
 		"IOPerformanceLinesOfService",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *ClassOfService) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetClassOfService will get a ClassOfService instance from the service.
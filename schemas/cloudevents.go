@@ -0,0 +1,539 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// CloudEventsSpecVersion is the version of the CloudEvents specification
+	// implemented.
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType is the content type of structured mode
+	// CloudEvents.
+	CloudEventsContentType = "application/cloudevents+json"
+
+	// defaultCloudEventTypePrefix is the prefix of the types of the
+	// CloudEvents converted from Redfish.
+	defaultCloudEventTypePrefix = "org.dmtf.redfish"
+	// cloudEventContextExtension is the extension holding the subscription's
+	// Context.
+	cloudEventContextExtension = "redfishcontext"
+	// cloudEventMetricReportType is the type suffix of metric reports.
+	cloudEventMetricReportType = "MetricReport"
+	// cloudEventHeaderPrefix is the prefix of binary mode attribute headers.
+	cloudEventHeaderPrefix = "Ce-"
+)
+
+// CloudEventMode is how a CloudEvent is carried in an HTTP message.
+type CloudEventMode string
+
+const (
+	// StructuredCloudEventMode sends the attributes and data together as a
+	// JSON document.
+	StructuredCloudEventMode CloudEventMode = "structured"
+	// BinaryCloudEventMode sends the attributes as headers and the data as the
+	// body.
+	BinaryCloudEventMode CloudEventMode = "binary"
+)
+
+// CloudEvent is an event in the CloudEvents format.
+type CloudEvent struct {
+	// ID identifies the event. It is unique for each source.
+	ID string
+	// Source identifies where the event happened.
+	Source string
+	// SpecVersion is the CloudEvents specification version.
+	SpecVersion string
+	// Type is the type of event.
+	Type string
+	// Subject is what the event is about within the source.
+	Subject string
+	// Time is when the event happened, in RFC 3339 format.
+	Time string
+	// DataContentType is the content type of Data.
+	DataContentType string
+	// DataSchema is the URI of the schema of Data.
+	DataSchema string
+	// Data is the event payload.
+	Data []byte
+	// Extensions are the extension attributes by name.
+	Extensions map[string]string
+}
+
+// cloudEventAttributes are the names of the context attributes, other than
+// extensions.
+var cloudEventAttributes = []string{"id", "source", "specversion", "type", "subject", "time", "datacontenttype", "dataschema"}
+
+// attributes returns the attributes and extensions by name, without empty
+// values.
+func (ce *CloudEvent) attributes() map[string]string {
+	attributes := make(map[string]string, len(cloudEventAttributes)+len(ce.Extensions))
+	for name, value := range ce.Extensions {
+		attributes[name] = value
+	}
+	values := []string{ce.ID, ce.Source, ce.SpecVersion, ce.Type, ce.Subject, ce.Time, ce.DataContentType, ce.DataSchema}
+	for i, name := range cloudEventAttributes {
+		if values[i] != "" {
+			attributes[name] = values[i]
+		}
+	}
+	return attributes
+}
+
+// setAttribute sets the attribute, or extension, with the name.
+func (ce *CloudEvent) setAttribute(name, value string) {
+	switch name {
+	case "id":
+		ce.ID = value
+	case "source":
+		ce.Source = value
+	case "specversion":
+		ce.SpecVersion = value
+	case "type":
+		ce.Type = value
+	case "subject":
+		ce.Subject = value
+	case "time":
+		ce.Time = value
+	case "datacontenttype":
+		ce.DataContentType = value
+	case "dataschema":
+		ce.DataSchema = value
+	default:
+		if ce.Extensions == nil {
+			ce.Extensions = make(map[string]string)
+		}
+		ce.Extensions[name] = value
+	}
+}
+
+// validate checks the required attributes are present.
+func (ce *CloudEvent) validate() error {
+	if ce.SpecVersion != CloudEventsSpecVersion {
+		return fmt.Errorf("unsupported CloudEvents specversion %q", ce.SpecVersion)
+	}
+	if ce.ID == "" || ce.Source == "" || ce.Type == "" {
+		return errors.New("CloudEvent is missing a required id, source or type")
+	}
+	return nil
+}
+
+// hasJSONData returns whether the data is JSON.
+func (ce *CloudEvent) hasJSONData() bool {
+	if ce.DataContentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(ce.DataContentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// MarshalJSON encodes the event in the structured JSON format.
+func (ce *CloudEvent) MarshalJSON() ([]byte, error) {
+	event := make(map[string]any)
+	for name, value := range ce.attributes() {
+		event[name] = value
+	}
+	if len(ce.Data) > 0 {
+		if ce.hasJSONData() && json.Valid(ce.Data) {
+			event["data"] = json.RawMessage(ce.Data)
+		} else {
+			event["data_base64"] = base64.StdEncoding.EncodeToString(ce.Data)
+		}
+	}
+	return json.Marshal(event)
+}
+
+// UnmarshalJSON decodes an event in the structured JSON format.
+func (ce *CloudEvent) UnmarshalJSON(b []byte) error {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal(b, &event); err != nil {
+		return err
+	}
+
+	*ce = CloudEvent{}
+	for name, raw := range event {
+		switch name {
+		case "data":
+			if ce.hasJSONDataRaw(event) {
+				ce.Data = raw
+				continue
+			}
+			// Non-JSON data is carried as a JSON string
+			var data string
+			if err := json.Unmarshal(raw, &data); err != nil {
+				return fmt.Errorf("invalid CloudEvent data: %w", err)
+			}
+			ce.Data = []byte(data)
+		case "data_base64":
+			var encoded string
+			if err := json.Unmarshal(raw, &encoded); err != nil {
+				return fmt.Errorf("invalid CloudEvent data_base64: %w", err)
+			}
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return fmt.Errorf("invalid CloudEvent data_base64: %w", err)
+			}
+			ce.Data = data
+		default:
+			var value any
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+			if s, ok := value.(string); ok {
+				ce.setAttribute(name, s)
+			} else if value != nil {
+				ce.setAttribute(name, string(raw))
+			}
+		}
+	}
+
+	return ce.validate()
+}
+
+// hasJSONDataRaw returns whether the data of the undecoded event is JSON.
+func (ce *CloudEvent) hasJSONDataRaw(event map[string]json.RawMessage) bool {
+	var contentType string
+	_ = json.Unmarshal(event["datacontenttype"], &contentType)
+	return (&CloudEvent{DataContentType: contentType}).hasJSONData()
+}
+
+// NewHTTPRequest creates a POST request sending the event to the target URL in
+// the given mode.
+func (ce *CloudEvent) NewHTTPRequest(ctx context.Context, target string, mode CloudEventMode) (*http.Request, error) {
+	if err := ce.validate(); err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case StructuredCloudEventMode, "":
+		body, err := ce.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", CloudEventsContentType)
+		return req, nil
+	case BinaryCloudEventMode:
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(ce.Data))
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range ce.attributes() {
+			if name == "datacontenttype" {
+				req.Header.Set("Content-Type", value)
+				continue
+			}
+			req.Header.Set(cloudEventHeaderPrefix+name, encodeCloudEventHeader(value))
+		}
+		return req, nil
+	}
+
+	return nil, fmt.Errorf("unknown CloudEvent mode %q", mode)
+}
+
+// CloudEventFromHTTPRequest reads a CloudEvent sent in either structured or
+// binary mode.
+func CloudEventFromHTTPRequest(req *http.Request) (*CloudEvent, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == CloudEventsContentType {
+		ce := &CloudEvent{}
+		if err := ce.UnmarshalJSON(body); err != nil {
+			return nil, err
+		}
+		return ce, nil
+	}
+
+	ce := &CloudEvent{DataContentType: contentType, Data: body}
+	for name, values := range req.Header {
+		if len(name) <= len(cloudEventHeaderPrefix) || !strings.EqualFold(name[:len(cloudEventHeaderPrefix)], cloudEventHeaderPrefix) {
+			continue
+		}
+		value, err := url.PathUnescape(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CloudEvent header %s: %w", name, err)
+		}
+		ce.setAttribute(strings.ToLower(name[len(cloudEventHeaderPrefix):]), value)
+	}
+	if len(body) == 0 {
+		ce.Data = nil
+	}
+
+	if err := ce.validate(); err != nil {
+		return nil, err
+	}
+	return ce, nil
+}
+
+// encodeCloudEventHeader percent-encodes the characters binary mode header
+// values can't contain.
+func encodeCloudEventHeader(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c <= ' ' || c > '~' || c == '"' || c == '%' {
+			fmt.Fprintf(&sb, "%%%02X", c)
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+// CloudEventConverter converts Redfish events and metric reports to and from
+// CloudEvents.
+type CloudEventConverter struct {
+	// Endpoint is the base URL of the service, such as
+	// "https://bmc1.example.com". It is combined with the URI of the resource
+	// the event is about to give the event's source.
+	Endpoint string
+	// TypePrefix is the prefix of the event types. Defaults to
+	// "org.dmtf.redfish".
+	TypePrefix string
+}
+
+func (c *CloudEventConverter) typePrefix() string {
+	if c.TypePrefix == "" {
+		return defaultCloudEventTypePrefix
+	}
+	return c.TypePrefix
+}
+
+// source returns the source of events about the resource at uri.
+func (c *CloudEventConverter) source(uri string) string {
+	endpoint := strings.TrimSuffix(c.Endpoint, "/")
+	if endpoint == "" {
+		return uri
+	}
+	if u, err := url.Parse(uri); err == nil && u.IsAbs() {
+		return uri
+	}
+	return endpoint + uri
+}
+
+// FromEvent converts each record of the event to a CloudEvent.
+func (c *CloudEventConverter) FromEvent(event *Event) ([]*CloudEvent, error) {
+	events := make([]*CloudEvent, 0, len(event.Events))
+	for i := range event.Events {
+		ce, err := c.FromEventRecord(&event.Events[i], event.Context)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ce)
+	}
+	return events, nil
+}
+
+// FromEventRecord converts an event record to a CloudEvent. The source is the
+// resource that is the origin of the condition, or the event service if that
+// is not known. The type is made from the registry prefix and message key of
+// the MessageId, so it doesn't change between registry versions. The context
+// of the subscription, if not empty, is kept in the "redfishcontext"
+// extension.
+func (c *CloudEventConverter) FromEventRecord(record *EventRecord, subscriptionContext string) (*CloudEvent, error) {
+	data, err := marshalEventRecord(record)
+	if err != nil {
+		return nil, err
+	}
+
+	origin := record.originOfCondition
+	if origin == "" {
+		origin = "/redfish/v1/EventService"
+	}
+
+	eventType := c.typePrefix() + ".Event"
+	if parts := strings.Split(record.MessageID, "."); len(parts) == MessageIDSectionLength {
+		eventType = c.typePrefix() + "." + parts[0] + "." + parts[len(parts)-1]
+	} else if record.MessageID != "" {
+		eventType = c.typePrefix() + "." + record.MessageID
+	}
+
+	ce := &CloudEvent{
+		ID:              record.EventID,
+		Source:          c.source(origin),
+		SpecVersion:     CloudEventsSpecVersion,
+		Type:            eventType,
+		Time:            cloudEventTime(record.EventTimestamp),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+	if ce.ID == "" {
+		ce.ID = contentID(data)
+	}
+	if subscriptionContext != "" {
+		ce.Extensions = map[string]string{cloudEventContextExtension: subscriptionContext}
+	}
+	return ce, nil
+}
+
+// FromMetricReport converts a metric report to a CloudEvent. The source is the
+// metric report and the subject is the Id of its definition.
+func (c *CloudEventConverter) FromMetricReport(report *MetricReport) (*CloudEvent, error) {
+	data, err := marshalMetricReport(report)
+	if err != nil {
+		return nil, err
+	}
+
+	source := report.ODataID
+	if source == "" {
+		source = "/redfish/v1/TelemetryService/MetricReports/" + report.ID
+	}
+
+	ce := &CloudEvent{
+		Source:          c.source(source),
+		SpecVersion:     CloudEventsSpecVersion,
+		Type:            c.typePrefix() + "." + cloudEventMetricReportType,
+		Time:            cloudEventTime(report.Timestamp),
+		DataContentType: "application/json",
+		Data:            data,
+	}
+	if report.metricReportDefinition != "" {
+		ce.Subject = report.metricReportDefinition[strings.LastIndex(report.metricReportDefinition, "/")+1:]
+	}
+	switch {
+	case report.ReportSequence != "":
+		ce.ID = report.ID + "-" + report.ReportSequence
+	case report.Timestamp != "":
+		ce.ID = report.ID + "-" + report.Timestamp
+	default:
+		ce.ID = contentID(data)
+	}
+	if report.Context != "" {
+		ce.Extensions = map[string]string{cloudEventContextExtension: report.Context}
+	}
+	return ce, nil
+}
+
+// IsMetricReport returns whether the event is a metric report.
+func (ce *CloudEvent) IsMetricReport() bool {
+	return strings.HasSuffix(ce.Type, "."+cloudEventMetricReportType)
+}
+
+// Event converts a CloudEvent made by a CloudEventConverter back to a Redfish
+// event with a single record.
+func (ce *CloudEvent) Event() (*Event, error) {
+	if ce.IsMetricReport() {
+		return nil, fmt.Errorf("CloudEvent %s is a metric report", ce.ID)
+	}
+
+	var record EventRecord
+	if err := json.Unmarshal(ce.Data, &record); err != nil {
+		return nil, fmt.Errorf("invalid event record in CloudEvent %s: %w", ce.ID, err)
+	}
+
+	event := &Event{
+		Context:     ce.Extensions[cloudEventContextExtension],
+		Events:      []EventRecord{record},
+		EventsCount: 1,
+	}
+	event.ID = ce.ID
+	event.Name = "Event"
+	return event, nil
+}
+
+// MetricReport converts a CloudEvent made by a CloudEventConverter back to a
+// metric report.
+func (ce *CloudEvent) MetricReport() (*MetricReport, error) {
+	if !ce.IsMetricReport() {
+		return nil, fmt.Errorf("CloudEvent %s is not a metric report", ce.ID)
+	}
+
+	var report MetricReport
+	if err := json.Unmarshal(ce.Data, &report); err != nil {
+		return nil, fmt.Errorf("invalid metric report in CloudEvent %s: %w", ce.ID, err)
+	}
+	if report.Context == "" {
+		report.Context = ce.Extensions[cloudEventContextExtension]
+	}
+	return &report, nil
+}
+
+// cloudEventTime returns the timestamp in RFC 3339 format, or nothing if it
+// can't be parsed.
+func cloudEventTime(timestamp string) string {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// contentID returns an ID derived from the data, so the same event always
+// gets the same ID.
+func contentID(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// linkObject returns the JSON form of a link, or nil if there is no link.
+func linkObject(uri string) map[string]string {
+	if uri == "" {
+		return nil
+	}
+	return map[string]string{"@odata.id": uri}
+}
+
+// marshalEventRecord encodes the record including its links.
+func marshalEventRecord(record *EventRecord) ([]byte, error) {
+	type temp EventRecord
+	return json.Marshal(struct {
+		temp
+		LogEntry          map[string]string `json:",omitempty"`
+		OriginOfCondition map[string]string `json:",omitempty"`
+	}{
+		temp:              temp(*record),
+		LogEntry:          linkObject(record.logEntry),
+		OriginOfCondition: linkObject(record.originOfCondition),
+	})
+}
+
+// marshalMetricReport encodes the report including its links.
+func marshalMetricReport(report *MetricReport) ([]byte, error) {
+	type value MetricValue
+	type valueLinks struct {
+		value
+		MetricDefinition map[string]string `json:",omitempty"`
+	}
+	values := make([]valueLinks, len(report.MetricValues))
+	for i := range report.MetricValues {
+		values[i] = valueLinks{
+			value:            value(report.MetricValues[i]),
+			MetricDefinition: linkObject(report.MetricValues[i].metricDefinition),
+		}
+	}
+
+	type temp MetricReport
+	return json.Marshal(struct {
+		temp
+		MetricValues           []valueLinks
+		MetricReportDefinition map[string]string `json:",omitempty"`
+	}{
+		temp:                   temp(*report),
+		MetricValues:           values,
+		MetricReportDefinition: linkObject(report.metricReportDefinition),
+	})
+}
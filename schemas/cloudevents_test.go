@@ -0,0 +1,189 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+var cloudEventBody = `{
+		"@odata.type": "#Event.v1_7_0.Event",
+		"Id": "5",
+		"Name": "Event Array",
+		"Context": "monitor:dc1",
+		"Events": [{
+			"EventId": "4593",
+			"EventTimestamp": "2024-05-01T10:15:00+02:00",
+			"MessageId": "ResourceEvent.1.2.ResourceStatusChangedCritical",
+			"MessageArgs": ["Fan 1", "Critical"],
+			"MessageSeverity": "Critical",
+			"OriginOfCondition": {"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1"},
+			"LogEntry": {"@odata.id": "/redfish/v1/Managers/1/LogServices/Log/Entries/4593"}
+		}, {
+			"MessageId": "Alert",
+			"Message": "Something happened"
+		}]
+	}`
+
+var cloudEventMetricReportBody = `{
+		"@odata.id": "/redfish/v1/TelemetryService/MetricReports/PowerMetrics",
+		"Id": "PowerMetrics",
+		"Name": "Power metrics",
+		"ReportSequence": "127",
+		"Timestamp": "2024-05-01T08:00:00Z",
+		"MetricReportDefinition": {"@odata.id": "/redfish/v1/TelemetryService/MetricReportDefinitions/PowerMetrics"},
+		"MetricValues": [{
+			"MetricId": "Power",
+			"MetricValue": "350",
+			"MetricProperty": "/redfish/v1/Chassis/1/Sensors/Power#/Reading",
+			"MetricDefinition": {"@odata.id": "/redfish/v1/TelemetryService/MetricDefinitions/Power"}
+		}]
+	}`
+
+func cloudEventEvent(t *testing.T) *Event {
+	var result Event
+	if err := json.Unmarshal([]byte(cloudEventBody), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	return &result
+}
+
+// TestCloudEventFromEvent tests event records are converted to CloudEvents.
+func TestCloudEventFromEvent(t *testing.T) {
+	converter := &CloudEventConverter{Endpoint: "https://bmc1.example.com/"}
+	events, err := converter.FromEvent(cloudEventEvent(t))
+	if err != nil {
+		t.Fatalf("Error converting event: %s", err)
+	}
+	AssertEqual(t, 2, len(events))
+
+	ce := events[0]
+	AssertEqual(t, "4593", ce.ID)
+	AssertEqual(t, "https://bmc1.example.com/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1", ce.Source)
+	AssertEqual(t, "org.dmtf.redfish.ResourceEvent.ResourceStatusChangedCritical", ce.Type)
+	AssertEqual(t, "2024-05-01T10:15:00+02:00", ce.Time)
+	AssertEqual(t, "monitor:dc1", ce.Extensions["redfishcontext"])
+
+	ce = events[1]
+	AssertEqual(t, "https://bmc1.example.com/redfish/v1/EventService", ce.Source)
+	AssertEqual(t, "org.dmtf.redfish.Alert", ce.Type)
+	AssertEqual(t, "", ce.Time)
+	AssertEqual(t, 32, len(ce.ID))
+}
+
+// TestCloudEventRoundTrip tests events survive conversion to a CloudEvent,
+// both HTTP modes and back.
+func TestCloudEventRoundTrip(t *testing.T) {
+	converter := &CloudEventConverter{Endpoint: "https://bmc1.example.com"}
+	events, err := converter.FromEvent(cloudEventEvent(t))
+	if err != nil {
+		t.Fatalf("Error converting event: %s", err)
+	}
+
+	for _, mode := range []CloudEventMode{StructuredCloudEventMode, BinaryCloudEventMode} {
+		req, err := events[0].NewHTTPRequest(context.Background(), "http://bus.example.com/", mode)
+		if err != nil {
+			t.Fatalf("Error creating %s request: %s", mode, err)
+		}
+		if mode == BinaryCloudEventMode {
+			AssertEqual(t, "application/json", req.Header.Get("Content-Type"))
+			AssertEqual(t, "monitor:dc1", req.Header.Get("ce-redfishcontext"))
+			AssertEqual(t, "2024-05-01T10:15:00+02:00", req.Header.Get("ce-time"))
+		} else {
+			AssertEqual(t, CloudEventsContentType, req.Header.Get("Content-Type"))
+		}
+
+		ce, err := CloudEventFromHTTPRequest(req)
+		if err != nil {
+			t.Fatalf("Error reading %s request: %s", mode, err)
+		}
+		AssertEqual(t, events[0].Source, ce.Source)
+		AssertEqual(t, events[0].Type, ce.Type)
+
+		event, err := ce.Event()
+		if err != nil {
+			t.Fatalf("Error converting CloudEvent: %s", err)
+		}
+		AssertEqual(t, "monitor:dc1", event.Context)
+		record := event.Events[0]
+		AssertEqual(t, "4593", record.EventID)
+		AssertEqual(t, Health("Critical"), record.MessageSeverity)
+		AssertEqual(t, "Critical", record.MessageArgs[1])
+		AssertEqual(t, "/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1", record.originOfCondition)
+		AssertEqual(t, "/redfish/v1/Managers/1/LogServices/Log/Entries/4593", record.logEntry)
+	}
+}
+
+// TestCloudEventMetricReport tests metric reports are converted to and from
+// CloudEvents.
+func TestCloudEventMetricReport(t *testing.T) {
+	var report MetricReport
+	if err := json.Unmarshal([]byte(cloudEventMetricReportBody), &report); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	converter := &CloudEventConverter{Endpoint: "https://bmc1.example.com", TypePrefix: "com.example.hw"}
+	ce, err := converter.FromMetricReport(&report)
+	if err != nil {
+		t.Fatalf("Error converting metric report: %s", err)
+	}
+	AssertEqual(t, "PowerMetrics-127", ce.ID)
+	AssertEqual(t, "https://bmc1.example.com/redfish/v1/TelemetryService/MetricReports/PowerMetrics", ce.Source)
+	AssertEqual(t, "com.example.hw.MetricReport", ce.Type)
+	AssertEqual(t, "PowerMetrics", ce.Subject)
+
+	b, err := json.Marshal(ce)
+	if err != nil {
+		t.Fatalf("Error encoding CloudEvent: %s", err)
+	}
+	var decoded CloudEvent
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Error decoding CloudEvent: %s", err)
+	}
+
+	if _, err := decoded.Event(); err == nil {
+		t.Error("Expected a metric report not to convert to an event")
+	}
+	result, err := decoded.MetricReport()
+	if err != nil {
+		t.Fatalf("Error converting CloudEvent: %s", err)
+	}
+	AssertEqual(t, "350", result.MetricValues[0].MetricValue)
+	AssertEqual(t, "/redfish/v1/TelemetryService/MetricDefinitions/Power", result.MetricValues[0].metricDefinition)
+	AssertEqual(t, "/redfish/v1/TelemetryService/MetricReportDefinitions/PowerMetrics", result.metricReportDefinition)
+}
+
+// TestCloudEventValidation tests invalid CloudEvents are rejected.
+func TestCloudEventValidation(t *testing.T) {
+	var ce CloudEvent
+	err := json.Unmarshal([]byte(`{"specversion": "1.0", "id": "1", "type": "x"}`), &ce)
+	RequireErrorContains(t, err, "missing a required")
+
+	err = json.Unmarshal([]byte(`{"specversion": "0.3", "id": "1", "source": "/", "type": "x"}`), &ce)
+	RequireErrorContains(t, err, "unsupported CloudEvents specversion")
+
+	err = json.Unmarshal([]byte(`{"specversion": "1.0", "id": "1", "source": "/", "type": "x",
+		"datacontenttype": "application/octet-stream", "data_base64": "AAE="}`), &ce)
+	if err != nil {
+		t.Fatalf("Error decoding CloudEvent: %s", err)
+	}
+	AssertEqual(t, 2, len(ce.Data))
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://bus.example.com/", http.NoBody)
+	req.Header.Set("ce-specversion", "1.0")
+	req.Header.Set("ce-id", "1")
+	req.Header.Set("ce-source", "/")
+	req.Header.Set("ce-type", "x")
+	req.Header.Set("ce-subject", "Fan%201%20%22left%22")
+	decoded, err := CloudEventFromHTTPRequest(req)
+	if err != nil {
+		t.Fatalf("Error reading request: %s", err)
+	}
+	AssertEqual(t, `Fan 1 "left"`, decoded.Subject)
+	AssertEqual(t, "Fan%201%20%22left%22", encodeCloudEventHeader(decoded.Subject))
+}
@@ -204,13 +204,16 @@ func (c *ComponentIntegrity) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *ComponentIntegrity) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *ComponentIntegrity) writableFields() []string {
+	return []string{
 		"ComponentIntegrityEnabled",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *ComponentIntegrity) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetComponentIntegrity will get a ComponentIntegrity instance from the service.
@@ -142,15 +142,18 @@ func (c *CompositionService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *CompositionService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *CompositionService) writableFields() []string {
+	return []string{
 		"AllowOverprovisioning",
 		"ReservationDuration",
 		"ServiceEnabled",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *CompositionService) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetCompositionService will get a CompositionService instance from the service.
@@ -1033,9 +1033,9 @@ func (c *ComputerSystem) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *ComputerSystem) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *ComputerSystem) writableFields() []string {
+	return []string{
 		"AssetTag",
 		"HostName",
 		"IndicatorLED",
@@ -1046,8 +1046,11 @@ func (c *ComputerSystem) Update() error {
 		"PowerOnDelaySeconds",
 		"PowerRestorePolicy",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *ComputerSystem) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetComputerSystem will get a ComputerSystem instance from the service.
@@ -1702,6 +1705,25 @@ func (bo *Boot) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// writableFields returns the properties of this object that can be updated.
+func (bo *Boot) writableFields() []string {
+	return []string{
+		"AliasBootOrder",
+		"AutomaticRetryAttempts",
+		"AutomaticRetryConfig",
+		"BootNext",
+		"BootOrder",
+		"BootOrderPropertySelection",
+		"BootSourceOverrideEnabled",
+		"BootSourceOverrideMode",
+		"BootSourceOverrideTarget",
+		"HttpBootUri",
+		"StopBootOnFault",
+		"TrustedModuleRequiredToBoot",
+		"UefiTargetBootSourceOverride",
+	}
+}
+
 // BootOptions gets the BootOptions collection.
 func (bo *Boot) BootOptions(client Client) ([]*BootOption, error) {
 	if bo.bootOptions == "" {
@@ -142,15 +142,18 @@ func (c *ConsistencyGroup) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *ConsistencyGroup) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *ConsistencyGroup) writableFields() []string {
+	return []string{
 		"ConsistencyMethod",
 		"ConsistencyType",
 		"Volumes",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *ConsistencyGroup) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetConsistencyGroup will get a ConsistencyGroup instance from the service.
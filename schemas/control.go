@@ -288,9 +288,9 @@ func (c *Control) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *Control) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *Control) writableFields() []string {
+	return []string{
 		"ControlDelaySeconds",
 		"ControlMode",
 		"DeadBand",
@@ -298,8 +298,11 @@ func (c *Control) Update() error {
 		"SettingMax",
 		"SettingMin",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *Control) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetControl will get a Control instance from the service.
@@ -291,15 +291,18 @@ func (c *CoolantConnector) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *CoolantConnector) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *CoolantConnector) writableFields() []string {
+	return []string{
 		"CoolingLoopName",
 		"CoolingManagerURI",
 		"LocationIndicatorActive",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *CoolantConnector) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetCoolantConnector will get a CoolantConnector instance from the service.
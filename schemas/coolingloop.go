@@ -147,9 +147,9 @@ func (c *CoolingLoop) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *CoolingLoop) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *CoolingLoop) writableFields() []string {
+	return []string{
 		"ConsumingEquipmentNames",
 		"CoolingLoopType",
 		"CoolingManagerURI",
@@ -157,8 +157,11 @@ func (c *CoolingLoop) Update() error {
 		"SupplyEquipmentNames",
 		"UserLabel",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *CoolingLoop) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetCoolingLoop will get a CoolingLoop instance from the service.
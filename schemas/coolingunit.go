@@ -227,14 +227,17 @@ func (c *CoolingUnit) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (c *CoolingUnit) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (c *CoolingUnit) writableFields() []string {
+	return []string{
 		"AssetTag",
 		"UserLabel",
 	}
+}
 
-	return c.UpdateFromRawData(c, c.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (c *CoolingUnit) Update() error {
+	return c.UpdateFromRawData(c, c.RawData, c.writableFields())
 }
 
 // GetCoolingUnit will get a CoolingUnit instance from the service.
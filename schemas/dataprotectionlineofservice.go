@@ -87,9 +87,9 @@ func (d *DataProtectionLineOfService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (d *DataProtectionLineOfService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (d *DataProtectionLineOfService) writableFields() []string {
+	return []string{
 		"IsIsolated",
 		"MinLifetime",
 		"RecoveryGeographicObjective",
@@ -98,8 +98,11 @@ func (d *DataProtectionLineOfService) Update() error {
 		"ReplicaClassOfService",
 		"ReplicaType",
 	}
+}
 
-	return d.UpdateFromRawData(d, d.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (d *DataProtectionLineOfService) Update() error {
+	return d.UpdateFromRawData(d, d.RawData, d.writableFields())
 }
 
 // GetDataProtectionLineOfService will get a DataProtectionLineOfService instance from the service.
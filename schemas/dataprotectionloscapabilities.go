@@ -138,9 +138,9 @@ func (d *DataProtectionLoSCapabilities) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (d *DataProtectionLoSCapabilities) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (d *DataProtectionLoSCapabilities) writableFields() []string {
+	return []string{
 		"SupportedLinesOfService",
 		"SupportedMinLifetimes",
 		"SupportedRecoveryGeographicObjectives",
@@ -149,8 +149,11 @@ func (d *DataProtectionLoSCapabilities) Update() error {
 		"SupportedReplicaTypes",
 		"SupportsIsolated",
 	}
+}
 
-	return d.UpdateFromRawData(d, d.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (d *DataProtectionLoSCapabilities) Update() error {
+	return d.UpdateFromRawData(d, d.RawData, d.writableFields())
 }
 
 // GetDataProtectionLoSCapabilities will get a DataProtectionLoSCapabilities instance from the service.
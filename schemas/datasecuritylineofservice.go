@@ -71,9 +71,9 @@ func (d *DataSecurityLineOfService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (d *DataSecurityLineOfService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (d *DataSecurityLineOfService) writableFields() []string {
+	return []string{
 		"AntivirusEngineProvider",
 		"AntivirusScanPolicies",
 		"ChannelEncryptionStrength",
@@ -83,8 +83,11 @@ func (d *DataSecurityLineOfService) Update() error {
 		"SecureChannelProtocol",
 		"UserAuthenticationType",
 	}
+}
 
-	return d.UpdateFromRawData(d, d.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (d *DataSecurityLineOfService) Update() error {
+	return d.UpdateFromRawData(d, d.RawData, d.writableFields())
 }
 
 // GetDataSecurityLineOfService will get a DataSecurityLineOfService instance from the service.
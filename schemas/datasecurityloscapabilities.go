@@ -186,9 +186,9 @@ func (d *DataSecurityLoSCapabilities) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (d *DataSecurityLoSCapabilities) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (d *DataSecurityLoSCapabilities) writableFields() []string {
+	return []string{
 		"SupportedAntivirusEngineProviders",
 		"SupportedAntivirusScanPolicies",
 		"SupportedChannelEncryptionStrengths",
@@ -199,8 +199,11 @@ func (d *DataSecurityLoSCapabilities) Update() error {
 		"SupportedSecureChannelProtocols",
 		"SupportedUserAuthenticationTypes",
 	}
+}
 
-	return d.UpdateFromRawData(d, d.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (d *DataSecurityLoSCapabilities) Update() error {
+	return d.UpdateFromRawData(d, d.RawData, d.writableFields())
 }
 
 // GetDataSecurityLoSCapabilities will get a DataSecurityLoSCapabilities instance from the service.
@@ -71,17 +71,20 @@ func (d *DataStorageLineOfService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (d *DataStorageLineOfService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (d *DataStorageLineOfService) writableFields() []string {
+	return []string{
 		"AccessCapabilities",
 		"IsSpaceEfficient",
 		"ProvisioningPolicy",
 		"RecoverableCapacitySourceCount",
 		"RecoveryTimeObjectives",
 	}
+}
 
-	return d.UpdateFromRawData(d, d.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (d *DataStorageLineOfService) Update() error {
+	return d.UpdateFromRawData(d, d.RawData, d.writableFields())
 }
 
 // GetDataStorageLineOfService will get a DataStorageLineOfService instance from the service.
@@ -89,9 +89,9 @@ func (d *DataStorageLoSCapabilities) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (d *DataStorageLoSCapabilities) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (d *DataStorageLoSCapabilities) writableFields() []string {
+	return []string{
 		"MaximumRecoverableCapacitySourceCount",
 		"SupportedAccessCapabilities",
 		"SupportedLinesOfService",
@@ -99,8 +99,11 @@ func (d *DataStorageLoSCapabilities) Update() error {
 		"SupportedRecoveryTimeObjectives",
 		"SupportsSpaceEfficiency",
 	}
+}
 
-	return d.UpdateFromRawData(d, d.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (d *DataStorageLoSCapabilities) Update() error {
+	return d.UpdateFromRawData(d, d.RawData, d.writableFields())
 }
 
 // GetDataStorageLoSCapabilities will get a DataStorageLoSCapabilities instance from the service.
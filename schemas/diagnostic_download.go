@@ -0,0 +1,250 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// downloadAccept is sent when downloading so services returning binary
+	// data don't reject the client's default of JSON.
+	downloadAccept = "application/octet-stream, */*"
+
+	defaultDownloadMaxResumes = 3
+)
+
+// ErrDownloadVerification is returned when downloaded data does not have the
+// expected size or hash.
+var ErrDownloadVerification = errors.New("downloaded data failed verification")
+
+// DownloadOptions control how data is downloaded.
+type DownloadOptions struct {
+	// ExpectedSize is the expected size of the data in bytes. If zero, the
+	// size is not verified.
+	ExpectedSize int64
+	// ExpectedSHA256 is the hex encoded SHA-256 hash the data is expected to
+	// have. If empty, the hash is not verified.
+	ExpectedSHA256 string
+	// MaxResumes is the number of times an interrupted download is resumed
+	// when the service supports range requests. Defaults to 3, a negative
+	// value disables resuming.
+	MaxResumes int
+	// Await controls how the diagnostic data collection task is waited for.
+	Await *AwaitOptions
+}
+
+// DownloadResult describes downloaded data.
+type DownloadResult struct {
+	// URI is the URI the data was downloaded from.
+	URI string
+	// Size is the number of bytes written.
+	Size int64
+	// SHA256 is the hex encoded SHA-256 hash of the data written.
+	SHA256 string
+	// ContentType is the content type reported by the service.
+	ContentType string
+	// Resumes is the number of times the download was resumed.
+	Resumes int
+}
+
+// Download streams the data at the URI to w. If the transfer is interrupted
+// and the service supports range requests, it is resumed from where it left
+// off. Services that ignore the range are handled by skipping the data already
+// written. The size and hash of the data are verified if expected values are
+// given in the options.
+func Download(ctx context.Context, c Client, uri string, w io.Writer, opts *DownloadOptions) (*DownloadResult, error) {
+	if c == nil {
+		return nil, errors.New("no client available to download with")
+	}
+	if uri == "" {
+		return nil, errors.New("a URI is required to download")
+	}
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	maxResumes := opts.MaxResumes
+	if maxResumes == 0 {
+		maxResumes = defaultDownloadMaxResumes
+	}
+
+	d := &download{
+		ctx:    ctx,
+		client: c,
+		uri:    uri,
+		hash:   sha256.New(),
+		result: &DownloadResult{URI: uri},
+	}
+	d.out = io.MultiWriter(w, d.hash)
+
+	for {
+		err := d.fetch()
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return d.result, ctx.Err()
+		}
+		var interrupted *interruptedError
+		if !errors.As(err, &interrupted) {
+			return d.result, err
+		}
+		if !d.resumable || d.result.Resumes >= maxResumes {
+			return d.result, fmt.Errorf("download of %s interrupted after %d bytes: %w",
+				uri, d.result.Size, interrupted.err)
+		}
+		d.result.Resumes++
+	}
+
+	d.result.SHA256 = hex.EncodeToString(d.hash.Sum(nil))
+	if opts.ExpectedSize > 0 && d.result.Size != opts.ExpectedSize {
+		return d.result, fmt.Errorf("%w: expected %d bytes, got %d",
+			ErrDownloadVerification, opts.ExpectedSize, d.result.Size)
+	}
+	if opts.ExpectedSHA256 != "" && !strings.EqualFold(opts.ExpectedSHA256, d.result.SHA256) {
+		return d.result, fmt.Errorf("%w: expected SHA-256 %s, got %s",
+			ErrDownloadVerification, opts.ExpectedSHA256, d.result.SHA256)
+	}
+
+	return d.result, nil
+}
+
+// interruptedError is a failure reading the data that may be resumed.
+type interruptedError struct {
+	err error
+}
+
+func (e *interruptedError) Error() string {
+	return e.err.Error()
+}
+
+// download holds the state of a download across resumes.
+type download struct {
+	ctx       context.Context
+	client    Client
+	uri       string
+	out       io.Writer
+	hash      hash.Hash
+	result    *DownloadResult
+	resumable bool
+}
+
+// fetch requests the data not yet written and writes it out.
+func (d *download) fetch() error {
+	offset := d.result.Size
+	headers := map[string]string{"Accept": downloadAccept}
+	if offset > 0 {
+		headers["Range"] = fmt.Sprintf("bytes=%d-", offset)
+	}
+
+	resp, err := d.client.GetWithHeaders(d.uri, headers)
+	defer DeferredCleanupHTTPResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if offset == 0 {
+		d.result.ContentType = resp.Header.Get("Content-Type")
+		d.resumable = strings.EqualFold(resp.Header.Get("Accept-Ranges"), "bytes")
+	}
+
+	body := &contextReader{ctx: d.ctx, r: resp.Body}
+	if offset > 0 {
+		if resp.StatusCode == http.StatusPartialContent {
+			contentRange := resp.Header.Get("Content-Range")
+			if !strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", offset)) {
+				return fmt.Errorf("unexpected content range %q resuming download at byte %d", contentRange, offset)
+			}
+		} else if _, err := io.CopyN(io.Discard, body, offset); err != nil {
+			// The range was ignored, skip the data already written
+			return &interruptedError{err: err}
+		}
+	}
+
+	n, err := io.Copy(d.out, body)
+	d.result.Size += n
+	if err != nil {
+		return &interruptedError{err: err}
+	}
+	return nil
+}
+
+// contextReader stops reading once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// DownloadAdditionalData streams the additional data of the log entry, such
+// as a crash dump, to w. If no expected size is given, the entry's
+// AdditionalDataSizeBytes is verified when reported.
+func (e *LogEntry) DownloadAdditionalData(ctx context.Context, w io.Writer, opts *DownloadOptions) (*DownloadResult, error) {
+	if e.AdditionalDataURI == "" {
+		return nil, fmt.Errorf("log entry %s has no additional data", e.ODataID)
+	}
+
+	options := DownloadOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	if options.ExpectedSize == 0 && e.AdditionalDataSizeBytes != nil {
+		options.ExpectedSize = int64(*e.AdditionalDataSizeBytes)
+	}
+
+	return Download(ctx, e.GetClient(), e.AdditionalDataURI, w, &options)
+}
+
+// DownloadDiagnosticData collects diagnostic data and streams it to w. Any
+// task started by the collection is waited for, then the resulting log entry
+// is retrieved and its additional data downloaded. The log entry is returned
+// along with the result of the download.
+func (l *LogService) DownloadDiagnosticData(ctx context.Context, params *LogServiceCollectDiagnosticDataParameters,
+	w io.Writer, opts *DownloadOptions) (*LogEntry, *DownloadResult, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+
+	location, taskInfo, err := l.CollectDiagnosticData(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if taskInfo != nil {
+		status, err := Await(ctx, l.GetClient(), taskInfo, opts.Await)
+		if err != nil {
+			return nil, nil, err
+		}
+		location = status.Location
+		if location == "" && len(status.CreatedResources) > 0 {
+			location = status.CreatedResources[len(status.CreatedResources)-1]
+		}
+	}
+	if location == "" {
+		return nil, nil, errors.New("service did not report the log entry of the collected diagnostic data")
+	}
+
+	entry, err := GetObject[LogEntry](l.GetClient(), location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := entry.DownloadAdditionalData(ctx, w, opts)
+	return entry, result, err
+}
@@ -0,0 +1,193 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// failingReader returns an error after reading a number of bytes.
+type failingReader struct {
+	r         io.Reader
+	remaining int
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if len(p) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= n
+	return n, err
+}
+
+// fakeDownloadClient serves binary data, interrupting each transfer after a
+// number of bytes.
+type fakeDownloadClient struct {
+	*TestClient
+	data        []byte
+	failAfter   int
+	acceptRange bool
+	requests    []map[string]string
+}
+
+func (c *fakeDownloadClient) GetWithHeaders(_ string, headers map[string]string) (*http.Response, error) {
+	c.requests = append(c.requests, headers)
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: make(http.Header)}
+	resp.Header.Set("Content-Type", "application/octet-stream")
+	data := c.data
+	if c.acceptRange {
+		resp.Header.Set("Accept-Ranges", "bytes")
+		if start, ok := strings.CutPrefix(headers["Range"], "bytes="); ok {
+			offset, _ := strconv.Atoi(strings.TrimSuffix(start, "-"))
+			resp.StatusCode = http.StatusPartialContent
+			resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(c.data)-1, len(c.data)))
+			data = data[offset:]
+		}
+	}
+
+	var body io.Reader = bytes.NewReader(data)
+	if c.failAfter > 0 {
+		body = &failingReader{r: body, remaining: c.failAfter}
+	}
+	resp.Body = io.NopCloser(body)
+	return resp, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// TestDownloadResume tests interrupted downloads are resumed with range
+// requests.
+func TestDownloadResume(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	client := &fakeDownloadClient{TestClient: &TestClient{}, data: data, failAfter: 40, acceptRange: true}
+
+	var out bytes.Buffer
+	result, err := Download(context.Background(), client, "/dump", &out, &DownloadOptions{
+		ExpectedSize:   int64(len(data)),
+		ExpectedSHA256: sha256Hex(data),
+	})
+	if err != nil {
+		t.Fatalf("Error downloading: %s", err)
+	}
+	AssertEqual(t, string(data), out.String())
+	AssertEqual(t, sha256Hex(data), result.SHA256)
+	AssertEqual(t, "application/octet-stream", result.ContentType)
+	AssertEqual(t, 2, result.Resumes)
+	AssertEqual(t, downloadAccept, client.requests[0]["Accept"])
+	AssertEqual(t, "bytes=80-", client.requests[2]["Range"])
+}
+
+// TestDownloadIgnoredRange tests data already written is skipped when the
+// service ignores the range.
+func TestDownloadIgnoredRange(t *testing.T) {
+	data := bytes.Repeat([]byte("abcdefghij"), 10)
+	client := &fakeDownloadClient{TestClient: &TestClient{}, data: data, failAfter: 60}
+
+	var out bytes.Buffer
+	d := &download{
+		ctx:    context.Background(),
+		client: client,
+		uri:    "/dump",
+		out:    &out,
+		hash:   sha256.New(),
+		result: &DownloadResult{},
+	}
+	if err := d.fetch(); err == nil {
+		t.Fatal("Expected the first transfer to be interrupted")
+	}
+	AssertEqual(t, int64(60), d.result.Size)
+
+	client.failAfter = 0
+	if err := d.fetch(); err != nil {
+		t.Fatalf("Error resuming: %s", err)
+	}
+	AssertEqual(t, string(data), out.String())
+}
+
+// TestDownloadVerification tests size and hash mismatches are reported.
+func TestDownloadVerification(t *testing.T) {
+	client := &fakeDownloadClient{TestClient: &TestClient{}, data: []byte("data")}
+
+	_, err := Download(context.Background(), client, "/dump", io.Discard, &DownloadOptions{ExpectedSize: 5})
+	if !errors.Is(err, ErrDownloadVerification) {
+		t.Errorf("Expected verification error, got: %v", err)
+	}
+
+	_, err = Download(context.Background(), client, "/dump", io.Discard, &DownloadOptions{ExpectedSHA256: sha256Hex([]byte("other"))})
+	if !errors.Is(err, ErrDownloadVerification) {
+		t.Errorf("Expected verification error, got: %v", err)
+	}
+
+	// Not resumable, so the interruption is returned
+	client.failAfter = 2
+	_, err = Download(context.Background(), client, "/dump", io.Discard, nil)
+	RequireErrorContains(t, err, "interrupted after 2 bytes")
+}
+
+// TestDownloadDiagnosticData tests the collection task is waited for before
+// the resulting log entry's data is downloaded.
+func TestDownloadDiagnosticData(t *testing.T) {
+	data := []byte("crash dump")
+
+	accepted := acceptedCall("", "")
+	accepted.Header.Set("Location", "/redfish/v1/TaskService/TaskMonitors/1")
+	done := getCall("")
+	done.Header.Set("Location", "/redfish/v1/Managers/1/LogServices/Dump/Entries/7")
+	entry := getCall(fmt.Sprintf(`{"@odata.id": "/redfish/v1/Managers/1/LogServices/Dump/Entries/7", "Id": "7",
+		"AdditionalDataURI": "/redfish/v1/Managers/1/LogServices/Dump/Entries/7/attachment",
+		"AdditionalDataSizeBytes": %d}`, len(data)))
+	dump := getCall(string(data))
+
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodPost: {accepted},
+			http.MethodGet:  {done, entry, dump},
+		},
+	}
+
+	var result LogService
+	body := `{"@odata.id": "/redfish/v1/Managers/1/LogServices/Dump", "Id": "Dump",
+		"Actions": {"#LogService.CollectDiagnosticData": {
+			"target": "/redfish/v1/Managers/1/LogServices/Dump/Actions/LogService.CollectDiagnosticData"}}}`
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(testClient)
+
+	var out bytes.Buffer
+	logEntry, download, err := result.DownloadDiagnosticData(context.Background(),
+		&LogServiceCollectDiagnosticDataParameters{DiagnosticDataType: ManagerLogDiagnosticDataTypes}, &out, nil)
+	if err != nil {
+		t.Fatalf("Error downloading diagnostic data: %s", err)
+	}
+
+	AssertEqual(t, "7", logEntry.ID)
+	AssertEqual(t, string(data), out.String())
+	AssertEqual(t, int64(len(data)), download.Size)
+
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, "/redfish/v1/TaskService/TaskMonitors/1", calls[1].URL)
+	AssertEqual(t, "/redfish/v1/Managers/1/LogServices/Dump/Entries/7/attachment", calls[3].URL)
+	AssertEqual(t, downloadAccept, calls[3].CustomHeaders["Accept"])
+}
@@ -518,9 +518,9 @@ func (d *Drive) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (d *Drive) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (d *Drive) writableFields() []string {
+	return []string{
 		"AssetTag",
 		"BlockSecurityIDEnabled",
 		"ConfigurationLock",
@@ -533,8 +533,11 @@ func (d *Drive) Update() error {
 		"TargetConfigurationLockLevel",
 		"WriteCacheEnabled",
 	}
+}
 
-	return d.UpdateFromRawData(d, d.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (d *Drive) Update() error {
+	return d.UpdateFromRawData(d, d.RawData, d.writableFields())
 }
 
 // GetDrive will get a Drive instance from the service.
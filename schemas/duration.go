@@ -0,0 +1,87 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redfishDurationPattern matches the ISO 8601 durations used by Redfish, such
+// as "P1DT2H30M" or "PT0.5S".
+var redfishDurationPattern = regexp.MustCompile(`^(-)?P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ParseRedfishDuration parses an ISO 8601 duration as used by Redfish
+// properties, such as "PT5M" for five minutes.
+func ParseRedfishDuration(value string) (time.Duration, error) {
+	match := redfishDurationPattern.FindStringSubmatch(value)
+	if match == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var duration time.Duration
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute}
+	for i, unit := range units {
+		if match[i+2] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(match[i+2], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		duration += time.Duration(n) * unit
+	}
+	if match[5] != "" {
+		seconds, err := time.ParseDuration(match[5] + "s")
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		duration += seconds
+	}
+
+	if match[1] != "" {
+		duration = -duration
+	}
+	return duration, nil
+}
+
+// FormatRedfishDuration formats a duration in the ISO 8601 form used by
+// Redfish properties.
+func FormatRedfishDuration(duration time.Duration) string {
+	var sb strings.Builder
+	if duration < 0 {
+		sb.WriteString("-")
+		duration = -duration
+	}
+	sb.WriteString("P")
+
+	if days := duration / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&sb, "%dD", days)
+		duration -= days * 24 * time.Hour
+	}
+	if duration == 0 {
+		if sb.Len() <= 2 {
+			sb.WriteString("T0S")
+		}
+		return sb.String()
+	}
+
+	sb.WriteString("T")
+	if hours := duration / time.Hour; hours > 0 {
+		fmt.Fprintf(&sb, "%dH", hours)
+		duration -= hours * time.Hour
+	}
+	if minutes := duration / time.Minute; minutes > 0 {
+		fmt.Fprintf(&sb, "%dM", minutes)
+		duration -= minutes * time.Minute
+	}
+	if duration > 0 {
+		sb.WriteString(strconv.FormatFloat(duration.Seconds(), 'f', -1, 64) + "S")
+	}
+	return sb.String()
+}
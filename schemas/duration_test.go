@@ -0,0 +1,39 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRedfishDuration tests parsing and formatting durations.
+func TestRedfishDuration(t *testing.T) {
+	tests := []struct {
+		value    string
+		duration time.Duration
+	}{
+		{"PT0S", 0},
+		{"PT5M", 5 * time.Minute},
+		{"PT0.5S", 500 * time.Millisecond},
+		{"P1D", 24 * time.Hour},
+		{"P2DT1H30M15S", 49*time.Hour + 30*time.Minute + 15*time.Second},
+		{"-PT10S", -10 * time.Second},
+	}
+	for _, test := range tests {
+		duration, err := ParseRedfishDuration(test.value)
+		if err != nil {
+			t.Fatalf("Error parsing %s: %s", test.value, err)
+		}
+		AssertEqual(t, test.duration, duration)
+		AssertEqual(t, test.value, FormatRedfishDuration(test.duration))
+	}
+
+	for _, value := range []string{"", "P", "PT", "5M", "PT5", "P1H"} {
+		if _, err := ParseRedfishDuration(value); err == nil {
+			t.Errorf("Expected %q to be invalid", value)
+		}
+	}
+}
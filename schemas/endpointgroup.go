@@ -113,17 +113,20 @@ func (e *EndpointGroup) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (e *EndpointGroup) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (e *EndpointGroup) writableFields() []string {
+	return []string{
 		"AccessState",
 		"Endpoints",
 		"GroupType",
 		"Preferred",
 		"TargetEndpointGroupIdentifier",
 	}
+}
 
-	return e.UpdateFromRawData(e, e.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (e *EndpointGroup) Update() error {
+	return e.UpdateFromRawData(e, e.RawData, e.writableFields())
 }
 
 // GetEndpointGroup will get a EndpointGroup instance from the service.
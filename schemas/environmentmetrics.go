@@ -155,13 +155,16 @@ func (e *EnvironmentMetrics) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (e *EnvironmentMetrics) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (e *EnvironmentMetrics) writableFields() []string {
+	return []string{
 		"PowerLimitWatts",
 	}
+}
 
-	return e.UpdateFromRawData(e, e.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (e *EnvironmentMetrics) Update() error {
+	return e.UpdateFromRawData(e, e.RawData, e.writableFields())
 }
 
 // GetEnvironmentMetrics will get a EnvironmentMetrics instance from the service.
@@ -346,9 +346,9 @@ func (e *EthernetInterface) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (e *EthernetInterface) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (e *EthernetInterface) writableFields() []string {
+	return []string{
 		"AutoNeg",
 		"DHCPv4",
 		"DHCPv6",
@@ -372,8 +372,11 @@ func (e *EthernetInterface) Update() error {
 		"TeamMode",
 		"VLAN",
 	}
+}
 
-	return e.UpdateFromRawData(e, e.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (e *EthernetInterface) Update() error {
+	return e.UpdateFromRawData(e, e.RawData, e.writableFields())
 }
 
 // GetEthernetInterface will get a EthernetInterface instance from the service.
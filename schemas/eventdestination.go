@@ -463,6 +463,7 @@ func (e *EventDestination) UnmarshalJSON(b []byte) error {
 		// Workaround for the OriginResources actually being an idref
 		var x struct {
 			temp
+			Actions            eActions
 			OriginResources    Links
 			Certificates       Link
 			ClientCertificates Link
@@ -475,6 +476,7 @@ func (e *EventDestination) UnmarshalJSON(b []byte) error {
 		}
 
 		tmp.temp = x.temp
+		tmp.Actions = x.Actions
 		tmp.Certificates = x.Certificates
 		tmp.ClientCertificates = x.ClientCertificates
 		tmp.OriginResources = x.OriginResources.ToStrings()
@@ -494,16 +496,19 @@ func (e *EventDestination) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (e *EventDestination) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (e *EventDestination) writableFields() []string {
+	return []string{
 		"BackupDestinations",
 		"Context",
 		"DeliveryRetryPolicy",
 		"VerifyCertificate",
 	}
+}
 
-	return e.UpdateFromRawData(e, e.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (e *EventDestination) Update() error {
+	return e.UpdateFromRawData(e, e.RawData, e.writableFields())
 }
 
 // GetEventDestination will get a EventDestination instance from the service.
@@ -641,6 +646,8 @@ type SNMPSettings struct {
 type subscriptionPayload struct {
 	Destination         string                   `json:"Destination,omitempty"`
 	EventTypes          []EventType              `json:"EventTypes,omitempty"`
+	EventFormatType     EventFormatType          `json:"EventFormatType,omitempty"`
+	OriginResources     []map[string]string      `json:"OriginResources,omitempty"`
 	RegistryPrefixes    []string                 `json:"RegistryPrefixes,omitempty"`
 	ResourceTypes       []string                 `json:"ResourceTypes,omitempty"`
 	DeliveryRetryPolicy DeliveryRetryPolicy      `json:"DeliveryRetryPolicy,omitempty"`
@@ -200,15 +200,18 @@ func (e *EventService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (e *EventService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (e *EventService) writableFields() []string {
+	return []string{
 		"DeliveryRetryAttempts",
 		"DeliveryRetryIntervalSeconds",
 		"ServiceEnabled",
 	}
+}
 
-	return e.UpdateFromRawData(e, e.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (e *EventService) Update() error {
+	return e.UpdateFromRawData(e, e.RawData, e.writableFields())
 }
 
 // GetEventService will get a EventService instance from the service.
@@ -117,17 +117,20 @@ func (e *ExternalAccountProvider) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (e *ExternalAccountProvider) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (e *ExternalAccountProvider) writableFields() []string {
+	return []string{
 		"Priority",
 		"Retries",
 		"ServiceAddresses",
 		"ServiceEnabled",
 		"TimeoutSeconds",
 	}
+}
 
-	return e.UpdateFromRawData(e, e.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (e *ExternalAccountProvider) Update() error {
+	return e.UpdateFromRawData(e, e.RawData, e.writableFields())
 }
 
 // GetExternalAccountProvider will get a ExternalAccountProvider instance from the service.
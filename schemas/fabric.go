@@ -105,13 +105,16 @@ func (f *Fabric) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (f *Fabric) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (f *Fabric) writableFields() []string {
+	return []string{
 		"UUID",
 	}
+}
 
-	return f.UpdateFromRawData(f, f.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (f *Fabric) Update() error {
+	return f.UpdateFromRawData(f, f.RawData, f.writableFields())
 }
 
 // GetFabric will get a Fabric instance from the service.
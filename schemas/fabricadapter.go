@@ -129,14 +129,17 @@ func (f *FabricAdapter) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (f *FabricAdapter) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (f *FabricAdapter) writableFields() []string {
+	return []string{
 		"FabricType",
 		"LocationIndicatorActive",
 	}
+}
 
-	return f.UpdateFromRawData(f, f.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (f *FabricAdapter) Update() error {
+	return f.UpdateFromRawData(f, f.RawData, f.writableFields())
 }
 
 // GetFabricAdapter will get a FabricAdapter instance from the service.
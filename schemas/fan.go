@@ -140,13 +140,16 @@ func (f *Fan) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (f *Fan) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (f *Fan) writableFields() []string {
+	return []string{
 		"LocationIndicatorActive",
 	}
+}
 
-	return f.UpdateFromRawData(f, f.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (f *Fan) Update() error {
+	return f.UpdateFromRawData(f, f.RawData, f.writableFields())
 }
 
 // GetFan will get a Fan instance from the service.
@@ -137,17 +137,20 @@ func (f *FileShare) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (f *FileShare) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (f *FileShare) writableFields() []string {
+	return []string{
 		"CASupported",
 		"FileShareQuotaType",
 		"FileShareTotalQuotaBytes",
 		"LowSpaceWarningThresholdPercents",
 		"ReplicationEnabled",
 	}
+}
 
-	return f.UpdateFromRawData(f, f.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (f *FileShare) Update() error {
+	return f.UpdateFromRawData(f, f.RawData, f.writableFields())
 }
 
 // GetFileShare will get a FileShare instance from the service.
@@ -237,9 +237,9 @@ func (f *FileSystem) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (f *FileSystem) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (f *FileSystem) writableFields() []string {
+	return []string{
 		"AccessCapabilities",
 		"CapacitySources",
 		"CasePreserved",
@@ -252,8 +252,11 @@ func (f *FileSystem) Update() error {
 		"RecoverableCapacitySourceCount",
 		"ReplicationEnabled",
 	}
+}
 
-	return f.UpdateFromRawData(f, f.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (f *FileSystem) Update() error {
+	return f.UpdateFromRawData(f, f.RawData, f.writableFields())
 }
 
 // GetFileSystem will get a FileSystem instance from the service.
@@ -117,16 +117,19 @@ func (f *Filter) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (f *Filter) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (f *Filter) writableFields() []string {
+	return []string{
 		"LocationIndicatorActive",
 		"ServiceHours",
 		"ServicedDate",
 		"UserLabel",
 	}
+}
 
-	return f.UpdateFromRawData(f, f.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (f *Filter) Update() error {
+	return f.UpdateFromRawData(f, f.RawData, f.writableFields())
 }
 
 // GetFilter will get a Filter instance from the service.
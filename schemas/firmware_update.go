@@ -0,0 +1,447 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FirmwareComponent is the desired version of a firmware component, as part of
+// a firmware baseline.
+type FirmwareComponent struct {
+	// SoftwareID is matched against the SoftwareId of the firmware inventory
+	// entries. Every entry with a matching SoftwareId is checked, such as the
+	// firmware of each of several identical devices.
+	SoftwareID string
+	// ID is matched against the Id of the firmware inventory entries if
+	// SoftwareID is not set.
+	ID string
+	// Version is the desired version of the component.
+	Version string
+	// ImageURI is the location the service retrieves the image from using
+	// SimpleUpdate.
+	ImageURI string
+	// TransferProtocol is the protocol used to retrieve ImageURI if its
+	// scheme does not determine it.
+	TransferProtocol TransferProtocolType
+	// Username is the username used to access ImageURI.
+	Username string
+	// Password is the password used to access ImageURI.
+	Password string
+	// ImagePath is the path of a local image file that is pushed to the
+	// service's MultipartHttpPushUri. Only used if ImageURI is not set.
+	ImagePath string
+	// ForceUpdate asks the service to bypass its update policies, such as to
+	// allow downgrades. Downgrades are skipped unless this is set.
+	ForceUpdate bool
+	// Optional marks a component that may not be present, such as the
+	// firmware of a device not fitted to every system. A missing optional
+	// component does not make the firmware non-compliant.
+	Optional bool
+}
+
+func (fc *FirmwareComponent) matches(inventory *SoftwareInventory) bool {
+	if fc.SoftwareID != "" {
+		return fc.SoftwareID == inventory.SoftwareID
+	}
+	return fc.ID != "" && fc.ID == inventory.ID
+}
+
+func (fc *FirmwareComponent) String() string {
+	if fc.SoftwareID != "" {
+		return fc.SoftwareID
+	}
+	return fc.ID
+}
+
+// FirmwareComplianceItem is the result of comparing a firmware inventory entry
+// against the baseline.
+type FirmwareComplianceItem struct {
+	// Component is the baseline component matching the inventory entry.
+	Component *FirmwareComponent
+	// Inventory is the firmware inventory entry.
+	Inventory *SoftwareInventory
+	// RelatedItems are the URIs of the devices the firmware applies to.
+	RelatedItems []string
+	// NeedsUpdate indicates the installed version differs from the baseline
+	// and the component can be updated.
+	NeedsUpdate bool
+	// Downgrade indicates the baseline version is older than the installed
+	// version. Only set if both versions can be compared.
+	Downgrade bool
+	// Reason explains why a component that differs from the baseline will not
+	// be updated.
+	Reason string
+}
+
+// FirmwareComplianceReport is the result of comparing the firmware inventory
+// against a baseline.
+type FirmwareComplianceReport struct {
+	// Items are the firmware inventory entries matching a baseline component.
+	Items []FirmwareComplianceItem
+	// Missing are the baseline components no inventory entry matched.
+	Missing []FirmwareComponent
+}
+
+// Compliant indicates whether all matched inventory entries are at their
+// baseline version and no component that isn't optional is missing.
+func (r *FirmwareComplianceReport) Compliant() bool {
+	for i := range r.Missing {
+		if !r.Missing[i].Optional {
+			return false
+		}
+	}
+	for i := range r.Items {
+		if r.Items[i].Inventory.Version != r.Items[i].Component.Version {
+			return false
+		}
+	}
+	return true
+}
+
+// PendingUpdates returns the items that need to be updated.
+func (r *FirmwareComplianceReport) PendingUpdates() []FirmwareComplianceItem {
+	var result []FirmwareComplianceItem
+	for i := range r.Items {
+		if r.Items[i].NeedsUpdate {
+			result = append(result, r.Items[i])
+		}
+	}
+	return result
+}
+
+// CheckFirmwareCompliance compares the firmware inventory against the
+// baseline and determines which components need to be updated.
+func (u *UpdateService) CheckFirmwareCompliance(baseline []FirmwareComponent) (*FirmwareComplianceReport, error) {
+	inventory, err := u.FirmwareInventory()
+	if err != nil {
+		return nil, err
+	}
+
+	return compareFirmware(baseline, inventory), nil
+}
+
+func compareFirmware(baseline []FirmwareComponent, inventory []*SoftwareInventory) *FirmwareComplianceReport {
+	report := &FirmwareComplianceReport{}
+	for i := range baseline {
+		component := &baseline[i]
+		found := false
+		for _, item := range inventory {
+			if !component.matches(item) {
+				continue
+			}
+			found = true
+
+			result := FirmwareComplianceItem{
+				Component:    component,
+				Inventory:    item,
+				RelatedItems: item.relatedItem,
+			}
+			if item.Version != component.Version {
+				if cmp, ok := compareVersions(component.Version, item.Version); ok && cmp < 0 {
+					result.Downgrade = true
+				}
+
+				switch {
+				case !item.Updateable:
+					result.Reason = "component is not updateable"
+				case item.WriteProtected:
+					result.Reason = "component is write protected"
+				case result.Downgrade && !component.ForceUpdate:
+					result.Reason = "baseline version is older than installed version"
+				default:
+					result.NeedsUpdate = true
+				}
+			}
+			report.Items = append(report.Items, result)
+		}
+
+		if !found {
+			report.Missing = append(report.Missing, *component)
+		}
+	}
+
+	return report
+}
+
+// compareVersions compares two dot separated numeric versions, ignoring any
+// leading 'v' and any pre-release or build suffix. The second return value is
+// false if the versions can't be compared.
+func compareVersions(a, b string) (int, bool) {
+	parse := func(v string) ([]int, bool) {
+		v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+		if i := strings.IndexAny(v, "-+"); i >= 0 {
+			v = v[:i]
+		}
+		var result []int
+		for _, part := range strings.Split(v, ".") {
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, false
+			}
+			result = append(result, n)
+		}
+		return result, true
+	}
+
+	av, aok := parse(a)
+	bv, bok := parse(b)
+	if !aok || !bok {
+		return 0, false
+	}
+
+	for i := 0; i < len(av) || i < len(bv); i++ {
+		var x, y int
+		if i < len(av) {
+			x = av[i]
+		}
+		if i < len(bv) {
+			y = bv[i]
+		}
+		if x != y {
+			if x < y {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// FirmwareUpdateOptions controls how a firmware baseline is applied.
+type FirmwareUpdateOptions struct {
+	// ApplyTime is sent as the '@Redfish.OperationApplyTime' of the update
+	// requests. If empty, the service default is used.
+	ApplyTime OperationApplyTime
+	// Stage stages the images on the targets and then activates them with
+	// the UpdateService Activate action.
+	Stage bool
+	// TargetRelatedItems targets the devices in the inventory entries'
+	// RelatedItem instead of the inventory entries themselves, for services
+	// that expect device targets.
+	TargetRelatedItems bool
+	// Reset is called once all updates have been applied if any of them need
+	// a reset to take effect, either because the inventory entry requires it
+	// or because ApplyTime is OnReset. If not set, no reset is performed and
+	// those updates are reported as pending.
+	Reset func(ctx context.Context, items []FirmwareComplianceItem) error
+	// AwaitOptions are used when waiting for update tasks to complete.
+	AwaitOptions *AwaitOptions
+}
+
+// FirmwareUpdateResult is the outcome of updating one baseline component.
+type FirmwareUpdateResult struct {
+	// Component is the baseline component that was applied.
+	Component *FirmwareComponent
+	// Targets are the URIs the update was applied to.
+	Targets []string
+	// Status is the final status of the update task, if there was one.
+	Status *WorkStatus
+	// ResetRequired indicates the update needs a reset to take effect.
+	ResetRequired bool
+	// Verified indicates the firmware inventory reported the baseline version
+	// for all updated entries after the update.
+	Verified bool
+	// Err is the error encountered applying the update, if any.
+	Err error
+}
+
+// ApplyFirmwareBaseline updates all firmware inventory entries that differ
+// from the baseline. Each component is applied with SimpleUpdate if it has an
+// ImageURI, or pushed to the MultipartHttpPushUri if it has an ImagePath. The
+// update tasks are waited on, staged images are activated, resets are
+// performed if needed, and the inventory is read again to verify the new
+// versions. The results of every attempted update are returned, along with an
+// error joining any failures.
+func (u *UpdateService) ApplyFirmwareBaseline(ctx context.Context, baseline []FirmwareComponent, opts *FirmwareUpdateOptions) ([]FirmwareUpdateResult, error) {
+	if opts == nil {
+		opts = &FirmwareUpdateOptions{}
+	}
+
+	report, err := u.CheckFirmwareCompliance(baseline)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := report.PendingUpdates()
+	var results []FirmwareUpdateResult
+	var resetItems []FirmwareComplianceItem
+	for i := range baseline {
+		var items []FirmwareComplianceItem
+		for j := range pending {
+			if pending[j].Component == &baseline[i] {
+				items = append(items, pending[j])
+			}
+		}
+		if len(items) == 0 {
+			continue
+		}
+
+		result := u.applyFirmwareComponent(ctx, &baseline[i], items, opts)
+		if result.Err == nil && result.ResetRequired {
+			resetItems = append(resetItems, items...)
+		}
+		results = append(results, result)
+	}
+
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	if len(resetItems) > 0 && opts.Reset != nil {
+		if err := opts.Reset(ctx, resetItems); err != nil {
+			return results, fmt.Errorf("failed to reset after firmware update: %w", err)
+		}
+		for i := range results {
+			results[i].ResetRequired = false
+		}
+	}
+
+	inventory, err := u.FirmwareInventory()
+	if err != nil {
+		return results, fmt.Errorf("failed to verify firmware versions: %w", err)
+	}
+	verified := compareFirmware(baseline, inventory)
+
+	var errs []error
+	for i := range results {
+		if results[i].Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", results[i].Component, results[i].Err))
+			continue
+		}
+
+		results[i].Verified = true
+		for j := range verified.Items {
+			item := &verified.Items[j]
+			if item.Component == results[i].Component && item.Inventory.Version != item.Component.Version {
+				results[i].Verified = false
+			}
+		}
+		if !results[i].Verified && !results[i].ResetRequired {
+			errs = append(errs, fmt.Errorf("%s: version %s not reported after update",
+				results[i].Component, results[i].Component.Version))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+func (u *UpdateService) applyFirmwareComponent(ctx context.Context, component *FirmwareComponent,
+	items []FirmwareComplianceItem, opts *FirmwareUpdateOptions) FirmwareUpdateResult {
+	result := FirmwareUpdateResult{Component: component}
+	for i := range items {
+		if opts.TargetRelatedItems && len(items[i].RelatedItems) > 0 {
+			result.Targets = append(result.Targets, items[i].RelatedItems...)
+		} else {
+			result.Targets = append(result.Targets, items[i].Inventory.ODataID)
+		}
+		if items[i].Inventory.ResetRequiredOnUpdate {
+			result.ResetRequired = true
+		}
+	}
+	if opts.ApplyTime == OnResetOperationApplyTime {
+		result.ResetRequired = true
+	}
+
+	var taskInfo *TaskMonitorInfo
+	switch {
+	case component.ImageURI != "":
+		taskInfo, result.Err = u.simpleUpdateTargets(component, result.Targets, opts)
+	case component.ImagePath != "":
+		taskInfo, result.Err = u.pushUpdateTargets(component, result.Targets, opts)
+	default:
+		result.Err = fmt.Errorf("no image location provided")
+	}
+	if result.Err != nil {
+		return result
+	}
+
+	if taskInfo != nil {
+		result.Status, result.Err = Await(ctx, u.client, taskInfo, opts.AwaitOptions)
+		if result.Err != nil {
+			return result
+		}
+	}
+
+	if opts.Stage {
+		taskInfo, result.Err = u.Activate(result.Targets)
+		if result.Err == nil && taskInfo != nil {
+			result.Status, result.Err = Await(ctx, u.client, taskInfo, opts.AwaitOptions)
+		}
+	}
+
+	return result
+}
+
+// simpleUpdateTargets starts a SimpleUpdate of the component's ImageURI.
+func (u *UpdateService) simpleUpdateTargets(component *FirmwareComponent, targets []string, opts *FirmwareUpdateOptions) (*TaskMonitorInfo, error) {
+	params := &UpdateServiceSimpleUpdateParameters{
+		ImageURI:         component.ImageURI,
+		TransferProtocol: component.TransferProtocol,
+		Username:         component.Username,
+		Password:         component.Password,
+		ForceUpdate:      component.ForceUpdate,
+		Stage:            opts.Stage,
+		Targets:          targets,
+	}
+	if opts.ApplyTime == "" {
+		return u.SimpleUpdate(params)
+	}
+
+	payload := struct {
+		*UpdateServiceSimpleUpdateParameters
+		ApplyTime OperationApplyTime `json:"@Redfish.OperationApplyTime"`
+	}{params, opts.ApplyTime}
+	resp, taskInfo, err := PostWithTask(u.client,
+		u.simpleUpdateTarget, payload, u.Headers(), false)
+	defer DeferredCleanupHTTPResponse(resp)
+	return taskInfo, err
+}
+
+// pushUpdateTargets pushes the component's ImagePath to the
+// MultipartHttpPushUri.
+func (u *UpdateService) pushUpdateTargets(component *FirmwareComponent, targets []string, opts *FirmwareUpdateOptions) (*TaskMonitorInfo, error) {
+	if u.MultipartHTTPPushURI == "" {
+		return nil, fmt.Errorf("service does not support multipart HTTP push updates")
+	}
+
+	params := map[string]any{"Targets": targets}
+	if component.ForceUpdate {
+		params["ForceUpdate"] = true
+	}
+	if opts.Stage {
+		params["Stage"] = true
+	}
+	if opts.ApplyTime != "" {
+		params["@Redfish.OperationApplyTime"] = opts.ApplyTime
+	}
+	paramData, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := os.Open(component.ImagePath)
+	if err != nil {
+		return nil, err
+	}
+	defer image.Close()
+
+	payload := map[string]io.Reader{
+		"UpdateParameters": bytes.NewReader(paramData),
+		"UpdateFile":       image,
+	}
+	resp, taskInfo, err := PostWithTask(u.client,
+		u.MultipartHTTPPushURI, payload, u.Headers(), true)
+	defer DeferredCleanupHTTPResponse(resp)
+	return taskInfo, err
+}
@@ -0,0 +1,217 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const firmwareInventoryBody = `{
+		"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory",
+		"Name": "Firmware Inventory",
+		"Members": [
+			{
+				"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/BIOS",
+				"Id": "BIOS",
+				"Name": "BIOS",
+				"SoftwareId": "BIOS-FW",
+				"Version": "%s",
+				"Updateable": true,
+				"ResetRequiredOnUpdate": true,
+				"RelatedItem": [{"@odata.id": "/redfish/v1/Systems/1/Bios"}]
+			},
+			{
+				"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/BMC",
+				"Id": "BMC",
+				"Name": "BMC",
+				"SoftwareId": "BMC-FW",
+				"Version": "2.10.0",
+				"Updateable": true
+			},
+			{
+				"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory/CPLD",
+				"Id": "CPLD",
+				"Name": "CPLD",
+				"SoftwareId": "CPLD-FW",
+				"Version": "3",
+				"Updateable": false
+			}
+		]
+	}`
+
+const firmwareUpdateServiceBody = `{
+		"@odata.id": "/redfish/v1/UpdateService",
+		"Id": "UpdateService",
+		"Name": "Update Service",
+		"FirmwareInventory": {"@odata.id": "/redfish/v1/UpdateService/FirmwareInventory"},
+		"Actions": {
+			"#UpdateService.SimpleUpdate": {
+				"target": "/redfish/v1/UpdateService/Actions/UpdateService.SimpleUpdate"
+			}
+		}
+	}`
+
+func firmwareUpdateService(t *testing.T) *UpdateService {
+	var result UpdateService
+	if err := json.Unmarshal([]byte(firmwareUpdateServiceBody), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	return &result
+}
+
+// TestCheckFirmwareCompliance tests comparing the inventory to a baseline.
+func TestCheckFirmwareCompliance(t *testing.T) {
+	result := firmwareUpdateService(t)
+	result.SetClient(&TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {getCall(fmt.Sprintf(firmwareInventoryBody, "1.0.0"))},
+		},
+	})
+
+	report, err := result.CheckFirmwareCompliance([]FirmwareComponent{
+		{SoftwareID: "BIOS-FW", Version: "1.2.0"},
+		{SoftwareID: "BMC-FW", Version: "2.9.0"},
+		{ID: "CPLD", Version: "4"},
+		{SoftwareID: "NIC-FW", Version: "22.1"},
+	})
+	if err != nil {
+		t.Fatalf("Error checking compliance: %s", err)
+	}
+
+	if report.Compliant() {
+		t.Error("Report should not be compliant")
+	}
+	if len(report.Items) != 3 {
+		t.Fatalf("Expected 3 matched items, got %d", len(report.Items))
+	}
+
+	pending := report.PendingUpdates()
+	if len(pending) != 1 || pending[0].Inventory.ID != "BIOS" {
+		t.Errorf("Expected only BIOS to need an update, got %v", pending)
+	}
+	if pending[0].RelatedItems[0] != "/redfish/v1/Systems/1/Bios" {
+		t.Errorf("Unexpected related items: %v", pending[0].RelatedItems)
+	}
+	if !report.Items[1].Downgrade || report.Items[1].Reason == "" {
+		t.Errorf("BMC downgrade should be skipped: %+v", report.Items[1])
+	}
+	if report.Items[2].Reason != "component is not updateable" {
+		t.Errorf("Unexpected CPLD reason: %s", report.Items[2].Reason)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].SoftwareID != "NIC-FW" {
+		t.Errorf("Expected NIC-FW to be missing, got %v", report.Missing)
+	}
+}
+
+// TestFirmwareComplianceMissing tests missing components make the firmware
+// non-compliant unless they are optional.
+func TestFirmwareComplianceMissing(t *testing.T) {
+	bios := FirmwareComponent{SoftwareID: "BIOS-FW", Version: "1.2.0"}
+	inventory := &SoftwareInventory{SoftwareID: "BIOS-FW", Version: "1.2.0"}
+	nic := FirmwareComponent{SoftwareID: "NIC-FW", Version: "22.1"}
+
+	report := compareFirmware([]FirmwareComponent{bios, nic}, []*SoftwareInventory{inventory})
+	if report.Compliant() {
+		t.Error("Report should not be compliant with a required component missing")
+	}
+
+	nic.Optional = true
+	report = compareFirmware([]FirmwareComponent{bios, nic}, []*SoftwareInventory{inventory})
+	if !report.Compliant() {
+		t.Error("Report should be compliant with only an optional component missing")
+	}
+	AssertEqual(t, 1, len(report.Missing))
+}
+
+// TestApplyFirmwareBaseline tests updating, resetting and verifying firmware.
+func TestApplyFirmwareBaseline(t *testing.T) {
+	result := firmwareUpdateService(t)
+
+	header := make(http.Header)
+	header.Set("Location", "/redfish/v1/TaskService/TaskMonitors/1")
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				getCall(fmt.Sprintf(firmwareInventoryBody, "1.0.0")),
+				getCall(`{"@odata.id": "/redfish/v1/TaskService/Tasks/1", "Id": "1", "TaskState": "Completed"}`),
+				getCall(fmt.Sprintf(firmwareInventoryBody, "1.2.0")),
+			},
+			http.MethodPost: {
+				&http.Response{StatusCode: http.StatusAccepted, Header: header, Body: io.NopCloser(strings.NewReader(""))},
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	resets := 0
+	results, err := result.ApplyFirmwareBaseline(context.Background(), []FirmwareComponent{
+		{SoftwareID: "BIOS-FW", Version: "1.2.0", ImageURI: "https://images.example.com/bios-1.2.0.bin"},
+		{SoftwareID: "BMC-FW", Version: "2.10.0", ImageURI: "https://images.example.com/bmc-2.10.0.bin"},
+	}, &FirmwareUpdateOptions{
+		ApplyTime:    OnResetOperationApplyTime,
+		AwaitOptions: &AwaitOptions{PollRate: time.Millisecond},
+		Reset: func(_ context.Context, items []FirmwareComplianceItem) error {
+			resets++
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error applying baseline: %s", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 update result, got %d", len(results))
+	}
+	if !results[0].Verified || results[0].ResetRequired {
+		t.Errorf("Expected a verified update, got %+v", results[0])
+	}
+	if resets != 1 {
+		t.Errorf("Expected 1 reset, got %d", resets)
+	}
+
+	calls := testClient.CapturedCalls()
+	var post TestAPICall
+	for _, call := range calls {
+		if call.Action == http.MethodPost {
+			post = call
+		}
+	}
+	if post.URL != "/redfish/v1/UpdateService/Actions/UpdateService.SimpleUpdate" {
+		t.Errorf("Unexpected update URL: %s", post.URL)
+	}
+	if !strings.Contains(post.Payload, "Targets:[/redfish/v1/UpdateService/FirmwareInventory/BIOS]") ||
+		!strings.Contains(post.Payload, "@Redfish.OperationApplyTime:OnReset") {
+		t.Errorf("Unexpected update payload: %s", post.Payload)
+	}
+}
+
+// TestCompareVersions tests comparing firmware versions.
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+		ok       bool
+	}{
+		{"1.2.0", "1.10.0", -1, true},
+		{"v2.0", "2.0.0", 0, true},
+		{"3.1.0-rc1", "3.0.9", 1, true},
+		{"A04", "A03", 0, false},
+	}
+
+	for _, tt := range tests {
+		result, ok := compareVersions(tt.a, tt.b)
+		if result != tt.expected || ok != tt.ok {
+			t.Errorf("compareVersions(%q, %q) = %d/%v, expected %d/%v",
+				tt.a, tt.b, result, ok, tt.expected, tt.ok)
+		}
+	}
+}
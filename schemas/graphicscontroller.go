@@ -98,13 +98,16 @@ func (g *GraphicsController) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (g *GraphicsController) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (g *GraphicsController) writableFields() []string {
+	return []string{
 		"AssetTag",
 	}
+}
 
-	return g.UpdateFromRawData(g, g.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (g *GraphicsController) Update() error {
+	return g.UpdateFromRawData(g, g.RawData, g.writableFields())
 }
 
 // GetGraphicsController will get a GraphicsController instance from the service.
@@ -114,13 +114,16 @@ func (h *Heater) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (h *Heater) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (h *Heater) writableFields() []string {
+	return []string{
 		"LocationIndicatorActive",
 	}
+}
 
-	return h.UpdateFromRawData(h, h.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (h *Heater) Update() error {
+	return h.UpdateFromRawData(h, h.RawData, h.writableFields())
 }
 
 // GetHeater will get a Heater instance from the service.
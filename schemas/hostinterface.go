@@ -174,9 +174,9 @@ func (h *HostInterface) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (h *HostInterface) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (h *HostInterface) writableFields() []string {
+	return []string{
 		"AuthNoneRoleId",
 		"AuthenticationModes",
 		"FirmwareAuthEnabled",
@@ -185,8 +185,11 @@ func (h *HostInterface) Update() error {
 		"KernelAuthEnabled",
 		"KernelAuthRoleId",
 	}
+}
 
-	return h.UpdateFromRawData(h, h.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (h *HostInterface) Update() error {
+	return h.UpdateFromRawData(h, h.RawData, h.writableFields())
 }
 
 // GetHostInterface will get a HostInterface instance from the service.
@@ -45,13 +45,16 @@ func (i *InitiatorEndpointGroup) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (i *InitiatorEndpointGroup) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (i *InitiatorEndpointGroup) writableFields() []string {
+	return []string{
 		"Identifier",
 	}
+}
 
-	return i.UpdateFromRawData(i, i.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (i *InitiatorEndpointGroup) Update() error {
+	return i.UpdateFromRawData(i, i.RawData, i.writableFields())
 }
 
 // GetInitiatorEndpointGroup will get a InitiatorEndpointGroup instance from the service.
@@ -65,15 +65,18 @@ func (i *IOConnectivityLineOfService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (i *IOConnectivityLineOfService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (i *IOConnectivityLineOfService) writableFields() []string {
+	return []string{
 		"AccessProtocols",
 		"MaxBytesPerSecond",
 		"MaxIOPS",
 	}
+}
 
-	return i.UpdateFromRawData(i, i.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (i *IOConnectivityLineOfService) Update() error {
+	return i.UpdateFromRawData(i, i.RawData, i.writableFields())
 }
 
 // GetIOConnectivityLineOfService will get a IOConnectivityLineOfService instance from the service.
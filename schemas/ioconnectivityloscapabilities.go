@@ -68,16 +68,19 @@ func (i *IOConnectivityLoSCapabilities) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (i *IOConnectivityLoSCapabilities) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (i *IOConnectivityLoSCapabilities) writableFields() []string {
+	return []string{
 		"MaxSupportedBytesPerSecond",
 		"MaxSupportedIOPS",
 		"SupportedAccessProtocols",
 		"SupportedLinesOfService",
 	}
+}
 
-	return i.UpdateFromRawData(i, i.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (i *IOConnectivityLoSCapabilities) Update() error {
+	return i.UpdateFromRawData(i, i.RawData, i.writableFields())
 }
 
 // GetIOConnectivityLoSCapabilities will get a IOConnectivityLoSCapabilities instance from the service.
@@ -69,16 +69,19 @@ func (i *IOPerformanceLineOfService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (i *IOPerformanceLineOfService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (i *IOPerformanceLineOfService) writableFields() []string {
+	return []string{
 		"AverageIOOperationLatencyMicroseconds",
 		"IOOperationsPerSecondIsLimited",
 		"MaxIOOperationsPerSecondPerTerabyte",
 		"SamplePeriod",
 	}
+}
 
-	return i.UpdateFromRawData(i, i.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (i *IOPerformanceLineOfService) Update() error {
+	return i.UpdateFromRawData(i, i.RawData, i.writableFields())
 }
 
 // GetIOPerformanceLineOfService will get a IOPerformanceLineOfService instance from the service.
@@ -94,17 +94,20 @@ func (i *IOPerformanceLoSCapabilities) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (i *IOPerformanceLoSCapabilities) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (i *IOPerformanceLoSCapabilities) writableFields() []string {
+	return []string{
 		"IOLimitingIsSupported",
 		"MaxSamplePeriod",
 		"MinSamplePeriod",
 		"MinSupportedIoOperationLatencyMicroseconds",
 		"SupportedLinesOfService",
 	}
+}
 
-	return i.UpdateFromRawData(i, i.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (i *IOPerformanceLoSCapabilities) Update() error {
+	return i.UpdateFromRawData(i, i.RawData, i.writableFields())
 }
 
 // GetIOPerformanceLoSCapabilities will get a IOPerformanceLoSCapabilities instance from the service.
@@ -284,14 +284,17 @@ func (j *Job) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (j *Job) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (j *Job) writableFields() []string {
+	return []string{
 		"JobState",
 		"MaxExecutionTime",
 	}
+}
 
-	return j.UpdateFromRawData(j, j.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (j *Job) Update() error {
+	return j.UpdateFromRawData(j, j.RawData, j.writableFields())
 }
 
 // GetJob will get a Job instance from the service.
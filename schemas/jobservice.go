@@ -114,13 +114,16 @@ func (j *JobService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (j *JobService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (j *JobService) writableFields() []string {
+	return []string{
 		"ServiceEnabled",
 	}
+}
 
-	return j.UpdateFromRawData(j, j.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (j *JobService) Update() error {
+	return j.UpdateFromRawData(j, j.RawData, j.writableFields())
 }
 
 // GetJobService will get a JobService instance from the service.
@@ -119,13 +119,16 @@ func (k *Key) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (k *Key) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (k *Key) writableFields() []string {
+	return []string{
 		"UserDescription",
 	}
+}
 
-	return k.UpdateFromRawData(k, k.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (k *Key) Update() error {
+	return k.UpdateFromRawData(k, k.RawData, k.writableFields())
 }
 
 // GetKey will get a Key instance from the service.
@@ -157,13 +157,16 @@ func (k *KeyPolicy) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (k *KeyPolicy) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (k *KeyPolicy) writableFields() []string {
+	return []string{
 		"IsDefault",
 	}
+}
 
-	return k.UpdateFromRawData(k, k.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (k *KeyPolicy) Update() error {
+	return k.UpdateFromRawData(k, k.RawData, k.writableFields())
 }
 
 // GetKeyPolicy will get a KeyPolicy instance from the service.
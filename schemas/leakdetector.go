@@ -179,17 +179,20 @@ func (l *LeakDetector) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (l *LeakDetector) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (l *LeakDetector) writableFields() []string {
+	return []string{
 		"CriticalReactionType",
 		"Enabled",
 		"ReactionDelaySeconds",
 		"UserLabel",
 		"WarningReactionType",
 	}
+}
 
-	return l.UpdateFromRawData(l, l.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (l *LeakDetector) Update() error {
+	return l.UpdateFromRawData(l, l.RawData, l.writableFields())
 }
 
 // GetLeakDetector will get a LeakDetector instance from the service.
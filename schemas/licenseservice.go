@@ -74,14 +74,17 @@ func (l *LicenseService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (l *LicenseService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (l *LicenseService) writableFields() []string {
+	return []string{
 		"LicenseExpirationWarningDays",
 		"ServiceEnabled",
 	}
+}
 
-	return l.UpdateFromRawData(l, l.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (l *LicenseService) Update() error {
+	return l.UpdateFromRawData(l, l.RawData, l.writableFields())
 }
 
 // GetLicenseService will get a LicenseService instance from the service.
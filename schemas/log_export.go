@@ -0,0 +1,350 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LogRecord is a log entry or event in the form written by log exporters. The
+// JSON field names are stable so records can be ingested by log pipelines.
+type LogRecord struct {
+	// Timestamp is the time the event occurred, or the time the entry was
+	// created if that is not known.
+	Timestamp string `json:"timestamp"`
+	// ID is the Id of the log entry, or the EventId of the event.
+	ID string `json:"id"`
+	// URI is the URI of the log entry.
+	URI string `json:"uri"`
+	// Severity is the severity, one of OK, Warning or Critical.
+	Severity string `json:"severity"`
+	// EntryType is the type of the log entry, or the type of event.
+	EntryType string `json:"entry_type"`
+	// EntryCode is the IPMI entry code of SEL entries.
+	EntryCode string `json:"entry_code"`
+	// SensorType is the IPMI sensor type of SEL entries.
+	SensorType string `json:"sensor_type"`
+	// MessageID is the MessageId of the message.
+	MessageID string `json:"message_id"`
+	// MessageArgs are the arguments of the message.
+	MessageArgs []string `json:"message_args"`
+	// Message is the message text, resolved from the message registry when the
+	// service did not provide it.
+	Message string `json:"message"`
+	// OriginOfCondition is the URI of the resource that caused the condition.
+	OriginOfCondition string `json:"origin_of_condition"`
+}
+
+// NewLogEntryRecord converts a log entry to a LogRecord. If resolve is not nil
+// it is used to get the text of messages the service did not provide, see
+// NewMessageResolver.
+func NewLogEntryRecord(entry *LogEntry, resolve func(Message) string) *LogRecord {
+	timestamp := entry.EventTimestamp
+	if timestamp == "" {
+		timestamp = entry.Created
+	}
+	return &LogRecord{
+		Timestamp:         timestamp,
+		ID:                entry.ID,
+		URI:               entry.ODataID,
+		Severity:          string(entry.Severity),
+		EntryType:         string(entry.EntryType),
+		EntryCode:         string(entry.EntryCode),
+		SensorType:        string(entry.SensorType),
+		MessageID:         entry.MessageID,
+		MessageArgs:       entry.MessageArgs,
+		Message:           resolveRecordMessage(entry.Message, entry.MessageID, entry.MessageArgs, resolve),
+		OriginOfCondition: entry.originOfCondition,
+	}
+}
+
+// NewEventRecord converts an event to a LogRecord. If resolve is not nil it is
+// used to get the text of messages the service did not provide, see
+// NewMessageResolver.
+func NewEventRecord(event *EventRecord, resolve func(Message) string) *LogRecord {
+	severity := string(event.MessageSeverity)
+	if severity == "" {
+		severity = event.Severity
+	}
+	return &LogRecord{
+		Timestamp:         event.EventTimestamp,
+		ID:                event.EventID,
+		URI:               event.logEntry,
+		Severity:          severity,
+		EntryType:         string(event.EventType),
+		MessageID:         event.MessageID,
+		MessageArgs:       event.MessageArgs,
+		Message:           resolveRecordMessage(event.Message, event.MessageID, event.MessageArgs, resolve),
+		OriginOfCondition: event.originOfCondition,
+	}
+}
+
+func resolveRecordMessage(message, messageID string, args []string, resolve func(Message) string) string {
+	if message != "" || resolve == nil {
+		return message
+	}
+	return resolve(Message{MessageID: messageID, MessageArgs: args})
+}
+
+// LogExporter writes log records in a log format.
+type LogExporter interface {
+	Export(record *LogRecord) error
+}
+
+// ExportLogEntries writes the log entries to the exporter.
+func ExportLogEntries(exporter LogExporter, entries []*LogEntry, resolve func(Message) string) error {
+	for _, entry := range entries {
+		if err := exporter.Export(NewLogEntryRecord(entry, resolve)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportEvents writes the records of the events to the exporter.
+func ExportEvents(exporter LogExporter, events []*Event, resolve func(Message) string) error {
+	for _, event := range events {
+		for i := range event.Events {
+			if err := exporter.Export(NewEventRecord(&event.Events[i], resolve)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ExportLogStream writes the log entries received, such as from
+// LogService.Tail, to the exporter until the channel is closed or the context
+// is done.
+func ExportLogStream(ctx context.Context, exporter LogExporter, entries <-chan *LogEntry, resolve func(Message) string) error {
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				return nil
+			}
+			if err := exporter.Export(NewLogEntryRecord(entry, resolve)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Export writes all entries of the log to the exporter, oldest first.
+func (l *LogService) Export(exporter LogExporter, resolve func(Message) string) error {
+	entries, err := l.Entries()
+	if err != nil {
+		return err
+	}
+
+	// Entries are retrieved concurrently, so put them back in order
+	slices.SortStableFunc(entries, func(a, b *LogEntry) int {
+		return strings.Compare(logEntryTime(a), logEntryTime(b))
+	})
+
+	return ExportLogEntries(exporter, entries, resolve)
+}
+
+// logEntryTime returns when the entry was created in a form that sorts in time
+// order.
+func logEntryTime(entry *LogEntry) string {
+	created, err := time.Parse(time.RFC3339, entry.Created)
+	if err != nil {
+		return entry.Created
+	}
+	return created.UTC().Format(time.RFC3339Nano)
+}
+
+// JSONLinesExporter writes each log record as a line of JSON.
+type JSONLinesExporter struct {
+	encoder *json.Encoder
+}
+
+// NewJSONLinesExporter creates a JSONLinesExporter writing to w.
+func NewJSONLinesExporter(w io.Writer) *JSONLinesExporter {
+	return &JSONLinesExporter{encoder: json.NewEncoder(w)}
+}
+
+// Export writes the record.
+func (e *JSONLinesExporter) Export(record *LogRecord) error {
+	return e.encoder.Encode(record)
+}
+
+// logRecordCSVHeader is the header row written by CSVExporter. The columns
+// match the JSON field names of LogRecord.
+var logRecordCSVHeader = []string{
+	"timestamp", "id", "uri", "severity", "entry_type", "entry_code", "sensor_type",
+	"message_id", "message_args", "message", "origin_of_condition",
+}
+
+// CSVExporter writes log records as CSV, preceded by a header row. Message
+// arguments are joined with semicolons.
+type CSVExporter struct {
+	writer      *csv.Writer
+	wroteHeader bool
+}
+
+// NewCSVExporter creates a CSVExporter writing to w.
+func NewCSVExporter(w io.Writer) *CSVExporter {
+	return &CSVExporter{writer: csv.NewWriter(w)}
+}
+
+// Export writes the record.
+func (e *CSVExporter) Export(record *LogRecord) error {
+	if !e.wroteHeader {
+		if err := e.writer.Write(logRecordCSVHeader); err != nil {
+			return err
+		}
+		e.wroteHeader = true
+	}
+
+	err := e.writer.Write([]string{
+		record.Timestamp, record.ID, record.URI, record.Severity, record.EntryType, record.EntryCode,
+		record.SensorType, record.MessageID, strings.Join(record.MessageArgs, ";"), record.Message,
+		record.OriginOfCondition,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Flush every record so streamed logs are written as they arrive
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+const (
+	// defaultSyslogFacility is the local use 0 facility.
+	defaultSyslogFacility = 16
+	// syslogStructuredDataID is the structured data ID of the record details,
+	// using the DMTF's private enterprise number.
+	syslogStructuredDataID = "redfish@412"
+	// syslogNil is the value of empty syslog header fields.
+	syslogNil = "-"
+)
+
+// SyslogOptions control the syslog header fields.
+type SyslogOptions struct {
+	// Facility is the syslog facility code. Defaults to 16 (local0).
+	Facility int
+	// Hostname identifies the service that logged the records, such as the
+	// BMC's host name.
+	Hostname string
+	// AppName identifies the log, such as the LogService Id. Defaults to
+	// "redfish".
+	AppName string
+}
+
+// SyslogExporter writes each log record as an RFC 5424 syslog message on its
+// own line. The record details other than the message are written as
+// structured data.
+type SyslogExporter struct {
+	w        io.Writer
+	facility int
+	hostname string
+	appName  string
+}
+
+// NewSyslogExporter creates a SyslogExporter writing to w.
+func NewSyslogExporter(w io.Writer, opts *SyslogOptions) *SyslogExporter {
+	if opts == nil {
+		opts = &SyslogOptions{}
+	}
+	e := &SyslogExporter{
+		w:        w,
+		facility: opts.Facility,
+		hostname: syslogHeaderField(opts.Hostname, 255),
+		appName:  syslogHeaderField(opts.AppName, 48),
+	}
+	if e.facility <= 0 || e.facility > 23 {
+		e.facility = defaultSyslogFacility
+	}
+	if e.appName == syslogNil {
+		e.appName = "redfish"
+	}
+	return e
+}
+
+// Export writes the record.
+func (e *SyslogExporter) Export(record *LogRecord) error {
+	timestamp := syslogNil
+	if t, err := time.Parse(time.RFC3339, record.Timestamp); err == nil {
+		timestamp = t.Format(time.RFC3339Nano)
+	}
+
+	var sd strings.Builder
+	sd.WriteString("[" + syslogStructuredDataID)
+	params := [][2]string{
+		{"id", record.ID},
+		{"uri", record.URI},
+		{"severity", record.Severity},
+		{"entryType", record.EntryType},
+		{"entryCode", record.EntryCode},
+		{"sensorType", record.SensorType},
+		{"messageId", record.MessageID},
+		{"originOfCondition", record.OriginOfCondition},
+	}
+	for _, param := range params {
+		if param[1] != "" {
+			fmt.Fprintf(&sd, " %s=\"%s\"", param[0], syslogParamEscaper.Replace(param[1]))
+		}
+	}
+	sd.WriteString("]")
+
+	message := record.Message
+	if message == "" {
+		message = record.MessageID
+	}
+	message = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(message)
+
+	_, err := fmt.Fprintf(e.w, "<%d>1 %s %s %s %s %s %s %s\n",
+		e.facility*8+syslogSeverity(record.Severity), timestamp, e.hostname, e.appName,
+		syslogNil, syslogHeaderField(record.MessageID, 32), sd.String(), message)
+	return err
+}
+
+// syslogParamEscaper escapes the characters structured data values can't
+// contain.
+var syslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogSeverity returns the syslog severity for a Redfish severity.
+func syslogSeverity(severity string) int {
+	switch severity {
+	case string(CriticalEventSeverity):
+		return 2
+	case string(WarningEventSeverity):
+		return 4
+	case string(OKEventSeverity):
+		return 6
+	}
+	// Unknown severities are worth noticing
+	return 5
+}
+
+// syslogHeaderField returns the value as a syslog header field, which must be
+// printable ASCII without spaces and limited in length.
+func syslogHeaderField(value string, maxLength int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+	if value == "" {
+		return syslogNil
+	}
+	if len(value) > maxLength {
+		value = value[:maxLength]
+	}
+	return value
+}
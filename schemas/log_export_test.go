@@ -0,0 +1,135 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var exportLogEntryBody = `{
+		"@odata.id": "/redfish/v1/Systems/1/LogServices/SEL/Entries/3",
+		"Id": "3",
+		"Created": "2024-05-01T10:15:00+02:00",
+		"EntryType": "SEL",
+		"EntryCode": "Assert",
+		"SensorType": "Temperature",
+		"Severity": "Critical",
+		"MessageId": "Platform.1.0.TempHigh",
+		"MessageArgs": ["CPU \"1\"", "95"],
+		"Links": {"OriginOfCondition": {"@odata.id": "/redfish/v1/Chassis/1/Sensors/CPU1Temp"}}
+	}`
+
+func exportLogEntry(t *testing.T) *LogEntry {
+	var result LogEntry
+	if err := json.Unmarshal([]byte(exportLogEntryBody), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	return &result
+}
+
+func resolveTestMessage(m Message) string {
+	return FormatRegistryMessage("%1 temperature is %2C.", m.MessageArgs)
+}
+
+// TestSyslogExporter tests log entries are written as RFC 5424 messages.
+func TestSyslogExporter(t *testing.T) {
+	var out bytes.Buffer
+	exporter := NewSyslogExporter(&out, &SyslogOptions{Hostname: "bmc-1", AppName: "SEL"})
+
+	if err := ExportLogEntries(exporter, []*LogEntry{exportLogEntry(t)}, resolveTestMessage); err != nil {
+		t.Fatalf("Error exporting: %s", err)
+	}
+
+	expected := `<130>1 2024-05-01T10:15:00+02:00 bmc-1 SEL - Platform.1.0.TempHigh [redfish@412 id="3" ` +
+		`uri="/redfish/v1/Systems/1/LogServices/SEL/Entries/3" severity="Critical" entryType="SEL" entryCode="Assert" ` +
+		`sensorType="Temperature" messageId="Platform.1.0.TempHigh" originOfCondition="/redfish/v1/Chassis/1/Sensors/CPU1Temp"] ` +
+		`CPU "1" temperature is 95C.` + "\n"
+	AssertEqual(t, expected, out.String())
+
+	out.Reset()
+	if err := exporter.Export(&LogRecord{Severity: "OK", Message: "line one\nline two", ID: `a]b"c`}); err != nil {
+		t.Fatalf("Error exporting: %s", err)
+	}
+	AssertEqual(t, `<134>1 - bmc-1 SEL - - [redfish@412 id="a\]b\"c" severity="OK"] line one line two`+"\n", out.String())
+}
+
+// TestJSONLinesExporter tests records are written with stable field names.
+func TestJSONLinesExporter(t *testing.T) {
+	var out bytes.Buffer
+	exporter := NewJSONLinesExporter(&out)
+
+	entry := exportLogEntry(t)
+	if err := ExportLogEntries(exporter, []*LogEntry{entry, entry}, nil); err != nil {
+		t.Fatalf("Error exporting: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	AssertEqual(t, 2, len(lines))
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("Error decoding line: %s", err)
+	}
+	AssertEqual(t, "Critical", record["severity"])
+	AssertEqual(t, "Temperature", record["sensor_type"])
+	AssertEqual(t, "Platform.1.0.TempHigh", record["message_id"])
+	AssertEqual(t, "/redfish/v1/Chassis/1/Sensors/CPU1Temp", record["origin_of_condition"])
+	// Without a resolver the service's empty message is kept
+	AssertEqual(t, "", record["message"])
+}
+
+// TestCSVExporter tests a header row is written before the records.
+func TestCSVExporter(t *testing.T) {
+	var out bytes.Buffer
+	exporter := NewCSVExporter(&out)
+
+	if err := ExportLogEntries(exporter, []*LogEntry{exportLogEntry(t)}, resolveTestMessage); err != nil {
+		t.Fatalf("Error exporting: %s", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	AssertEqual(t, strings.Join(logRecordCSVHeader, ","), lines[0])
+	AssertEqual(t, `2024-05-01T10:15:00+02:00,3,/redfish/v1/Systems/1/LogServices/SEL/Entries/3,Critical,SEL,Assert,`+
+		`Temperature,Platform.1.0.TempHigh,"CPU ""1"";95","CPU ""1"" temperature is 95C.",/redfish/v1/Chassis/1/Sensors/CPU1Temp`, lines[1])
+}
+
+// TestExportEvents tests event records are exported.
+func TestExportEvents(t *testing.T) {
+	var event Event
+	body := `{"Events": [{"EventId": "42", "EventTimestamp": "2024-05-01T08:00:00Z", "EventType": "Alert",
+		"MessageSeverity": "Warning", "Severity": "Critical", "MessageId": "Base.1.8.Example", "Message": "Fan slow",
+		"LogEntry": {"@odata.id": "/redfish/v1/Managers/1/LogServices/Log/Entries/42"},
+		"OriginOfCondition": {"@odata.id": "/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1"}}]}`
+	if err := json.Unmarshal([]byte(body), &event); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	var out bytes.Buffer
+	if err := ExportEvents(NewSyslogExporter(&out, nil), []*Event{&event}, resolveTestMessage); err != nil {
+		t.Fatalf("Error exporting: %s", err)
+	}
+	AssertEqual(t, `<132>1 2024-05-01T08:00:00Z - redfish - Base.1.8.Example [redfish@412 id="42" `+
+		`uri="/redfish/v1/Managers/1/LogServices/Log/Entries/42" severity="Warning" entryType="Alert" `+
+		`messageId="Base.1.8.Example" originOfCondition="/redfish/v1/Chassis/1/ThermalSubsystem/Fans/1"] Fan slow`+"\n", out.String())
+}
+
+// TestExportLogStream tests streamed entries are exported until the channel
+// is closed.
+func TestExportLogStream(t *testing.T) {
+	entries := make(chan *LogEntry, 2)
+	entries <- exportLogEntry(t)
+	entries <- exportLogEntry(t)
+	close(entries)
+
+	var out bytes.Buffer
+	if err := ExportLogStream(context.Background(), NewJSONLinesExporter(&out), entries, nil); err != nil {
+		t.Fatalf("Error exporting: %s", err)
+	}
+	AssertEqual(t, 2, strings.Count(out.String(), "\n"))
+}
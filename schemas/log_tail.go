@@ -0,0 +1,508 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// defaultTailPollRate is used when LogTailOptions.PollRate is not set.
+const defaultTailPollRate = 30 * time.Second
+
+// defaultTailPageSize is used when LogTailOptions.PageSize is not set.
+const defaultTailPageSize = 50
+
+// defaultTailMaxRetries is used when LogTailOptions.MaxRetries is not set.
+const defaultTailMaxRetries = 5
+
+// maxTailRetryDelay limits the backoff between retries of a failed poll,
+// unless the PollRate is longer.
+const maxTailRetryDelay = 5 * time.Minute
+
+// LogResetReason describes why previously seen log entries are gone.
+type LogResetReason string
+
+const (
+	// ClearedLogResetReason indicates the log was cleared.
+	ClearedLogResetReason LogResetReason = "Cleared"
+	// WrappedLogResetReason indicates the log was full and older entries,
+	// possibly including unseen entries, were overwritten.
+	WrappedLogResetReason LogResetReason = "Wrapped"
+)
+
+// LogCursor records the position in a log that has been read up to. It can be
+// saved and passed back in LogTailOptions to resume tailing later.
+type LogCursor struct {
+	// LastID is the Id of the newest entry read.
+	LastID string
+	// LastCreated is the Created timestamp of the newest entry read.
+	LastCreated string
+	// Count is the number of entries in the log when last read.
+	Count int
+	// ETag is the ETag of the entry collection when last read.
+	ETag string
+}
+
+// LogTailOptions controls how a log is tailed.
+type LogTailOptions struct {
+	// PollRate is the interval between checks for new entries. Defaults to
+	// thirty seconds.
+	PollRate time.Duration
+	// PageSize is the number of entries requested at once with $top.
+	// Defaults to 50.
+	PageSize int
+	// Cursor resumes tailing from a previous position. If nil, tailing
+	// starts after the newest entry, unless FromStart is set.
+	Cursor *LogCursor
+	// FromStart returns all existing entries on the first poll when there is
+	// no Cursor.
+	FromStart bool
+	// UseFilter requests new entries with a $filter on their Created
+	// timestamp instead of paging through the newest entries. Only set this
+	// for services that support $filter.
+	UseFilter bool
+	// OnReset, if set, is called when previously seen entries are found to
+	// be gone, because the log was cleared or wrapped. A log whose
+	// OverWritePolicy is NeverOverWrites is always reported as cleared.
+	OnReset func(LogResetReason)
+	// MaxRetries is the number of times in a row Tail retries a poll that
+	// failed with a transient error, such as a timeout or a 503 response,
+	// waiting twice as long before each retry starting from the PollRate.
+	// Defaults to 5. Set a negative value to not retry.
+	MaxRetries int
+}
+
+// LogTailer reads the entries added to a log since it was last read.
+type LogTailer struct {
+	service     *LogService
+	opts        LogTailOptions
+	cursor      *LogCursor
+	newestFirst *bool
+}
+
+// NewLogTailer creates a LogTailer for the log service.
+func (l *LogService) NewLogTailer(opts *LogTailOptions) *LogTailer {
+	t := &LogTailer{service: l}
+	if opts != nil {
+		t.opts = *opts
+	}
+	if t.opts.PageSize <= 0 {
+		t.opts.PageSize = defaultTailPageSize
+	}
+	if t.opts.PollRate <= 0 {
+		t.opts.PollRate = defaultTailPollRate
+	}
+	if t.opts.MaxRetries == 0 {
+		t.opts.MaxRetries = defaultTailMaxRetries
+	}
+	if t.opts.Cursor != nil {
+		cursor := *t.opts.Cursor
+		t.cursor = &cursor
+	}
+	return t
+}
+
+// Tail sends each new entry in the log to entries, oldest first, until the
+// context is done or reading the log fails. Polls that fail with a transient
+// error are retried with backoff up to MaxRetries times in a row. The position
+// reached is returned so tailing can be resumed later.
+func (l *LogService) Tail(ctx context.Context, entries chan<- *LogEntry, opts *LogTailOptions) (*LogCursor, error) {
+	t := l.NewLogTailer(opts)
+
+	failures := 0
+	for {
+		newEntries, err := t.Poll()
+		if err != nil {
+			if !isTransient(err) || failures >= t.opts.MaxRetries {
+				return t.Cursor(), err
+			}
+			failures++
+			select {
+			case <-time.After(t.retryDelay(failures)):
+				continue
+			case <-ctx.Done():
+				return t.Cursor(), ctx.Err()
+			}
+		}
+		failures = 0
+
+		for _, entry := range newEntries {
+			select {
+			case entries <- entry:
+			case <-ctx.Done():
+				return t.Cursor(), ctx.Err()
+			}
+		}
+
+		select {
+		case <-time.After(t.opts.PollRate):
+		case <-ctx.Done():
+			return t.Cursor(), ctx.Err()
+		}
+	}
+}
+
+// retryDelay returns how long to wait before retrying after a number of
+// failed polls in a row.
+func (t *LogTailer) retryDelay(failures int) time.Duration {
+	limit := max(t.opts.PollRate, maxTailRetryDelay)
+	delay := t.opts.PollRate
+	for i := 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// isTransient returns whether the error may not happen again, such as a
+// network error or a response saying the service is busy.
+func isTransient(err error) bool {
+	var redfishErr *Error
+	if errors.As(err, &redfishErr) {
+		switch redfishErr.HTTPReturnedStatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+			http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Cursor returns the position read up to, or nil if the log has not been read.
+func (t *LogTailer) Cursor() *LogCursor {
+	if t.cursor == nil {
+		return nil
+	}
+	cursor := *t.cursor
+	return &cursor
+}
+
+// Poll returns the entries added since the last poll, oldest first. The first
+// poll without a cursor only records the position, unless FromStart is set.
+func (t *LogTailer) Poll() ([]*LogEntry, error) {
+	if t.service.entries == "" {
+		return nil, errors.New("log service has no entries")
+	}
+
+	var etag string
+	if t.cursor != nil {
+		etag = t.cursor.ETag
+	}
+	probe, err := t.fetch(fmt.Sprintf("?$top=%d", 1), etag)
+	if errors.Is(err, ErrNotModified) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Without a cursor, start from the newest entry
+	if t.cursor == nil && !t.opts.FromStart {
+		newest, err := t.newest(probe)
+		if err != nil {
+			return nil, err
+		}
+		t.cursor = &LogCursor{Count: probe.count, ETag: probe.etag}
+		if newest != nil {
+			t.cursor.LastID = newest.ID
+			t.cursor.LastCreated = newest.Created
+		}
+		return nil, nil
+	}
+
+	var collected []*LogEntry
+	found := false
+	if t.opts.UseFilter && t.cursor != nil && t.cursor.LastCreated != "" {
+		collected, found, err = t.filterNewer()
+	} else {
+		collected, found, err = t.walkNewest(probe)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if t.cursor != nil && t.cursor.LastID != "" && !found && t.opts.OnReset != nil {
+		t.opts.OnReset(t.resetReason(probe.count))
+	}
+
+	if t.cursor == nil {
+		t.cursor = &LogCursor{}
+	}
+	t.cursor.Count = probe.count
+	t.cursor.ETag = probe.etag
+	if len(collected) > 0 {
+		t.cursor.LastID = collected[0].ID
+		t.cursor.LastCreated = collected[0].Created
+	}
+
+	// Entries were collected newest first
+	slices.Reverse(collected)
+	return collected, nil
+}
+
+// resetReason returns why the cursor's entry is gone from a log with count
+// entries. A log that never overwrites entries can only have been cleared, as
+// can one with fewer entries than before.
+func (t *LogTailer) resetReason(count int) LogResetReason {
+	if t.service.OverWritePolicy == NeverOverWritesLogServiceOverWritePolicy || count < t.cursor.Count {
+		return ClearedLogResetReason
+	}
+	return WrappedLogResetReason
+}
+
+// logPage is a page of a log entry collection.
+type logPage struct {
+	count   int
+	entries []*LogEntry
+	etag    string
+}
+
+// fetch gets the entry collection with the query. Members that are only links
+// are resolved as they are needed, as a service ignoring the paging
+// parameters may return every entry.
+func (t *LogTailer) fetch(query, etag string) (*logPage, error) {
+	c := t.service.GetClient()
+	headers := map[string]string{}
+	if etag != "" {
+		headers["If-None-Match"] = etag
+	}
+
+	resp, err := c.GetWithHeaders(t.service.entries+query, headers)
+	defer DeferredCleanupHTTPResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var collection struct {
+		Count   int `json:"Members@odata.count"`
+		Members []json.RawMessage
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&collection); err != nil {
+		return nil, err
+	}
+
+	page := &logPage{count: collection.Count, etag: resp.Header.Get("Etag")}
+	if page.count < len(collection.Members) {
+		page.count = len(collection.Members)
+	}
+	for _, member := range collection.Members {
+		entry := &LogEntry{}
+		if err := json.Unmarshal(member, entry); err != nil {
+			return nil, err
+		}
+		entry.SetClient(c)
+		page.entries = append(page.entries, entry)
+	}
+
+	return page, nil
+}
+
+// resolve fetches an entry that was only listed as a link.
+func (t *LogTailer) resolve(entry *LogEntry) (*LogEntry, error) {
+	if entry.ID != "" || entry.Created != "" || entry.ODataID == "" {
+		return entry, nil
+	}
+	return GetObject[LogEntry](t.service.GetClient(), entry.ODataID)
+}
+
+// fetchRange gets up to top entries starting at skip. If the service ignores
+// the paging parameters, all entries are returned.
+func (t *LogTailer) fetchRange(skip, top int) (entries []*LogEntry, paged bool, err error) {
+	query := fmt.Sprintf("?$top=%d", top)
+	if skip > 0 {
+		query = fmt.Sprintf("?$skip=%d&$top=%d", skip, top)
+	}
+	page, err := t.fetch(query, "")
+	if err != nil {
+		return nil, false, err
+	}
+	return page.entries, len(page.entries) <= top, nil
+}
+
+// isNewestFirst returns whether the service lists the newest entries first.
+func (t *LogTailer) isNewestFirst(probe *logPage) (bool, error) {
+	if t.newestFirst != nil {
+		return *t.newestFirst, nil
+	}
+	if probe.count < 2 || len(probe.entries) == 0 {
+		return false, nil
+	}
+
+	first, last := probe.entries[0], probe.entries[len(probe.entries)-1]
+	if len(probe.entries) == 1 {
+		entries, _, err := t.fetchRange(probe.count-1, 1)
+		if err != nil {
+			return false, err
+		}
+		if len(entries) > 0 {
+			last = entries[len(entries)-1]
+		}
+	}
+
+	first, err := t.resolve(first)
+	if err != nil {
+		return false, err
+	}
+	last, err = t.resolve(last)
+	if err != nil {
+		return false, err
+	}
+
+	newestFirst := isNewerLogEntry(first, last)
+	t.newestFirst = &newestFirst
+	return newestFirst, nil
+}
+
+// newest returns the newest entry in the log.
+func (t *LogTailer) newest(probe *logPage) (*LogEntry, error) {
+	if len(probe.entries) == 0 {
+		return nil, nil
+	}
+	newestFirst, err := t.isNewestFirst(probe)
+	if err != nil {
+		return nil, err
+	}
+	if newestFirst || probe.count == 1 {
+		return t.resolve(probe.entries[0])
+	}
+	if len(probe.entries) == probe.count {
+		return t.resolve(probe.entries[len(probe.entries)-1])
+	}
+
+	entries, _, err := t.fetchRange(probe.count-1, 1)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return t.resolve(entries[len(entries)-1])
+}
+
+// walkNewest pages back from the newest entry until reaching the cursor,
+// returning the newer entries newest first and whether the cursor was found.
+func (t *LogTailer) walkNewest(probe *logPage) (collected []*LogEntry, found bool, err error) {
+	newestFirst, err := t.isNewestFirst(probe)
+	if err != nil {
+		return nil, false, err
+	}
+
+	size := t.opts.PageSize
+	for fetched := 0; fetched < probe.count; {
+		skip, top := fetched, size
+		if !newestFirst {
+			skip = max(probe.count-fetched-size, 0)
+			top = probe.count - fetched - skip
+		}
+
+		entries, paged, err := t.fetchRange(skip, top)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(entries) == 0 {
+			break
+		}
+		if !newestFirst {
+			slices.Reverse(entries)
+		}
+
+		for _, entry := range entries {
+			entry, err := t.resolve(entry)
+			if err != nil {
+				return nil, false, err
+			}
+			if t.reached(entry) {
+				return collected, true, nil
+			}
+			collected = append(collected, entry)
+		}
+
+		if !paged {
+			break
+		}
+		fetched += len(entries)
+	}
+
+	return collected, false, nil
+}
+
+// filterNewer gets the entries created at or after the cursor's entry using
+// $filter, returning the ones after it newest first and whether the cursor's
+// entry was found. Entries created in the same second as the cursor's entry
+// are only returned if they come after it.
+func (t *LogTailer) filterNewer() ([]*LogEntry, bool, error) {
+	filter := fmt.Sprintf("Created ge '%s'", t.cursor.LastCreated)
+	page, err := t.fetch("?$filter="+url.PathEscape(filter), "")
+	if err != nil {
+		return nil, false, err
+	}
+
+	entries := make([]*LogEntry, 0, len(page.entries))
+	for _, entry := range page.entries {
+		entry, err := t.resolve(entry)
+		if err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, entry)
+	}
+
+	// Entries that can't be ordered otherwise keep the order of the service,
+	// which lists the oldest first unless found to be otherwise
+	if t.newestFirst == nil || !*t.newestFirst {
+		slices.Reverse(entries)
+	}
+	slices.SortStableFunc(entries, func(a, b *LogEntry) int {
+		switch {
+		case isNewerLogEntry(a, b):
+			return -1
+		case isNewerLogEntry(b, a):
+			return 1
+		}
+		return 0
+	})
+
+	if i := slices.IndexFunc(entries, func(entry *LogEntry) bool { return entry.ID == t.cursor.LastID }); i >= 0 {
+		return entries[:i], true, nil
+	}
+	return slices.DeleteFunc(entries, t.reached), false, nil
+}
+
+// reached returns whether the entry is the cursor's entry or older.
+func (t *LogTailer) reached(entry *LogEntry) bool {
+	if t.cursor == nil || t.cursor.LastID == "" {
+		return false
+	}
+	if entry.ID == t.cursor.LastID && (t.cursor.LastCreated == "" || entry.Created == t.cursor.LastCreated) {
+		return true
+	}
+
+	created, err := time.Parse(time.RFC3339, entry.Created)
+	if err != nil {
+		return false
+	}
+	last, err := time.Parse(time.RFC3339, t.cursor.LastCreated)
+	return err == nil && created.Before(last)
+}
+
+// isNewerLogEntry returns whether a was added to the log after b, judged by
+// the Created timestamps or failing that, numeric Ids.
+func isNewerLogEntry(a, b *LogEntry) bool {
+	aCreated, aErr := time.Parse(time.RFC3339, a.Created)
+	bCreated, bErr := time.Parse(time.RFC3339, b.Created)
+	if aErr == nil && bErr == nil && !aCreated.Equal(bCreated) {
+		return aCreated.After(bCreated)
+	}
+
+	aID, aErr := strconv.Atoi(a.ID)
+	bID, bErr := strconv.Atoi(b.ID)
+	return aErr == nil && bErr == nil && aID > bID
+}
@@ -0,0 +1,256 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLogClient serves a log entry collection supporting $skip and $top.
+type fakeLogClient struct {
+	*TestClient
+	mu          sync.Mutex
+	ids         []int
+	newestFirst bool
+	requests    []string
+	// created overrides the Created timestamps of entries by Id
+	created map[int]string
+	// failures is the number of requests to fail as unavailable
+	failures int
+}
+
+func (c *fakeLogClient) add(ids ...int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids = append(c.ids, ids...)
+}
+
+func (c *fakeLogClient) Get(uri string) (*http.Response, error) {
+	return c.GetWithHeaders(uri, nil)
+}
+
+func (c *fakeLogClient) GetWithHeaders(uri string, _ map[string]string) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, uri)
+	if c.failures > 0 {
+		c.failures--
+		return nil, ConstructError(http.StatusServiceUnavailable, nil)
+	}
+
+	ids := slices.Clone(c.ids)
+	if c.newestFirst {
+		slices.Reverse(ids)
+	}
+
+	_, query, _ := strings.Cut(uri, "?")
+	values, _ := url.ParseQuery(query)
+	skip, _ := strconv.Atoi(values.Get("$skip"))
+	top, err := strconv.Atoi(values.Get("$top"))
+	if err != nil {
+		top = len(ids)
+	}
+	skip = min(skip, len(ids))
+	ids = ids[skip:min(skip+top, len(ids))]
+
+	members := make([]string, 0, len(ids))
+	for _, id := range ids {
+		created, ok := c.created[id]
+		if !ok {
+			created = fmt.Sprintf("2024-01-01T00:%02d:00Z", id)
+		}
+		members = append(members, fmt.Sprintf(`{"@odata.id": "/redfish/v1/Systems/1/LogServices/SEL/Entries/%d", `+
+			`"Id": "%d", "Created": %q}`, id, id, created))
+	}
+	body := fmt.Sprintf(`{"Members@odata.count": %d, "Members": [%s]}`, len(c.ids), strings.Join(members, ","))
+	return getCall(body), nil
+}
+
+func tailLogService(t *testing.T, c Client) *LogService {
+	return tailLogServiceWithPolicy(t, c, UnknownLogServiceOverWritePolicy)
+}
+
+func tailLogServiceWithPolicy(t *testing.T, c Client, policy LogServiceOverWritePolicy) *LogService {
+	var result LogService
+	body := `{"@odata.id": "/redfish/v1/Systems/1/LogServices/SEL", "Id": "SEL", "OverWritePolicy": "` + string(policy) + `",
+		"Entries": {"@odata.id": "/redfish/v1/Systems/1/LogServices/SEL/Entries"}}`
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(c)
+	return &result
+}
+
+func logEntryIDs(entries []*LogEntry) string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+// TestLogTailerPoll tests only new entries are returned, oldest first.
+func TestLogTailerPoll(t *testing.T) {
+	for _, newestFirst := range []bool{false, true} {
+		client := &fakeLogClient{TestClient: &TestClient{}, newestFirst: newestFirst}
+		client.add(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+		tailer := tailLogService(t, client).NewLogTailer(&LogTailOptions{PageSize: 2})
+
+		entries, err := tailer.Poll()
+		if err != nil {
+			t.Fatalf("Error polling: %s", err)
+		}
+		AssertEqual(t, 0, len(entries))
+		AssertEqual(t, "10", tailer.Cursor().LastID)
+
+		client.add(11, 12, 13)
+		client.requests = nil
+		entries, err = tailer.Poll()
+		if err != nil {
+			t.Fatalf("Error polling: %s", err)
+		}
+		AssertEqual(t, "11,12,13", logEntryIDs(entries))
+		AssertEqual(t, 13, tailer.Cursor().Count)
+		// The probe plus two pages, not the whole log
+		AssertEqual(t, 3, len(client.requests))
+
+		entries, err = tailer.Poll()
+		if err != nil {
+			t.Fatalf("Error polling: %s", err)
+		}
+		AssertEqual(t, 0, len(entries))
+	}
+}
+
+// TestLogTailerCleared tests a cleared log is detected.
+func TestLogTailerCleared(t *testing.T) {
+	client := &fakeLogClient{TestClient: &TestClient{}}
+	client.add(1, 2, 3, 4, 5)
+
+	var resets []LogResetReason
+	tailer := tailLogService(t, client).NewLogTailer(&LogTailOptions{
+		Cursor:  &LogCursor{LastID: "5", LastCreated: "2024-01-01T00:05:00Z", Count: 5},
+		OnReset: func(reason LogResetReason) { resets = append(resets, reason) },
+	})
+
+	client.ids = nil
+	client.add(20, 21)
+	entries, err := tailer.Poll()
+	if err != nil {
+		t.Fatalf("Error polling: %s", err)
+	}
+	AssertEqual(t, "20,21", logEntryIDs(entries))
+	AssertEqual(t, 1, len(resets))
+	AssertEqual(t, ClearedLogResetReason, resets[0])
+}
+
+// TestLogTailerFilter tests entries are requested from the cursor's creation
+// time, and entries created in the same second are only returned once.
+func TestLogTailerFilter(t *testing.T) {
+	client := &fakeLogClient{TestClient: &TestClient{}, created: map[int]string{
+		5: "2024-01-01T00:05:00Z", 6: "2024-01-01T00:05:00Z", 7: "2024-01-01T00:05:00Z",
+	}}
+	client.add(4, 5, 6, 7)
+
+	var resets []LogResetReason
+	tailer := tailLogService(t, client).NewLogTailer(&LogTailOptions{
+		UseFilter: true,
+		Cursor:    &LogCursor{LastID: "6", LastCreated: "2024-01-01T00:05:00Z", Count: 3},
+		OnReset:   func(reason LogResetReason) { resets = append(resets, reason) },
+	})
+
+	client.requests = nil
+	entries, err := tailer.Poll()
+	if err != nil {
+		t.Fatalf("Error polling: %s", err)
+	}
+	AssertEqual(t, "7", logEntryIDs(entries))
+	AssertEqual(t, "/redfish/v1/Systems/1/LogServices/SEL/Entries?$filter=Created%20ge%20%272024-01-01T00:05:00Z%27",
+		client.requests[1])
+	AssertEqual(t, 0, len(resets))
+}
+
+// TestLogTailerNeverOverWrites tests a log that never overwrites entries is
+// reported as cleared, even with more entries than before.
+func TestLogTailerNeverOverWrites(t *testing.T) {
+	client := &fakeLogClient{TestClient: &TestClient{}}
+	client.add(20, 21, 22, 23, 24, 25)
+
+	var resets []LogResetReason
+	tailer := tailLogServiceWithPolicy(t, client, NeverOverWritesLogServiceOverWritePolicy).NewLogTailer(&LogTailOptions{
+		Cursor:  &LogCursor{LastID: "5", LastCreated: "2024-01-01T00:05:00Z", Count: 5},
+		OnReset: func(reason LogResetReason) { resets = append(resets, reason) },
+	})
+	if _, err := tailer.Poll(); err != nil {
+		t.Fatalf("Error polling: %s", err)
+	}
+	AssertEqual(t, []LogResetReason{ClearedLogResetReason}, resets)
+}
+
+// TestLogServiceTailRetries tests polls failing with transient errors are
+// retried, and Tail gives up once the retries are used up.
+func TestLogServiceTailRetries(t *testing.T) {
+	client := &fakeLogClient{TestClient: &TestClient{}, failures: 2}
+	client.add(1, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	entries := make(chan *LogEntry, 2)
+	go func() {
+		for len(entries) < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+	}()
+	_, err := tailLogService(t, client).Tail(ctx, entries, &LogTailOptions{FromStart: true, PollRate: time.Millisecond})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the tail to be cancelled after the retries, got: %v", err)
+	}
+	AssertEqual(t, 2, len(entries))
+
+	client.failures = 2
+	_, err = tailLogService(t, client).Tail(context.Background(), entries,
+		&LogTailOptions{PollRate: time.Millisecond, MaxRetries: 1})
+	RequireErrorContains(t, err, "503")
+}
+
+// TestLogServiceTail tests entries are streamed until the context ends.
+func TestLogServiceTail(t *testing.T) {
+	client := &fakeLogClient{TestClient: &TestClient{}}
+	client.add(1, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	entries := make(chan *LogEntry)
+	done := make(chan *LogCursor)
+	go func() {
+		cursor, _ := tailLogService(t, client).Tail(ctx, entries, &LogTailOptions{FromStart: true, PollRate: time.Millisecond})
+		done <- cursor
+	}()
+
+	var ids []string
+	for len(ids) < 3 {
+		entry := <-entries
+		ids = append(ids, entry.ID)
+		if len(ids) == 2 {
+			client.add(3)
+		}
+	}
+	cancel()
+	cursor := <-done
+
+	AssertEqual(t, "1,2,3", strings.Join(ids, ","))
+	AssertEqual(t, "3", cursor.LastID)
+}
@@ -676,13 +676,16 @@ func (l *LogEntry) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (l *LogEntry) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (l *LogEntry) writableFields() []string {
+	return []string{
 		"Resolved",
 	}
+}
 
-	return l.UpdateFromRawData(l, l.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (l *LogEntry) Update() error {
+	return l.UpdateFromRawData(l, l.RawData, l.writableFields())
 }
 
 // GetLogEntry will get a LogEntry instance from the service.
@@ -349,17 +349,20 @@ func (l *LogService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (l *LogService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (l *LogService) writableFields() []string {
+	return []string{
 		"AutoClearResolvedEntries",
 		"AutoDSTEnabled",
 		"DateTime",
 		"DateTimeLocalOffset",
 		"ServiceEnabled",
 	}
+}
 
-	return l.UpdateFromRawData(l, l.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (l *LogService) Update() error {
+	return l.UpdateFromRawData(l, l.RawData, l.writableFields())
 }
 
 // GetLogService will get a LogService instance from the service.
@@ -541,9 +541,9 @@ func (m *Manager) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (m *Manager) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (m *Manager) writableFields() []string {
+	return []string{
 		"AutoDSTEnabled",
 		"DateTime",
 		"DateTimeLocalOffset",
@@ -554,8 +554,11 @@ func (m *Manager) Update() error {
 		"ServiceUseNotification",
 		"TimeZoneName",
 	}
+}
 
-	return m.UpdateFromRawData(m, m.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (m *Manager) Update() error {
+	return m.UpdateFromRawData(m, m.RawData, m.writableFields())
 }
 
 // GetManager will get a Manager instance from the service.
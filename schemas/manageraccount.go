@@ -248,9 +248,9 @@ func (m *ManagerAccount) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (m *ManagerAccount) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (m *ManagerAccount) writableFields() []string {
+	return []string{
 		"AccountExpiration",
 		"AccountTypes",
 		"EmailAddress",
@@ -266,8 +266,11 @@ func (m *ManagerAccount) Update() error {
 		"StrictAccountTypes",
 		"UserName",
 	}
+}
 
-	return m.UpdateFromRawData(m, m.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (m *ManagerAccount) Update() error {
+	return m.UpdateFromRawData(m, m.RawData, m.writableFields())
 }
 
 // GetManagerAccount will get a ManagerAccount instance from the service.
@@ -660,9 +660,9 @@ func (m *Memory) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (m *Memory) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (m *Memory) writableFields() []string {
+	return []string{
 		"Enabled",
 		"LocationIndicatorActive",
 		"NonVolatileSizeLimitMiB",
@@ -671,8 +671,11 @@ func (m *Memory) Update() error {
 		"SecurityState",
 		"VolatileSizeLimitMiB",
 	}
+}
 
-	return m.UpdateFromRawData(m, m.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (m *Memory) Update() error {
+	return m.UpdateFromRawData(m, m.RawData, m.writableFields())
 }
 
 // GetMemory will get a Memory instance from the service.
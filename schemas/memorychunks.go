@@ -136,15 +136,18 @@ func (m *MemoryChunks) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (m *MemoryChunks) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (m *MemoryChunks) writableFields() []string {
+	return []string{
 		"DisplayName",
 		"MediaLocation",
 		"RequestedOperationalState",
 	}
+}
 
-	return m.UpdateFromRawData(m, m.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (m *MemoryChunks) Update() error {
+	return m.UpdateFromRawData(m, m.RawData, m.writableFields())
 }
 
 // GetMemoryChunks will get a MemoryChunks instance from the service.
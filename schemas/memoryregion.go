@@ -104,14 +104,17 @@ func (m *MemoryRegion) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (m *MemoryRegion) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (m *MemoryRegion) writableFields() []string {
+	return []string{
 		"BlockSizeMiB",
 		"SanitizeOnRelease",
 	}
+}
 
-	return m.UpdateFromRawData(m, m.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (m *MemoryRegion) Update() error {
+	return m.UpdateFromRawData(m, m.RawData, m.writableFields())
 }
 
 // GetMemoryRegion will get a MemoryRegion instance from the service.
@@ -0,0 +1,61 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FormatRegistryMessage fills in the '%1', '%2', ... placeholders of a message
+// registry message with the given arguments.
+func FormatRegistryMessage(message string, args []string) string {
+	// Replace the highest numbered placeholders first so '%1' doesn't match
+	// the start of '%10'.
+	for i := len(args); i > 0; i-- {
+		message = strings.ReplaceAll(message, "%"+strconv.Itoa(i), args[i-1])
+	}
+	return message
+}
+
+// NewMessageResolver returns a function that resolves the text of a message
+// using the message registries found in the registries collection at link, in
+// the given language. The message's own text is returned if it has any. The
+// registries are only retrieved the first time a message needs resolving. If
+// the message can't be resolved its MessageId is returned.
+func NewMessageResolver(c Client, link, language string) func(Message) string {
+	var once sync.Once
+	var registries []*MessageRegistry
+
+	return func(m Message) string {
+		if m.Message != "" {
+			return m.Message
+		}
+
+		parts := strings.Split(m.MessageID, ".")
+		if len(parts) != MessageIDSectionLength {
+			return m.MessageID
+		}
+
+		once.Do(func() {
+			// Resolving is best effort, without registries the
+			// MessageId is used instead
+			registries, _ = ListReferencedMessageRegistriesByLanguage(c, link, language)
+		})
+
+		version := parts[1] + "." + parts[2]
+		for _, mr := range registries {
+			if mr.RegistryPrefix != parts[0] || !strings.HasPrefix(mr.RegistryVersion, version) {
+				continue
+			}
+			if msg, ok := mr.Messages[parts[3]]; ok {
+				return FormatRegistryMessage(msg.Message, m.MessageArgs)
+			}
+		}
+
+		return m.MessageID
+	}
+}
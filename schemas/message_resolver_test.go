@@ -0,0 +1,42 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestFormatRegistryMessage tests filling in message arguments.
+func TestFormatRegistryMessage(t *testing.T) {
+	args := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	result := FormatRegistryMessage("%1 then %10 then %2", args)
+	AssertEqual(t, "a then j then b", result)
+}
+
+// TestNewMessageResolver tests resolving messages from the message registries.
+func TestNewMessageResolver(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				getCall(`{"Members": [{"@odata.id": "/redfish/v1/Registries/Base"}]}`),
+				getCall(`{"@odata.id": "/redfish/v1/Registries/Base", "Id": "Base", "Registry": "Base.1.8",
+					"Location": [{"Language": "en", "Uri": "/redfish/v1/Registries/Base/Base.json"}]}`),
+				getCall(`{"Id": "Base.1.8.0", "RegistryPrefix": "Base", "RegistryVersion": "1.8.0",
+					"Messages": {"PropertyValueNotInList": {"Message": "The value %1 for the property %2 is not in the list of acceptable values."}}}`),
+			},
+		},
+	}
+
+	resolve := NewMessageResolver(testClient, "/redfish/v1/Registries", "en")
+
+	AssertEqual(t, "Already resolved", resolve(Message{Message: "Already resolved"}))
+	AssertEqual(t, "The value Red for the property IndicatorLED is not in the list of acceptable values.",
+		resolve(Message{MessageID: "Base.1.8.PropertyValueNotInList", MessageArgs: []string{"Red", "IndicatorLED"}}))
+	AssertEqual(t, "Base.1.8.Unknown", resolve(Message{MessageID: "Base.1.8.Unknown"}))
+
+	// Registries should only be retrieved once
+	AssertEqual(t, 3, len(testClient.CapturedCalls()))
+}
@@ -0,0 +1,482 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stmcginnis/gofish/internal/exposition"
+)
+
+// MetricSample is a single value of a time series.
+type MetricSample struct {
+	// Timestamp is the time the value was obtained. It is zero if neither the
+	// metric value nor the report had a timestamp.
+	Timestamp time.Time
+	// Value is the metric value. Boolean metrics are 1 for true and 0 for false.
+	Value float64
+}
+
+// TimeSeries is the set of samples of one metric property in a metric report.
+type TimeSeries struct {
+	// Name is the MetricId of the metric, or a name derived from the
+	// MetricProperty if the service did not provide one.
+	Name string
+	// Unit is the UCUM unit of the metric from its MetricDefinition, if known.
+	Unit string
+	// Labels identify the series. They contain the report Id as "report", the
+	// resource URI of the MetricProperty as "resource", the JSON pointer of the
+	// property as "property", and the value of each wildcard substituted in
+	// the MetricProperty keyed by the wildcard name.
+	Labels map[string]string
+	// Samples are the values of the series in the order they were reported.
+	Samples []MetricSample
+}
+
+// labelKey returns a key uniquely identifying the name and labels.
+func (s *TimeSeries) labelKey() string {
+	var sb strings.Builder
+	sb.WriteString(s.Name)
+	for _, name := range sortedKeys(s.Labels) {
+		fmt.Fprintf(&sb, "\x00%s=%s", name, s.Labels[name])
+	}
+	return sb.String()
+}
+
+// TimeSeriesConverter converts metric reports to time series. Metric and
+// metric report definitions are read from the service to get the units and
+// wildcards of the metrics, and cached for later reports.
+type TimeSeriesConverter struct {
+	client Client
+
+	mu                sync.Mutex
+	definitions       map[string]*MetricDefinition
+	reportDefinitions map[string]*MetricReportDefinition
+}
+
+// NewTimeSeriesConverter creates a converter. If c is nil no definitions are
+// read, so series have no units and wildcard labels, which is useful for
+// reports received from event streams without access to the service.
+func NewTimeSeriesConverter(c Client) *TimeSeriesConverter {
+	return &TimeSeriesConverter{
+		client:            c,
+		definitions:       make(map[string]*MetricDefinition),
+		reportDefinitions: make(map[string]*MetricReportDefinition),
+	}
+}
+
+// AddMetricDefinition adds a definition to the cache, for example for a
+// converter without a client.
+func (tc *TimeSeriesConverter) AddMetricDefinition(definition *MetricDefinition) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.definitions[definition.ID] = definition
+}
+
+// AddMetricReportDefinition adds a report definition to the cache, for
+// example for a converter without a client.
+func (tc *TimeSeriesConverter) AddMetricReportDefinition(definition *MetricReportDefinition) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.reportDefinitions[definition.ID] = definition
+}
+
+// TimeSeries converts the metric report to time series using the client the
+// report was read with.
+func (m *MetricReport) TimeSeries() ([]*TimeSeries, error) {
+	return NewTimeSeriesConverter(m.GetClient()).Convert(m)
+}
+
+// Convert converts the metric values of a report to time series, grouping the
+// values of each metric property. Values that are not numbers or booleans,
+// such as "null" or the JSON arrays of array metrics, are skipped.
+func (tc *TimeSeriesConverter) Convert(report *MetricReport) ([]*TimeSeries, error) {
+	reportDefinition, err := tc.reportDefinition(report)
+	if err != nil {
+		return nil, err
+	}
+
+	reportTime := parseMetricTime(report.Timestamp)
+	var result []*TimeSeries
+	series := make(map[string]*TimeSeries)
+	for i := range report.MetricValues {
+		value := &report.MetricValues[i]
+		sample, ok := parseMetricSample(value.MetricValue)
+		if !ok {
+			continue
+		}
+		sample.Timestamp = reportTime
+		if value.Timestamp != "" {
+			sample.Timestamp = parseMetricTime(value.Timestamp)
+		}
+
+		definition, err := tc.metricDefinition(report, value)
+		if err != nil {
+			return nil, err
+		}
+
+		s := &TimeSeries{
+			Name:   value.MetricID,
+			Labels: metricLabels(report, reportDefinition, definition, value),
+		}
+		if s.Name == "" {
+			s.Name = metricPropertyName(value.MetricProperty)
+		}
+		if definition != nil {
+			s.Unit = definition.Units
+		}
+
+		key := s.labelKey()
+		if existing, ok := series[key]; ok {
+			s = existing
+		} else {
+			series[key] = s
+			result = append(result, s)
+		}
+		s.Samples = append(s.Samples, sample)
+	}
+
+	return result, nil
+}
+
+// reportDefinition gets the definition of the report, or nil if it is not
+// known.
+func (tc *TimeSeriesConverter) reportDefinition(report *MetricReport) (*MetricReportDefinition, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	id := report.ID
+	if report.metricReportDefinition != "" {
+		id = path.Base(report.metricReportDefinition)
+	}
+	if definition, ok := tc.reportDefinitions[id]; ok || tc.client == nil || report.metricReportDefinition == "" {
+		return definition, nil
+	}
+
+	definition, err := GetObject[MetricReportDefinition](tc.client, report.metricReportDefinition)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	tc.reportDefinitions[id] = definition
+	return definition, nil
+}
+
+// metricDefinition gets the definition of a metric value, or nil if it is not
+// known.
+func (tc *TimeSeriesConverter) metricDefinition(report *MetricReport, value *MetricValue) (*MetricDefinition, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	id := value.MetricID
+	if id == "" && value.metricDefinition != "" {
+		id = path.Base(value.metricDefinition)
+	}
+	if id == "" {
+		return nil, nil
+	}
+	if definition, ok := tc.definitions[id]; ok || tc.client == nil {
+		return definition, nil
+	}
+
+	uri := value.metricDefinition
+	if uri == "" {
+		uri = metricDefinitionURI(report, id)
+	}
+	definition, err := GetObject[MetricDefinition](tc.client, uri)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	// Cache missing definitions too, many services don't implement them
+	tc.definitions[id] = definition
+	return definition, nil
+}
+
+// metricDefinitionURI guesses the URI of a metric definition from the URI of
+// the report, as they are both in the TelemetryService.
+func metricDefinitionURI(report *MetricReport, id string) string {
+	service := "/redfish/v1/TelemetryService"
+	if report.ODataID != "" {
+		service = path.Dir(path.Dir(report.ODataID))
+	} else if report.metricReportDefinition != "" {
+		service = path.Dir(path.Dir(report.metricReportDefinition))
+	}
+	return service + "/MetricDefinitions/" + id
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func isNotFound(err error) bool {
+	var redfishErr *Error
+	return errors.As(err, &redfishErr) && redfishErr.HTTPReturnedStatusCode == http.StatusNotFound
+}
+
+// metricLabels gets the labels of a metric value.
+func metricLabels(report *MetricReport, reportDefinition *MetricReportDefinition,
+	definition *MetricDefinition, value *MetricValue) map[string]string {
+	resource, property, _ := strings.Cut(value.MetricProperty, "#")
+	labels := map[string]string{
+		"report":   report.ID,
+		"resource": resource,
+		"property": property,
+	}
+
+	// The wildcard values are found by matching the MetricProperty against
+	// the templates the metric was defined with
+	var templates []string
+	if definition != nil {
+		templates = append(templates, definition.MetricProperties...)
+	}
+	if reportDefinition != nil {
+		for i := range reportDefinition.Metrics {
+			metric := &reportDefinition.Metrics[i]
+			if metric.MetricID == "" || metric.MetricID == value.MetricID {
+				templates = append(templates, metric.MetricProperties...)
+			}
+		}
+	}
+	for _, template := range templates {
+		if wildcards := matchMetricProperty(template, value.MetricProperty); wildcards != nil {
+			for name, wildcard := range wildcards {
+				if _, ok := labels[name]; !ok {
+					labels[name] = wildcard
+				}
+			}
+			break
+		}
+	}
+
+	for name, v := range labels {
+		if v == "" {
+			delete(labels, name)
+		}
+	}
+	return labels
+}
+
+// matchMetricProperty matches a MetricProperty against a template with
+// wildcards, returning the values of the wildcards or nil if it doesn't
+// match.
+func matchMetricProperty(template, property string) map[string]string {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	var names []string
+	last := 0
+	for _, loc := range wildcardPattern.FindAllStringSubmatchIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		pattern.WriteString("([^/#]+)")
+		names = append(names, template[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil
+	}
+	match := re.FindStringSubmatch(property)
+	if match == nil {
+		return nil
+	}
+	wildcards := make(map[string]string, len(names))
+	for i, name := range names {
+		wildcards[name] = match[i+1]
+	}
+	return wildcards
+}
+
+// metricPropertyName derives a metric name from the non-index segments of the
+// JSON pointer of a MetricProperty, such as "Fans_Reading" for
+// "/redfish/v1/Chassis/1/Thermal#/Fans/0/Reading".
+func metricPropertyName(property string) string {
+	_, pointer, found := strings.Cut(property, "#")
+	if !found {
+		return path.Base(property)
+	}
+	var segments []string
+	for _, segment := range strings.Split(pointer, "/") {
+		if _, err := strconv.Atoi(segment); err == nil || segment == "" {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	return strings.Join(segments, "_")
+}
+
+// parseMetricSample parses a MetricValue, which is always a string.
+func parseMetricSample(value string) (MetricSample, bool) {
+	switch value {
+	case "true":
+		return MetricSample{Value: 1}, true
+	case "false":
+		return MetricSample{Value: 0}, true
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(v) {
+		return MetricSample{}, false
+	}
+	return MetricSample{Value: v}, true
+}
+
+func parseMetricTime(timestamp string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// openMetricsUnits maps UCUM units to the OpenMetrics base unit names used as
+// metric name suffixes.
+var openMetricsUnits = map[string]string{
+	"A":   "amperes",
+	"By":  "bytes",
+	"Cel": "celsius",
+	"Hz":  "hertz",
+	"J":   "joules",
+	"s":   "seconds",
+	"V":   "volts",
+	"W":   "watts",
+	"%":   "percent",
+}
+
+var invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// openMetricsName converts a name to a valid OpenMetrics metric or label name.
+func openMetricsName(name string) string {
+	name = invalidMetricNameChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// ExpositionOptions are the options for writing time series in the
+// OpenMetrics or Prometheus text formats.
+type ExpositionOptions struct {
+	// Namespace is prefixed to the metric names, separated by an underscore.
+	// The default is "redfish".
+	Namespace string
+	// OmitTimestamps leaves out the sample timestamps, so the scraper uses the
+	// scrape time.
+	OmitTimestamps bool
+}
+
+// WriteOpenMetrics writes time series in the OpenMetrics text format. Series
+// are written as gauges, with the OpenMetrics unit appended to the name for
+// known UCUM units. Only the newest sample of each series is written.
+func WriteOpenMetrics(w io.Writer, series []*TimeSeries, opts *ExpositionOptions) error {
+	return writeExposition(w, series, opts, true)
+}
+
+// WritePrometheusText writes time series in the Prometheus text exposition
+// format. Series are written as gauges, with the unit appended to the name for
+// known UCUM units. Only the newest sample of each series is written.
+func WritePrometheusText(w io.Writer, series []*TimeSeries, opts *ExpositionOptions) error {
+	return writeExposition(w, series, opts, false)
+}
+
+func writeExposition(w io.Writer, series []*TimeSeries, opts *ExpositionOptions, openMetrics bool) error {
+	if opts == nil {
+		opts = &ExpositionOptions{}
+	}
+	namespace := opts.Namespace
+	if namespace == "" {
+		namespace = "redfish"
+	}
+
+	// Samples of a metric must be grouped together
+	families := make(map[string]*exposition.Family)
+	for _, s := range series {
+		name := openMetricsName(namespace + "_" + s.Name)
+		unit := openMetricsUnits[s.Unit]
+		if unit != "" && !strings.HasSuffix(name, "_"+unit) {
+			name += "_" + unit
+		}
+		family, ok := families[name]
+		if !ok {
+			family = &exposition.Family{Name: name, Type: "gauge", Unit: unit}
+			families[name] = family
+		}
+
+		labels := make([]exposition.Label, 0, len(s.Labels))
+		for _, label := range sortedKeys(s.Labels) {
+			labels = append(labels, exposition.Label{Name: openMetricsName(label), Value: s.Labels[label]})
+		}
+		for _, sample := range s.Samples {
+			family.Samples = append(family.Samples,
+				exposition.Sample{Labels: labels, Value: sample.Value, Timestamp: sample.Timestamp})
+		}
+	}
+
+	ew := exposition.NewWriter(w)
+	ew.OpenMetrics = openMetrics
+	ew.OmitTimestamps = opts.OmitTimestamps
+	for _, name := range sortedKeys(families) {
+		ew.WriteFamily(families[name])
+	}
+	ew.WriteEOF()
+	_, err := ew.Result()
+	return err
+}
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	influxTagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+)
+
+// WriteInfluxLineProtocol writes time series in the InfluxDB line protocol.
+// Each series is written as a measurement named after the metric with a
+// "value" field, its labels as tags, and a "unit" tag if the unit is known.
+// Timestamps are in nanoseconds.
+func WriteInfluxLineProtocol(w io.Writer, series []*TimeSeries) error {
+	for _, s := range series {
+		var sb strings.Builder
+		sb.WriteString(influxMeasurementEscaper.Replace(s.Name))
+		tags := maps.Clone(s.Labels)
+		if s.Unit != "" {
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+			tags["unit"] = s.Unit
+		}
+		// Tags should be sorted by key for the best performance
+		for _, name := range sortedKeys(tags) {
+			fmt.Fprintf(&sb, ",%s=%s", influxTagEscaper.Replace(name), influxTagEscaper.Replace(tags[name]))
+		}
+		prefix := sb.String()
+
+		for _, sample := range s.Samples {
+			line := prefix + " value=" + strconv.FormatFloat(sample.Value, 'g', -1, 64)
+			if !sample.Timestamp.IsZero() {
+				line += " " + strconv.FormatInt(sample.Timestamp.UnixNano(), 10)
+			}
+			if _, err := io.WriteString(w, line+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var timeSeriesReportBody = `{
+		"@odata.id": "/redfish/v1/TelemetryService/MetricReports/PowerMetrics",
+		"Id": "PowerMetrics",
+		"Timestamp": "2024-05-01T08:00:00Z",
+		"MetricReportDefinition": {"@odata.id": "/redfish/v1/TelemetryService/MetricReportDefinitions/PowerMetrics"},
+		"MetricValues": [{
+			"MetricId": "PowerConsumedWatts",
+			"MetricValue": "350",
+			"MetricProperty": "/redfish/v1/Chassis/1/Power#/PowerControl/0/PowerConsumedWatts",
+			"Timestamp": "2024-05-01T07:59:00Z"
+		}, {
+			"MetricId": "PowerConsumedWatts",
+			"MetricValue": "120.5",
+			"MetricProperty": "/redfish/v1/Chassis/2/Power#/PowerControl/0/PowerConsumedWatts"
+		}, {
+			"MetricId": "PowerConsumedWatts",
+			"MetricValue": "360",
+			"MetricProperty": "/redfish/v1/Chassis/1/Power#/PowerControl/0/PowerConsumedWatts",
+			"Timestamp": "2024-05-01T08:00:00Z"
+		}, {
+			"MetricId": "PowerConsumedWatts",
+			"MetricValue": "null",
+			"MetricProperty": "/redfish/v1/Chassis/3/Power#/PowerControl/0/PowerConsumedWatts"
+		}, {
+			"MetricId": "PowerLimited",
+			"MetricValue": "true",
+			"MetricProperty": "/redfish/v1/Chassis/1/Power#/PowerControl/0/PowerLimit/Enabled"
+		}]
+	}`
+
+func timeSeriesReport(t *testing.T, c Client) *MetricReport {
+	var result MetricReport
+	if err := json.Unmarshal([]byte(timeSeriesReportBody), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(c)
+	return &result
+}
+
+// TestMetricReportTimeSeries tests metric values are grouped into typed
+// series with units and wildcard labels from the definitions.
+func TestMetricReportTimeSeries(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				getCall(`{"@odata.id": "/redfish/v1/TelemetryService/MetricReportDefinitions/PowerMetrics", "Id": "PowerMetrics",
+					"Metrics": [{"MetricProperties": ["/redfish/v1/Chassis/{ChassisID}/Power#/PowerControl/0/PowerLimit/Enabled"]}]}`),
+				getCall(`{"@odata.id": "/redfish/v1/TelemetryService/MetricDefinitions/PowerConsumedWatts", "Id": "PowerConsumedWatts",
+					"Units": "W", "MetricProperties": ["/redfish/v1/Chassis/{Chassis}/Power#/PowerControl/{Index}/PowerConsumedWatts"]}`),
+				errorCall(http.StatusNotFound),
+			},
+		},
+	}
+
+	series, err := timeSeriesReport(t, testClient).TimeSeries()
+	if err != nil {
+		t.Fatalf("Error converting report: %s", err)
+	}
+	AssertEqual(t, 3, len(series))
+
+	power := series[0]
+	AssertEqual(t, "PowerConsumedWatts", power.Name)
+	AssertEqual(t, "W", power.Unit)
+	AssertEqual(t, "1", power.Labels["Chassis"])
+	AssertEqual(t, "0", power.Labels["Index"])
+	AssertEqual(t, "PowerMetrics", power.Labels["report"])
+	AssertEqual(t, "/redfish/v1/Chassis/1/Power", power.Labels["resource"])
+	AssertEqual(t, "/PowerControl/0/PowerConsumedWatts", power.Labels["property"])
+	AssertEqual(t, 2, len(power.Samples))
+	AssertEqual(t, 360.0, power.Samples[1].Value)
+	AssertEqual(t, time.Date(2024, 5, 1, 7, 59, 0, 0, time.UTC), power.Samples[0].Timestamp)
+
+	// Values without a timestamp use the time of the report
+	AssertEqual(t, "2", series[1].Labels["Chassis"])
+	AssertEqual(t, 120.5, series[1].Samples[0].Value)
+	AssertEqual(t, time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC), series[1].Samples[0].Timestamp)
+
+	limited := series[2]
+	AssertEqual(t, "", limited.Unit)
+	AssertEqual(t, "1", limited.Labels["ChassisID"])
+	AssertEqual(t, 1.0, limited.Samples[0].Value)
+
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, 3, len(calls))
+	AssertEqual(t, "/redfish/v1/TelemetryService/MetricDefinitions/PowerLimited", calls[2].URL)
+}
+
+// TestTimeSeriesConverterCache tests definitions are read once and reports
+// can be converted without a client.
+func TestTimeSeriesConverterCache(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				errorCall(http.StatusNotFound),
+				getCall(`{"Id": "PowerConsumedWatts", "Units": "W"}`),
+				errorCall(http.StatusNotFound),
+			},
+		},
+	}
+	converter := NewTimeSeriesConverter(testClient)
+	for i := 0; i < 2; i++ {
+		if _, err := converter.Convert(timeSeriesReport(t, nil)); err != nil {
+			t.Fatalf("Error converting report: %s", err)
+		}
+	}
+	AssertEqual(t, 3, len(testClient.CapturedCalls()))
+
+	testClient = &TestClient{
+		CustomReturnForActions: map[string][]any{http.MethodGet: {errorCall(http.StatusInternalServerError)}},
+	}
+	_, err := NewTimeSeriesConverter(testClient).Convert(timeSeriesReport(t, nil))
+	RequireErrorContains(t, err, "Internal Server Error")
+
+	streamed := timeSeriesReport(t, nil)
+	streamed.MetricValues[0].MetricID = ""
+	converter = NewTimeSeriesConverter(nil)
+	converter.AddMetricDefinition(&MetricDefinition{Entity: Entity{ID: "PowerConsumedWatts"}, Units: "W"})
+	series, err := converter.Convert(streamed)
+	if err != nil {
+		t.Fatalf("Error converting report: %s", err)
+	}
+	AssertEqual(t, "PowerControl_PowerConsumedWatts", series[0].Name)
+	AssertEqual(t, "", series[0].Unit)
+	AssertEqual(t, "W", series[1].Unit)
+}
+
+// TestWriteTimeSeries tests the exposition and line protocol writers.
+func TestWriteTimeSeries(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 8, 0, 0, 500000000, time.UTC)
+	series := []*TimeSeries{{
+		Name:    "PowerConsumedWatts",
+		Unit:    "W",
+		Labels:  map[string]string{"Chassis": "1", "resource": "/redfish/v1/Chassis/1/Power"},
+		Samples: []MetricSample{{Timestamp: timestamp, Value: 350}},
+	}, {
+		Name:    "Fan Speed",
+		Labels:  map[string]string{"fan": `Fan "1", left`},
+		Samples: []MetricSample{{Value: 4200}},
+	}}
+
+	var sb strings.Builder
+	if err := WriteOpenMetrics(&sb, series, nil); err != nil {
+		t.Fatalf("Error writing OpenMetrics: %s", err)
+	}
+	AssertEqual(t, `# TYPE redfish_Fan_Speed gauge
+redfish_Fan_Speed{fan="Fan \"1\", left"} 4200
+# TYPE redfish_PowerConsumedWatts_watts gauge
+# UNIT redfish_PowerConsumedWatts_watts watts
+redfish_PowerConsumedWatts_watts{Chassis="1",resource="/redfish/v1/Chassis/1/Power"} 350 1714550400.5
+# EOF
+`, sb.String())
+
+	sb.Reset()
+	if err := WritePrometheusText(&sb, series[:1], &ExpositionOptions{Namespace: "bmc"}); err != nil {
+		t.Fatalf("Error writing Prometheus text: %s", err)
+	}
+	AssertEqual(t, `# TYPE bmc_PowerConsumedWatts_watts gauge
+bmc_PowerConsumedWatts_watts{Chassis="1",resource="/redfish/v1/Chassis/1/Power"} 350 1714550400500
+`, sb.String())
+
+	// Only the newest sample of a label set is written
+	duplicated := []*TimeSeries{{
+		Name:    "Reading",
+		Labels:  map[string]string{"sensor": "1"},
+		Samples: []MetricSample{{Timestamp: timestamp, Value: 1}, {Timestamp: timestamp.Add(time.Minute), Value: 2}},
+	}, {
+		Name:    "Reading",
+		Labels:  map[string]string{"sensor": "1"},
+		Samples: []MetricSample{{Timestamp: timestamp.Add(-time.Minute), Value: 3}},
+	}, {
+		Name:    "Reading",
+		Labels:  map[string]string{"sensor": "2"},
+		Samples: []MetricSample{{Timestamp: timestamp, Value: 4}},
+	}}
+	sb.Reset()
+	if err := WritePrometheusText(&sb, duplicated, nil); err != nil {
+		t.Fatalf("Error writing Prometheus text: %s", err)
+	}
+	AssertEqual(t, `# TYPE redfish_Reading gauge
+redfish_Reading{sensor="1"} 2 1714550460500
+redfish_Reading{sensor="2"} 4 1714550400500
+`, sb.String())
+
+	sb.Reset()
+	if err := WriteInfluxLineProtocol(&sb, series); err != nil {
+		t.Fatalf("Error writing line protocol: %s", err)
+	}
+	AssertEqual(t, `PowerConsumedWatts,Chassis=1,resource=/redfish/v1/Chassis/1/Power,unit=W value=350 1714550400500000000
+Fan\ Speed,fan=Fan\ "1"\,\ left value=4200
+`, sb.String())
+}
@@ -240,9 +240,9 @@ func (m *MetricDefinition) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (m *MetricDefinition) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (m *MetricDefinition) writableFields() []string {
+	return []string{
 		"Calculable",
 		"CalculationTimeInterval",
 		"DiscreteValues",
@@ -253,8 +253,11 @@ func (m *MetricDefinition) Update() error {
 		"SensingInterval",
 		"Units",
 	}
+}
 
-	return m.UpdateFromRawData(m, m.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (m *MetricDefinition) Update() error {
+	return m.UpdateFromRawData(m, m.RawData, m.writableFields())
 }
 
 // GetMetricDefinition will get a MetricDefinition instance from the service.
@@ -221,9 +221,9 @@ func (m *MetricReportDefinition) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (m *MetricReportDefinition) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (m *MetricReportDefinition) writableFields() []string {
+	return []string{
 		"MetricProperties",
 		"MetricReportDefinitionEnabled",
 		"MetricReportDefinitionType",
@@ -233,8 +233,11 @@ func (m *MetricReportDefinition) Update() error {
 		"ReportUpdates",
 		"SuppressRepeatedMetricValue",
 	}
+}
 
-	return m.UpdateFromRawData(m, m.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (m *MetricReportDefinition) Update() error {
+	return m.UpdateFromRawData(m, m.RawData, m.writableFields())
 }
 
 // GetMetricReportDefinition will get a MetricReportDefinition instance from the service.
@@ -170,13 +170,16 @@ func (n *NetworkAdapter) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (n *NetworkAdapter) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (n *NetworkAdapter) writableFields() []string {
+	return []string{
 		"LLDPEnabled",
 	}
+}
 
-	return n.UpdateFromRawData(n, n.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (n *NetworkAdapter) Update() error {
+	return n.UpdateFromRawData(n, n.RawData, n.writableFields())
 }
 
 // GetNetworkAdapter will get a NetworkAdapter instance from the service.
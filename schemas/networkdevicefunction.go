@@ -262,17 +262,20 @@ func (n *NetworkDeviceFunction) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (n *NetworkDeviceFunction) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (n *NetworkDeviceFunction) writableFields() []string {
+	return []string{
 		"BootMode",
 		"DeviceEnabled",
 		"NetDevFuncType",
 		"SAVIEnabled",
 		"VirtualFunctionAllocation",
 	}
+}
 
-	return n.UpdateFromRawData(n, n.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (n *NetworkDeviceFunction) Update() error {
+	return n.UpdateFromRawData(n, n.RawData, n.writableFields())
 }
 
 // GetNetworkDeviceFunction will get a NetworkDeviceFunction instance from the service.
@@ -177,17 +177,20 @@ func (n *NetworkPort) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (n *NetworkPort) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (n *NetworkPort) writableFields() []string {
+	return []string{
 		"ActiveLinkTechnology",
 		"CurrentLinkSpeedMbps",
 		"EEEEnabled",
 		"FlowControlConfiguration",
 		"WakeOnLANEnabled",
 	}
+}
 
-	return n.UpdateFromRawData(n, n.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (n *NetworkPort) Update() error {
+	return n.UpdateFromRawData(n, n.RawData, n.writableFields())
 }
 
 // GetNetworkPort will get a NetworkPort instance from the service.
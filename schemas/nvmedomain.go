@@ -103,13 +103,16 @@ func (n *NVMeDomain) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (n *NVMeDomain) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (n *NVMeDomain) writableFields() []string {
+	return []string{
 		"DomainMembers",
 	}
+}
 
-	return n.UpdateFromRawData(n, n.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (n *NVMeDomain) Update() error {
+	return n.UpdateFromRawData(n, n.RawData, n.writableFields())
 }
 
 // GetNVMeDomain will get a NVMeDomain instance from the service.
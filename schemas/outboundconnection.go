@@ -157,14 +157,17 @@ func (o *OutboundConnection) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (o *OutboundConnection) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (o *OutboundConnection) writableFields() []string {
+	return []string{
 		"ConnectionEnabled",
 		"WebSocketPingIntervalMinutes",
 	}
+}
 
-	return o.UpdateFromRawData(o, o.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (o *OutboundConnection) Update() error {
+	return o.UpdateFromRawData(o, o.RawData, o.writableFields())
 }
 
 // GetOutboundConnection will get a OutboundConnection instance from the service.
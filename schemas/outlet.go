@@ -295,9 +295,9 @@ func (o *Outlet) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (o *Outlet) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (o *Outlet) writableFields() []string {
+	return []string{
 		"ConfigurationLocked",
 		"ElectricalConsumerNames",
 		"IndicatorLED",
@@ -310,8 +310,11 @@ func (o *Outlet) Update() error {
 		"PowerRestorePolicy",
 		"UserLabel",
 	}
+}
 
-	return o.UpdateFromRawData(o, o.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (o *Outlet) Update() error {
+	return o.UpdateFromRawData(o, o.RawData, o.writableFields())
 }
 
 // GetOutlet will get a Outlet instance from the service.
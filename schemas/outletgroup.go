@@ -148,9 +148,9 @@ func (o *OutletGroup) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (o *OutletGroup) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (o *OutletGroup) writableFields() []string {
+	return []string{
 		"ConfigurationLocked",
 		"CreatedBy",
 		"PowerControlLocked",
@@ -160,8 +160,11 @@ func (o *OutletGroup) Update() error {
 		"PowerRestoreDelaySeconds",
 		"PowerRestorePolicy",
 	}
+}
 
-	return o.UpdateFromRawData(o, o.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (o *OutletGroup) Update() error {
+	return o.UpdateFromRawData(o, o.RawData, o.writableFields())
 }
 
 // GetOutletGroup will get a OutletGroup instance from the service.
@@ -0,0 +1,295 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrNotWritable is returned when a patch changes a property that is not
+// writable.
+var ErrNotWritable = errors.New("property is not writable")
+
+// PatchableResource is a resource that can be modified with a PatchBuilder.
+// All resources embedding Entity implement it.
+type PatchableResource interface {
+	GetODataID() string
+	Patch(uri string, payload any) error
+}
+
+// WritableProperties returns the names of the properties of the resource that
+// its Update method allows to be changed. Resources without an Update method
+// have no writable properties.
+func WritableProperties(resource PatchableResource) []string {
+	return writableFields(resource)
+}
+
+// writableFields returns the writable properties generated for the object, if
+// any.
+func writableFields(object any) []string {
+	writable, ok := object.(interface{ writableFields() []string })
+	if !ok {
+		return nil
+	}
+	return writable.writableFields()
+}
+
+// PatchBuilder builds a PATCH request that sets, or clears to null, individual
+// properties of a resource, including properties of nested objects. Each
+// property is checked to exist on the resource and to be writable, either as
+// a property of the resource or as a property of the nested object containing
+// it.
+//
+// Paths use dots to separate nested property names, such as
+// "Boot.HttpBootUri". Because annotations contain dots, a path element
+// starting with "@" is taken to be the rest of the path, such as
+// "@Redfish.SettingsApplyTime".
+type PatchBuilder struct {
+	resource PatchableResource
+	writable []string
+	allowed  []string
+	payload  map[string]any
+	errs     []error
+}
+
+// NewPatchBuilder creates a PatchBuilder for the resource.
+func NewPatchBuilder(resource PatchableResource) *PatchBuilder {
+	return &PatchBuilder{
+		resource: resource,
+		writable: WritableProperties(resource),
+		payload:  make(map[string]any),
+	}
+}
+
+// Allow marks paths, and the properties nested within them, as writable in
+// addition to the writable properties of the resource and its nested objects.
+// OEM properties, whole nested objects, and the properties of objects without
+// writable properties are not known to be writable and need to be allowed
+// before they can be set.
+func (p *PatchBuilder) Allow(paths ...string) *PatchBuilder {
+	p.allowed = append(p.allowed, paths...)
+	return p
+}
+
+// Set sets the property at the path to the value.
+func (p *PatchBuilder) Set(path string, value any) *PatchBuilder {
+	if err := p.put(path, value); err != nil {
+		p.errs = append(p.errs, err)
+	}
+	return p
+}
+
+// Clear sets the property at the path to null.
+func (p *PatchBuilder) Clear(path string) *PatchBuilder {
+	return p.Set(path, nil)
+}
+
+// Unset removes any change to the property at the path, leaving it as it is.
+func (p *PatchBuilder) Unset(path string) *PatchBuilder {
+	elements := splitPatchPath(path)
+	parent := p.payload
+	for _, element := range elements[:len(elements)-1] {
+		child, ok := parent[element].(map[string]any)
+		if !ok {
+			return p
+		}
+		parent = child
+	}
+	delete(parent, elements[len(elements)-1])
+	return p
+}
+
+// Payload returns the PATCH payload, or an error for any invalid changes.
+func (p *PatchBuilder) Payload() (map[string]any, error) {
+	if len(p.errs) > 0 {
+		return nil, errors.Join(p.errs...)
+	}
+	return p.payload, nil
+}
+
+// MarshalJSON returns the JSON encoded PATCH payload.
+func (p *PatchBuilder) MarshalJSON() ([]byte, error) {
+	payload, err := p.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(payload)
+}
+
+// Apply sends the changes to the service with the resource's ETag in an
+// If-Match header. Nothing is sent if there are no changes. The resource is
+// not updated, so should be fetched again to see the result.
+func (p *PatchBuilder) Apply() error {
+	payload, err := p.Payload()
+	if err != nil {
+		return err
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	return p.resource.Patch(p.resource.GetODataID(), payload)
+}
+
+func (p *PatchBuilder) put(path string, value any) error {
+	if path == "" {
+		return errors.New("a property path is required")
+	}
+	elements := splitPatchPath(path)
+
+	if !p.isAllowed(elements) && !p.isWritable(elements) {
+		return fmt.Errorf("%w: %s", ErrNotWritable, path)
+	}
+	if err := checkPropertyPath(reflect.TypeOf(p.resource), elements); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	parent := p.payload
+	for i, element := range elements[:len(elements)-1] {
+		existing, found := parent[element]
+		if !found {
+			child := make(map[string]any)
+			parent[element] = child
+			parent = child
+			continue
+		}
+		child, ok := existing.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s conflicts with the value set for %s", path, strings.Join(elements[:i+1], "."))
+		}
+		parent = child
+	}
+
+	last := elements[len(elements)-1]
+	if existing, ok := parent[last].(map[string]any); ok && len(existing) > 0 {
+		return fmt.Errorf("%s conflicts with the values set for its properties", path)
+	}
+	parent[last] = value
+	return nil
+}
+
+// isAllowed returns whether the path or one of its parents was allowed.
+func (p *PatchBuilder) isAllowed(elements []string) bool {
+	for i := range elements {
+		if slices.Contains(p.allowed, strings.Join(elements[:i+1], ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWritable returns whether the path is a writable property of the resource
+// or of the nested object containing it, or an annotation.
+func (p *PatchBuilder) isWritable(elements []string) bool {
+	last := elements[len(elements)-1]
+	if strings.HasPrefix(last, "@") {
+		return true
+	}
+	if len(elements) == 1 {
+		return slices.Contains(p.writable, last)
+	}
+
+	t, ok := propertyType(reflect.TypeOf(p.resource), elements[:len(elements)-1])
+	if !ok || t.Kind() != reflect.Struct {
+		return false
+	}
+	return slices.Contains(writableFields(reflect.New(t).Interface()), last)
+}
+
+// propertyType returns the type of the property at the path of the type.
+func propertyType(t reflect.Type, elements []string) (reflect.Type, bool) {
+	for _, element := range elements {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return nil, false
+		}
+		field, ok := findJSONField(t, element)
+		if !ok {
+			return nil, false
+		}
+		t = field.Type
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t, true
+}
+
+// splitPatchPath splits a path into its property names.
+func splitPatchPath(path string) []string {
+	var elements []string
+	for path != "" {
+		if strings.HasPrefix(path, "@") {
+			return append(elements, path)
+		}
+		element, rest, _ := strings.Cut(path, ".")
+		elements = append(elements, element)
+		path = rest
+	}
+	return elements
+}
+
+// checkPropertyPath checks the path refers to a property of the type.
+func checkPropertyPath(t reflect.Type, elements []string) error {
+	for i, element := range elements {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		// Annotations and OEM extensions are not modelled
+		if strings.HasPrefix(element, "@") || t == reflect.TypeOf(json.RawMessage{}) {
+			return nil
+		}
+		switch t.Kind() { //nolint:exhaustive
+		case reflect.Map, reflect.Interface:
+			return nil
+		case reflect.Struct:
+		default:
+			return fmt.Errorf("%s is not an object", strings.Join(elements[:i], "."))
+		}
+
+		field, ok := findJSONField(t, element)
+		if !ok {
+			return fmt.Errorf("unknown property %s", strings.Join(elements[:i+1], "."))
+		}
+		t = field.Type
+	}
+	return nil
+}
+
+// findJSONField finds the field of the struct type encoded with the name.
+func findJSONField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if found, ok := findJSONField(embedded, name); ok {
+					return found, true
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if tag == name || (tag == "" && field.Name == name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,98 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func patchBuilderSystem(t *testing.T, testClient *TestClient) *ComputerSystem {
+	var result ComputerSystem
+	body := `{"@odata.id": "/redfish/v1/Systems/1", "@odata.etag": "\"abc\"", "Id": "1",
+		"AssetTag": "rack-1", "Boot": {"HttpBootUri": "http://boot/image.iso"}}`
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(testClient)
+	return &result
+}
+
+// TestWritableProperties tests the writable properties are found without
+// sending an update.
+func TestWritableProperties(t *testing.T) {
+	testClient := &TestClient{}
+	system := patchBuilderSystem(t, testClient)
+	system.AssetTag = "changed"
+
+	writable := WritableProperties(system)
+	if !slices.Contains(writable, "AssetTag") || slices.Contains(writable, "Id") {
+		t.Errorf("Unexpected writable properties: %v", writable)
+	}
+	AssertEqual(t, 0, len(testClient.CapturedCalls()))
+
+	// Update should work normally afterwards
+	if err := system.Update(); err != nil {
+		t.Fatalf("Error updating: %s", err)
+	}
+	AssertEqual(t, 1, len(testClient.CapturedCalls()))
+}
+
+// TestPatchBuilder tests setting and clearing properties.
+func TestPatchBuilder(t *testing.T) {
+	testClient := &TestClient{}
+	system := patchBuilderSystem(t, testClient)
+
+	err := NewPatchBuilder(system).
+		Clear("AssetTag").
+		Clear("Boot.HttpBootUri").
+		Set("@Redfish.SettingsApplyTime", map[string]string{"ApplyTime": "OnReset"}).
+		Apply()
+	if err != nil {
+		t.Fatalf("Error applying patch: %s", err)
+	}
+
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, http.MethodPatch, calls[0].Action)
+	AssertEqual(t, "/redfish/v1/Systems/1", calls[0].URL)
+	AssertEqual(t, `"abc"`, calls[0].CustomHeaders["If-Match"])
+	AssertEqual(t, "map[@Redfish.SettingsApplyTime:map[ApplyTime:OnReset] AssetTag:<nil> Boot:map[HttpBootUri:<nil>]]", calls[0].Payload)
+}
+
+// TestPatchBuilderValidation tests invalid changes are not sent.
+func TestPatchBuilderValidation(t *testing.T) {
+	testClient := &TestClient{}
+	system := patchBuilderSystem(t, testClient)
+
+	payload, err := NewPatchBuilder(system).Set("Boot.HttpBootUri", "http://other").Payload()
+	if err != nil {
+		t.Fatalf("Error building payload: %s", err)
+	}
+	AssertEqual(t, "map[Boot:map[HttpBootUri:http://other]]", fmt.Sprint(payload))
+
+	for _, path := range []string{"Boot.RemainingAutomaticRetryAttempts", "Boot", "Status.State"} {
+		err = NewPatchBuilder(system).Set(path, nil).Apply()
+		if !errors.Is(err, ErrNotWritable) {
+			t.Errorf("Expected not writable error for %s, got: %v", path, err)
+		}
+	}
+
+	err = NewPatchBuilder(system).Allow("Boot").Set("Boot.NoSuchProperty", 1).Apply()
+	RequireErrorContains(t, err, "unknown property Boot.NoSuchProperty")
+
+	_, err = NewPatchBuilder(system).Allow("Boot").Set("Boot", nil).Set("Boot.HttpBootUri", "x").Payload()
+	RequireErrorContains(t, err, "conflicts with the value set for Boot")
+
+	payload, err = NewPatchBuilder(system).Set("AssetTag", "new").Set("HostName", "host").Unset("HostName").Payload()
+	if err != nil {
+		t.Fatalf("Error building payload: %s", err)
+	}
+	AssertEqual(t, 1, len(payload))
+	AssertEqual(t, 0, len(testClient.CapturedCalls()))
+}
@@ -279,15 +279,18 @@ func (p *PCIeDevice) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (p *PCIeDevice) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (p *PCIeDevice) writableFields() []string {
+	return []string{
 		"AssetTag",
 		"LocationIndicatorActive",
 		"ReadyToRemove",
 	}
+}
 
-	return p.UpdateFromRawData(p, p.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (p *PCIeDevice) Update() error {
+	return p.UpdateFromRawData(p, p.RawData, p.writableFields())
 }
 
 // GetPCIeDevice will get a PCIeDevice instance from the service.
@@ -232,13 +232,16 @@ func (p *PCIeFunction) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (p *PCIeFunction) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (p *PCIeFunction) writableFields() []string {
+	return []string{
 		"Enabled",
 	}
+}
 
-	return p.UpdateFromRawData(p, p.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (p *PCIeFunction) Update() error {
+	return p.UpdateFromRawData(p, p.RawData, p.writableFields())
 }
 
 // GetPCIeFunction will get a PCIeFunction instance from the service.
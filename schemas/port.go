@@ -646,9 +646,9 @@ func (p *Port) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (p *Port) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (p *Port) writableFields() []string {
+	return []string{
 		"ConfiguredSpeedGbps",
 		"ConfiguredWidth",
 		"Enabled",
@@ -659,8 +659,11 @@ func (p *Port) Update() error {
 		"LocationIndicatorActive",
 		"PortType",
 	}
+}
 
-	return p.UpdateFromRawData(p, p.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (p *Port) Update() error {
+	return p.UpdateFromRawData(p, p.RawData, p.writableFields())
 }
 
 // GetPort will get a Port instance from the service.
@@ -572,13 +572,16 @@ func (p *PowerSupply) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (p *PowerSupply) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (p *PowerSupply) writableFields() []string {
+	return []string{
 		"IndicatorLED",
 	}
+}
 
-	return p.UpdateFromRawData(p, p.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (p *PowerSupply) Update() error {
+	return p.UpdateFromRawData(p, p.RawData, p.writableFields())
 }
 
 // GetPowerSupply will get a PowerSupply instance from the service.
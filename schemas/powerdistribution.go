@@ -249,14 +249,17 @@ func (p *PowerDistribution) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (p *PowerDistribution) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (p *PowerDistribution) writableFields() []string {
+	return []string{
 		"AssetTag",
 		"UserLabel",
 	}
+}
 
-	return p.UpdateFromRawData(p, p.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (p *PowerDistribution) Update() error {
+	return p.UpdateFromRawData(p, p.RawData, p.writableFields())
 }
 
 // GetPowerDistribution will get a PowerDistribution instance from the service.
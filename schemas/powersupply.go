@@ -224,15 +224,18 @@ func (p *PowerSupplyUnit) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (p *PowerSupplyUnit) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (p *PowerSupplyUnit) writableFields() []string {
+	return []string{
 		"ElectricalSourceManagerURIs",
 		"ElectricalSourceNames",
 		"LocationIndicatorActive",
 	}
+}
 
-	return p.UpdateFromRawData(p, p.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (p *PowerSupplyUnit) Update() error {
+	return p.UpdateFromRawData(p, p.RawData, p.writableFields())
 }
 
 // GetPowerSupplyUnit will get a PowerSupplyUnit instance from the service.
@@ -0,0 +1,264 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrInsufficientPrivilege is returned when the privileges held do not
+	// allow an operation.
+	ErrInsufficientPrivilege = errors.New("insufficient privilege")
+	// ErrNoPrivilegeMapping is returned when the privilege registry does not
+	// describe an operation.
+	ErrNoPrivilegeMapping = errors.New("no privilege mapping")
+)
+
+// noAuthPrivilege is used in privilege registries for operations that do not
+// require authentication.
+const noAuthPrivilege = "NoAuth"
+
+// configureSelfPrivilege only allows changes to the caller's own account and
+// session.
+const configureSelfPrivilege = string(ConfigureSelfPrivilegeType)
+
+// PrivilegeRequest describes an operation to check against the privilege
+// registry.
+type PrivilegeRequest struct {
+	// Entity is the resource type, such as "ComputerSystem" or "LogService".
+	Entity string
+	// Method is the HTTP method. Actions are performed with POST against the
+	// resource the action belongs to.
+	Method string
+	// URI is the resource URI, used to apply resource URI overrides.
+	URI string
+	// Properties are the properties being modified, used to apply property
+	// overrides.
+	Properties []string
+	// Parents are the resource types the resource is subordinate to, from the
+	// service root down, used to apply subordinate overrides. For example, an
+	// EthernetInterface of a manager has the parents "Manager" and
+	// "EthernetInterfaceCollection".
+	Parents []string
+	// Self indicates the resource is the caller's own account or session.
+	// The ConfigureSelf privilege only counts towards operations on these.
+	Self bool
+}
+
+func (r *PrivilegeRequest) String() string {
+	s := r.Method + " " + r.Entity
+	if r.URI != "" {
+		s += " " + r.URI
+	}
+	return s
+}
+
+// PrivilegeRequirement is a set of alternative privilege sets, any one of
+// which allows an operation.
+type PrivilegeRequirement struct {
+	// Target is what the requirement applies to: the entity, or a property.
+	Target string
+	// Options are the privilege sets that satisfy the requirement. All
+	// privileges in an option are needed.
+	Options [][]string
+}
+
+// SatisfiedBy returns whether the privileges meet the requirement.
+func (r *PrivilegeRequirement) SatisfiedBy(privileges []string) bool {
+	for _, option := range r.Options {
+		if slices.Contains(option, noAuthPrivilege) {
+			return true
+		}
+		satisfied := true
+		for _, privilege := range option {
+			if !slices.Contains(privileges, privilege) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *PrivilegeRequirement) String() string {
+	options := make([]string, len(r.Options))
+	for i, option := range r.Options {
+		options[i] = strings.Join(option, "+")
+	}
+	return fmt.Sprintf("%s requires %s", r.Target, strings.Join(options, " or "))
+}
+
+// PrivilegeError describes why an operation is not allowed.
+type PrivilegeError struct {
+	Request *PrivilegeRequest
+	// Missing are the requirements that are not met.
+	Missing []PrivilegeRequirement
+	// Privileges are the privileges that were checked.
+	Privileges []string
+}
+
+func (e *PrivilegeError) Error() string {
+	missing := make([]string, len(e.Missing))
+	for i := range e.Missing {
+		missing[i] = e.Missing[i].String()
+	}
+	return fmt.Sprintf("%s: %s not allowed: %s; have %s", ErrInsufficientPrivilege, e.Request,
+		strings.Join(missing, ", "), strings.Join(e.Privileges, ", "))
+}
+
+// Unwrap allows errors.Is to match ErrInsufficientPrivilege.
+func (e *PrivilegeError) Unwrap() error {
+	return ErrInsufficientPrivilege
+}
+
+// Privileges returns all privileges granted by the role.
+func (r *Role) Privileges() []string {
+	privileges := make([]string, 0, len(r.AssignedPrivileges)+len(r.OemPrivileges))
+	for _, privilege := range r.AssignedPrivileges {
+		privileges = append(privileges, string(privilege))
+	}
+	return append(privileges, r.OemPrivileges...)
+}
+
+// Mapping gets the privilege mapping for an entity, or nil if there is none.
+func (p *PrivilegeRegistry) Mapping(entity string) *Mapping {
+	for i := range p.Mappings {
+		if p.Mappings[i].Entity == entity {
+			return &p.Mappings[i]
+		}
+	}
+	return nil
+}
+
+// RequiredPrivileges returns the requirements that must all be met to perform
+// the operation. Resource URI overrides take precedence over subordinate
+// overrides, which take precedence over the entity's operation map. Each
+// modified property with a property override adds a requirement for that
+// property instead of the entity requirement.
+func (p *PrivilegeRegistry) RequiredPrivileges(req *PrivilegeRequest) ([]PrivilegeRequirement, error) {
+	mapping := p.Mapping(req.Entity)
+	if mapping == nil {
+		return nil, fmt.Errorf("%w for %s", ErrNoPrivilegeMapping, req.Entity)
+	}
+
+	base, ok := mapping.OperationMap.privileges(req.Method)
+	for i := range mapping.SubordinateOverrides {
+		override := &mapping.SubordinateOverrides[i]
+		if len(override.Targets) > 0 && isSubsequence(override.Targets, req.Parents) {
+			if privileges, found := override.OperationMap.privileges(req.Method); found {
+				base, ok = privileges, true
+			}
+		}
+	}
+	for i := range mapping.ResourceURIOverrides {
+		override := &mapping.ResourceURIOverrides[i]
+		if req.URI != "" && slices.Contains(override.Targets, req.URI) {
+			if privileges, found := override.OperationMap.privileges(req.Method); found {
+				base, ok = privileges, true
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w for %s", ErrNoPrivilegeMapping, req)
+	}
+
+	var requirements []PrivilegeRequirement
+	needBase := len(req.Properties) == 0
+	for _, property := range req.Properties {
+		overridden := false
+		for i := range mapping.PropertyOverrides {
+			override := &mapping.PropertyOverrides[i]
+			if !slices.Contains(override.Targets, property) {
+				continue
+			}
+			if privileges, found := override.OperationMap.privileges(req.Method); found {
+				requirements = append(requirements, PrivilegeRequirement{Target: property, Options: privileges})
+				overridden = true
+			}
+		}
+		if !overridden {
+			needBase = true
+		}
+	}
+	if needBase {
+		requirements = append([]PrivilegeRequirement{{Target: req.Entity, Options: base}}, requirements...)
+	}
+
+	return requirements, nil
+}
+
+// CheckPrivileges returns a PrivilegeError if the privileges do not allow the
+// operation. ConfigureSelf is ignored unless the request is for the caller's
+// own account or session.
+func (p *PrivilegeRegistry) CheckPrivileges(req *PrivilegeRequest, privileges []string) error {
+	requirements, err := p.RequiredPrivileges(req)
+	if err != nil {
+		return err
+	}
+	if !req.Self && slices.Contains(privileges, configureSelfPrivilege) {
+		privileges = slices.DeleteFunc(slices.Clone(privileges), func(privilege string) bool {
+			return privilege == configureSelfPrivilege
+		})
+	}
+
+	var missing []PrivilegeRequirement
+	for i := range requirements {
+		if !requirements[i].SatisfiedBy(privileges) {
+			missing = append(missing, requirements[i])
+		}
+	}
+	if len(missing) > 0 {
+		return &PrivilegeError{Request: req, Missing: missing, Privileges: privileges}
+	}
+	return nil
+}
+
+// privileges returns the privilege options for the method, and whether the
+// method is present in the map.
+func (o *OperationMap) privileges(method string) ([][]string, bool) {
+	var operations []OperationPrivilege
+	switch strings.ToUpper(method) {
+	case http.MethodGet:
+		operations = o.GET
+	case http.MethodHead:
+		operations = o.HEAD
+	case http.MethodPatch:
+		operations = o.PATCH
+	case http.MethodPost:
+		operations = o.POST
+	case http.MethodPut:
+		operations = o.PUT
+	case http.MethodDelete:
+		operations = o.DELETE
+	}
+	if operations == nil {
+		return nil, false
+	}
+
+	options := make([][]string, len(operations))
+	for i := range operations {
+		options[i] = operations[i].Privilege
+	}
+	return options, true
+}
+
+// isSubsequence returns whether all of want appear in have, in order.
+func isSubsequence(want, have []string) bool {
+	i := 0
+	for _, s := range have {
+		if i < len(want) && want[i] == s {
+			i++
+		}
+	}
+	return i == len(want)
+}
@@ -0,0 +1,122 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+var privilegeCheckBody = `{
+		"@odata.id": "/redfish/v1/AccountService/PrivilegeMap",
+		"Id": "PrivilegeMap",
+		"Mappings": [
+			{
+				"Entity": "ComputerSystem",
+				"OperationMap": {
+					"GET": [{"Privilege": ["Login"]}],
+					"PATCH": [{"Privilege": ["ConfigureComponents"]}],
+					"POST": [{"Privilege": ["ConfigureComponents"]}]
+				}
+			},
+			{
+				"Entity": "ManagerAccount",
+				"OperationMap": {
+					"GET": [{"Privilege": ["ConfigureManager"]}, {"Privilege": ["ConfigureUsers"]}],
+					"PATCH": [{"Privilege": ["ConfigureUsers"]}]
+				},
+				"PropertyOverrides": [{
+					"Targets": ["Password"],
+					"OperationMap": {"PATCH": [{"Privilege": ["ConfigureUsers"]}, {"Privilege": ["ConfigureSelf"]}]}
+				}]
+			},
+			{
+				"Entity": "EthernetInterface",
+				"OperationMap": {"PATCH": [{"Privilege": ["ConfigureComponents"]}]},
+				"SubordinateOverrides": [{
+					"Targets": ["Manager", "EthernetInterfaceCollection"],
+					"OperationMap": {"PATCH": [{"Privilege": ["ConfigureManager"]}]}
+				}]
+			},
+			{
+				"Entity": "ServiceRoot",
+				"OperationMap": {"GET": [{"Privilege": ["NoAuth"]}]},
+				"ResourceURIOverrides": [{
+					"Targets": ["/redfish/v1/Private"],
+					"OperationMap": {"GET": [{"Privilege": ["Login", "ConfigureManager"]}]}
+				}]
+			}
+		]
+	}`
+
+func testPrivilegeRegistry(t *testing.T) *PrivilegeRegistry {
+	var result PrivilegeRegistry
+	if err := json.Unmarshal([]byte(privilegeCheckBody), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	return &result
+}
+
+// TestCheckPrivileges tests operations are checked against the registry.
+func TestCheckPrivileges(t *testing.T) {
+	registry := testPrivilegeRegistry(t)
+	readOnly := []string{"Login", "ConfigureSelf"}
+
+	err := registry.CheckPrivileges(&PrivilegeRequest{Entity: "ComputerSystem", Method: http.MethodGet}, readOnly)
+	if err != nil {
+		t.Errorf("Expected GET to be allowed: %v", err)
+	}
+
+	err = registry.CheckPrivileges(&PrivilegeRequest{Entity: "ComputerSystem", Method: http.MethodPatch,
+		URI: "/redfish/v1/Systems/1"}, readOnly)
+	if !errors.Is(err, ErrInsufficientPrivilege) {
+		t.Errorf("Expected PATCH to be denied, got: %v", err)
+	}
+	RequireErrorContains(t, err, "PATCH ComputerSystem /redfish/v1/Systems/1 not allowed: ComputerSystem requires ConfigureComponents")
+
+	err = registry.CheckPrivileges(&PrivilegeRequest{Entity: "Bios", Method: http.MethodGet}, readOnly)
+	if !errors.Is(err, ErrNoPrivilegeMapping) {
+		t.Errorf("Expected no mapping error, got: %v", err)
+	}
+}
+
+// TestCheckPrivilegesOverrides tests property, subordinate and URI overrides.
+func TestCheckPrivilegesOverrides(t *testing.T) {
+	registry := testPrivilegeRegistry(t)
+	readOnly := []string{"Login", "ConfigureSelf"}
+
+	// Changing only the password of the caller's own account is allowed by
+	// the property override
+	req := &PrivilegeRequest{Entity: "ManagerAccount", Method: http.MethodPatch, Properties: []string{"Password"}}
+	err := registry.CheckPrivileges(req, readOnly)
+	if !errors.Is(err, ErrInsufficientPrivilege) {
+		t.Errorf("Expected changing another account's password to be denied, got: %v", err)
+	}
+	req.Self = true
+	if err := registry.CheckPrivileges(req, readOnly); err != nil {
+		t.Errorf("Expected password change to be allowed: %v", err)
+	}
+	req.Properties = append(req.Properties, "RoleId")
+	err = registry.CheckPrivileges(req, readOnly)
+	RequireErrorContains(t, err, "ManagerAccount requires ConfigureUsers")
+
+	req = &PrivilegeRequest{Entity: "EthernetInterface", Method: http.MethodPatch,
+		Parents: []string{"ServiceRoot", "Manager", "EthernetInterfaceCollection"}}
+	requirements, err := registry.RequiredPrivileges(req)
+	if err != nil {
+		t.Fatalf("Error getting requirements: %v", err)
+	}
+	AssertEqual(t, "EthernetInterface requires ConfigureManager", requirements[0].String())
+
+	req = &PrivilegeRequest{Entity: "ServiceRoot", Method: http.MethodGet}
+	if err := registry.CheckPrivileges(req, nil); err != nil {
+		t.Errorf("Expected unauthenticated access to be allowed: %v", err)
+	}
+	req.URI = "/redfish/v1/Private"
+	err = registry.CheckPrivileges(req, readOnly)
+	RequireErrorContains(t, err, "ServiceRoot requires Login+ConfigureManager")
+}
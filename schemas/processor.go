@@ -629,9 +629,9 @@ func (p *Processor) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (p *Processor) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (p *Processor) writableFields() []string {
+	return []string{
 		"AppliedOperatingConfig",
 		"Enabled",
 		"LocationIndicatorActive",
@@ -639,8 +639,11 @@ func (p *Processor) Update() error {
 		"SpeedLimitMHz",
 		"SpeedLocked",
 	}
+}
 
-	return p.UpdateFromRawData(p, p.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (p *Processor) Update() error {
+	return p.UpdateFromRawData(p, p.RawData, p.writableFields())
 }
 
 // GetProcessor will get a Processor instance from the service.
@@ -153,16 +153,19 @@ func (p *Pump) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (p *Pump) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (p *Pump) writableFields() []string {
+	return []string{
 		"AssetTag",
 		"LocationIndicatorActive",
 		"ServiceHours",
 		"UserLabel",
 	}
+}
 
-	return p.UpdateFromRawData(p, p.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (p *Pump) Update() error {
+	return p.UpdateFromRawData(p, p.RawData, p.writableFields())
 }
 
 // GetPump will get a Pump instance from the service.
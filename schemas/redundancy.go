@@ -136,14 +136,17 @@ func (r *Redundancy) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (r *Redundancy) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (r *Redundancy) writableFields() []string {
+	return []string{
 		"Mode",
 		"RedundancyEnabled",
 	}
+}
 
-	return r.UpdateFromRawData(r, r.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (r *Redundancy) Update() error {
+	return r.UpdateFromRawData(r, r.RawData, r.writableFields())
 }
 
 // GetRedundancy will get a Redundancy instance from the service.
@@ -98,17 +98,20 @@ func (r *RegisteredClient) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (r *RegisteredClient) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (r *RegisteredClient) writableFields() []string {
+	return []string{
 		"ClientType",
 		"ClientURI",
 		"Context",
 		"ExpirationDate",
 		"SubContext",
 	}
+}
 
-	return r.UpdateFromRawData(r, r.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (r *RegisteredClient) Update() error {
+	return r.UpdateFromRawData(r, r.RawData, r.writableFields())
 }
 
 // GetRegisteredClient will get a RegisteredClient instance from the service.
@@ -123,14 +123,17 @@ func (r *Reservoir) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (r *Reservoir) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (r *Reservoir) writableFields() []string {
+	return []string{
 		"LocationIndicatorActive",
 		"UserLabel",
 	}
+}
 
-	return r.UpdateFromRawData(r, r.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (r *Reservoir) Update() error {
+	return r.UpdateFromRawData(r, r.RawData, r.writableFields())
 }
 
 // GetReservoir will get a Reservoir instance from the service.
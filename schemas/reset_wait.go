@@ -0,0 +1,352 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const (
+	// defaultResetPollRate is how often a resource is polled while waiting
+	// for a reset to complete if no rate was given.
+	defaultResetPollRate = 5 * time.Second
+
+	// defaultOfflineTimeout is how long to wait for a resource to go down
+	// after a restart before assuming the restart was too quick to observe.
+	defaultOfflineTimeout = 60 * time.Second
+)
+
+// SessionRenewer is implemented by clients that are able to re-establish
+// their authenticated session, such as after a manager reset invalidated it.
+type SessionRenewer interface {
+	RenewSession() error
+}
+
+// ContextClient is implemented by clients that are able to make their requests
+// with a given context, so that cancelling it also aborts requests in flight.
+type ContextClient interface {
+	ClientWithContext(ctx context.Context) Client
+}
+
+// ResetProgress describes the state of a resource observed while waiting for
+// a reset to complete.
+type ResetProgress struct {
+	// PowerState is the last power state reported by the resource.
+	PowerState PowerState
+	// Reachable is false while the resource cannot be retrieved, such as while
+	// a manager is restarting.
+	Reachable bool
+	// Err is the error returned by the last poll, if any.
+	Err error
+}
+
+// ResetWaitOptions controls how the ResetAndWait helpers wait for a reset to
+// complete. The timeout is controlled by the context passed to ResetAndWait.
+type ResetWaitOptions struct {
+	// PollRate is the interval between checks of the resource. Defaults to
+	// five seconds.
+	PollRate time.Duration
+	// PowerState overrides the power state to wait for. If empty, it is
+	// derived from the reset type with ExpectedPowerState.
+	PowerState PowerState
+	// OfflineTimeout is how long to wait for a restarting resource to go down,
+	// such as a manager becoming unreachable or a system leaving the On power
+	// state, before assuming it restarted too quickly to observe. Defaults to
+	// one minute. Only used for restart-type resets and manager resets.
+	OfflineTimeout time.Duration
+	// OnProgress, if set, is called each time the observed state of the
+	// resource changes.
+	OnProgress func(ResetProgress)
+}
+
+func (o *ResetWaitOptions) pollRate() time.Duration {
+	if o == nil || o.PollRate <= 0 {
+		return defaultResetPollRate
+	}
+	return o.PollRate
+}
+
+func (o *ResetWaitOptions) offlineTimeout() time.Duration {
+	if o == nil || o.OfflineTimeout <= 0 {
+		return defaultOfflineTimeout
+	}
+	return o.OfflineTimeout
+}
+
+func (o *ResetWaitOptions) report(progress ResetProgress) {
+	if o != nil && o.OnProgress != nil {
+		o.OnProgress(progress)
+	}
+}
+
+// ExpectedPowerState returns the power state a resource currently in the
+// current state is expected to settle in once the given reset completes. The
+// second return value is false if the reset type has no predictable outcome,
+// such as Nmi.
+func ExpectedPowerState(resetType ResetType, current PowerState) (PowerState, bool) {
+	switch resetType {
+	case OnResetType, ForceOnResetType, ForceRestartResetType,
+		GracefulRestartResetType, PowerCycleResetType,
+		FullPowerCycleResetType, ResumeResetType:
+		return OnPowerState, true
+	case ForceOffResetType, GracefulShutdownResetType, SuspendResetType:
+		return OffPowerState, true
+	case PauseResetType:
+		return PausedPowerState, true
+	case PushPowerButtonResetType:
+		switch current {
+		case OnPowerState, PoweringOnPowerState:
+			return OffPowerState, true
+		case OffPowerState, PoweringOffPowerState:
+			return OnPowerState, true
+		}
+	}
+	return "", false
+}
+
+// ResetAndWait resets the system and waits for it to reach the power state
+// expected for the reset type. The reset type is first validated against the
+// types returned by GetSupportedResetTypes. For restart-type resets, the
+// system is first waited on to go down, so a restart that has not started yet
+// is not mistaken for one that completed. If the system reports On throughout,
+// this returns once the OfflineTimeout has passed. The refreshed system is
+// returned on success. Requests are made with ctx if the client implements
+// ContextClient.
+func (c *ComputerSystem) ResetAndWait(ctx context.Context, resetType ResetType, opts *ResetWaitOptions) (*ComputerSystem, error) {
+	supported, err := c.GetSupportedResetTypes()
+	if err := validateResetType(resetType, supported, err); err != nil {
+		return nil, err
+	}
+
+	target, ok := resetTarget(resetType, c.PowerState, opts)
+
+	taskInfo, err := c.Reset(resetType)
+	if err != nil {
+		return nil, err
+	}
+	if err := waitForResetTask(ctx, c.client, taskInfo, opts); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return getObjectWithContext[ComputerSystem](ctx, c.client, c.ODataID)
+	}
+
+	return waitForPowerState(ctx, c.client, c.ODataID, target, isRestart(resetType), opts,
+		func(s *ComputerSystem) PowerState { return s.PowerState })
+}
+
+// ResetAndWait resets the chassis and waits for it to reach the power state
+// expected for the reset type. The reset type is first validated against the
+// types returned by GetSupportedResetTypes. Restart-type resets are waited on
+// as with ComputerSystem.ResetAndWait. The refreshed chassis is returned on
+// success.
+func (c *Chassis) ResetAndWait(ctx context.Context, resetType ResetType, opts *ResetWaitOptions) (*Chassis, error) {
+	supported, err := c.GetSupportedResetTypes()
+	if err := validateResetType(resetType, supported, err); err != nil {
+		return nil, err
+	}
+
+	target, ok := resetTarget(resetType, c.PowerState, opts)
+
+	taskInfo, err := c.Reset(resetType)
+	if err != nil {
+		return nil, err
+	}
+	if err := waitForResetTask(ctx, c.client, taskInfo, opts); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return getObjectWithContext[Chassis](ctx, c.client, c.ODataID)
+	}
+
+	return waitForPowerState(ctx, c.client, c.ODataID, target, isRestart(resetType), opts,
+		func(ch *Chassis) PowerState { return ch.PowerState })
+}
+
+// ResetAndWait resets the manager and waits for it to come back. Since the
+// manager usually provides the Redfish service, this waits for the manager to
+// become unreachable and then available again. If the reset invalidated the
+// session and the client implements SessionRenewer, a new session is created.
+// Errors other than transient ones, such as network errors and 5xx responses,
+// end the wait. The refreshed manager is returned on success. Requests are made with ctx if
+// the client implements ContextClient.
+func (m *Manager) ResetAndWait(ctx context.Context, resetType ResetType, opts *ResetWaitOptions) (*Manager, error) {
+	supported, err := m.GetSupportedResetTypes()
+	if err := validateResetType(resetType, supported, err); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.Reset(resetType); err != nil {
+		return nil, err
+	}
+
+	// Task monitors are not waited on here as they generally do not survive
+	// the manager restart.
+	start := time.Now()
+	offline := false
+	var last *ResetProgress
+	for {
+		manager, err := pollResetResource[Manager](ctx, m.client, m.ODataID)
+		progress := ResetProgress{Reachable: err == nil, Err: err}
+		if err == nil {
+			progress.PowerState = manager.PowerState
+		}
+		if last == nil || last.Reachable != progress.Reachable || last.PowerState != progress.PowerState {
+			opts.report(progress)
+		}
+		last = &progress
+
+		if err != nil && !isTransient(err) {
+			return nil, fmt.Errorf("unable to get manager %s while waiting for reset: %w", m.ODataID, err)
+		}
+		if err != nil {
+			offline = true
+		} else if (offline || time.Since(start) >= opts.offlineTimeout()) &&
+			(manager.PowerState == "" || manager.PowerState == OnPowerState) {
+			return manager, nil
+		}
+
+		select {
+		case <-time.After(opts.pollRate()):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for manager %s to come back after reset: %w",
+				m.ODataID, ctx.Err())
+		}
+	}
+}
+
+// validateResetType makes sure the requested reset type is one the resource
+// declares as supported. If the resource does not declare any, the reset type
+// is assumed to be OK.
+func validateResetType(resetType ResetType, supported []ResetType, err error) error {
+	if err != nil {
+		return fmt.Errorf("unable to determine supported reset types: %w", err)
+	}
+	if len(supported) > 0 && !slices.Contains(supported, resetType) {
+		return fmt.Errorf("reset type '%s' is not supported by this service", resetType)
+	}
+	return nil
+}
+
+// resetTarget determines the power state to wait for after a reset.
+func resetTarget(resetType ResetType, current PowerState, opts *ResetWaitOptions) (PowerState, bool) {
+	if opts != nil && opts.PowerState != "" {
+		return opts.PowerState, true
+	}
+	return ExpectedPowerState(resetType, current)
+}
+
+// waitForResetTask waits for a task monitor returned by a reset action, if any.
+func waitForResetTask(ctx context.Context, c Client, taskInfo *TaskMonitorInfo, opts *ResetWaitOptions) error {
+	if taskInfo == nil || taskInfo.TaskMonitor == "" {
+		return nil
+	}
+	resp, err := WaitForTaskMonitor(ctx, requestClient(ctx, c), opts.pollRate(), taskInfo, nil)
+	defer DeferredCleanupHTTPResponse(resp)
+	return err
+}
+
+// waitForPowerState polls the resource at uri until it reports the target
+// power state or the context is done. For a restart, the resource must first
+// be seen to go down, unless it does not within the OfflineTimeout. Transient
+// errors, such as network errors and 5xx responses, are taken to mean the
+// resource is down, while any other error ends the wait.
+func waitForPowerState[T any, PT GenericSchemaObjectPointer[T]](
+	ctx context.Context, c Client, uri string, target PowerState, restart bool,
+	opts *ResetWaitOptions, powerState func(PT) PowerState) (*T, error) {
+	start := time.Now()
+	down := !restart
+	var last *ResetProgress
+	for {
+		entity, err := pollResetResource[T, PT](ctx, c, uri)
+		progress := ResetProgress{Reachable: err == nil, Err: err}
+		if err == nil {
+			progress.PowerState = powerState(entity)
+		}
+		if last == nil || last.Reachable != progress.Reachable || last.PowerState != progress.PowerState {
+			opts.report(progress)
+		}
+		last = &progress
+
+		if err != nil && !isTransient(err) {
+			return nil, fmt.Errorf("unable to get %s while waiting for reset: %w", uri, err)
+		}
+		if err != nil || progress.PowerState != target {
+			down = true
+		} else if down || time.Since(start) >= opts.offlineTimeout() {
+			return entity, nil
+		}
+
+		select {
+		case <-time.After(opts.pollRate()):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for %s to reach power state %s (last %q): %w",
+				uri, target, last.PowerState, ctx.Err())
+		}
+	}
+}
+
+// pollResetResource retrieves a resource while waiting for a reset. If the
+// request is rejected as unauthorized, the session is renewed (when the client
+// supports it) and the request retried once.
+func pollResetResource[T any, PT GenericSchemaObjectPointer[T]](ctx context.Context, c Client, uri string) (*T, error) {
+	entity, err := getObjectWithContext[T, PT](ctx, c, uri)
+	if err == nil || !isUnauthorized(err) {
+		return entity, err
+	}
+
+	renewer, ok := c.(SessionRenewer)
+	if !ok {
+		return nil, err
+	}
+	if renewErr := renewer.RenewSession(); renewErr != nil {
+		return nil, fmt.Errorf("unable to renew session: %w", renewErr)
+	}
+	return getObjectWithContext[T, PT](ctx, c, uri)
+}
+
+// getObjectWithContext retrieves a resource, making the request with ctx if
+// the client supports it. The resource keeps using the client it was given.
+func getObjectWithContext[T any, PT GenericSchemaObjectPointer[T]](ctx context.Context, c Client, uri string) (*T, error) {
+	entity, err := GetObject[T, PT](requestClient(ctx, c), uri)
+	if err != nil {
+		return nil, err
+	}
+	PT(entity).SetClient(c)
+	return entity, nil
+}
+
+// requestClient returns a client making its requests with ctx, if the client
+// implements ContextClient.
+func requestClient(ctx context.Context, c Client) Client {
+	if contextClient, ok := c.(ContextClient); ok {
+		return contextClient.ClientWithContext(ctx)
+	}
+	return c
+}
+
+// isRestart checks if the reset type restarts a resource that is on, so that
+// it is expected to go down and come back in the same power state.
+func isRestart(resetType ResetType) bool {
+	switch resetType {
+	case ForceRestartResetType, GracefulRestartResetType, PowerCycleResetType, FullPowerCycleResetType:
+		return true
+	}
+	return false
+}
+
+// isUnauthorized checks if the error is a 401 Unauthorized response.
+func isUnauthorized(err error) bool {
+	var redfishErr *Error
+	if errors.As(err, &redfishErr) {
+		return redfishErr.HTTPReturnedStatusCode == http.StatusUnauthorized
+	}
+	return false
+}
@@ -0,0 +1,294 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+const resetWaitSystemBody = `{
+		"@odata.id": "/redfish/v1/Systems/1",
+		"Id": "1",
+		"Name": "System",
+		"PowerState": "%s",
+		"Actions": {
+			"#ComputerSystem.Reset": {
+				"target": "/redfish/v1/Systems/1/Actions/ComputerSystem.Reset",
+				"ResetType@Redfish.AllowableValues": ["On", "ForceOff", "ForceRestart", "Nmi"]
+			}
+		}
+	}`
+
+const resetWaitManagerBody = `{
+		"@odata.id": "/redfish/v1/Managers/1",
+		"Id": "1",
+		"Name": "Manager",
+		"PowerState": "On",
+		"Actions": {
+			"#Manager.Reset": {
+				"target": "/redfish/v1/Managers/1/Actions/Manager.Reset",
+				"ResetType@Redfish.AllowableValues": ["GracefulRestart"]
+			}
+		}
+	}`
+
+// errorCall returns a response with the given error status code.
+func errorCall(statusCode int) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       io.NopCloser(strings.NewReader(http.StatusText(statusCode))),
+	}
+}
+
+// renewingTestClient is a TestClient that can renew its session.
+type renewingTestClient struct {
+	*TestClient
+	renewals int
+}
+
+func (c *renewingTestClient) RenewSession() error {
+	c.renewals++
+	return nil
+}
+
+// contextTestClient is a TestClient that records the contexts its requests
+// are made with.
+type contextTestClient struct {
+	*TestClient
+	contexts []context.Context
+}
+
+func (c *contextTestClient) ClientWithContext(ctx context.Context) Client {
+	c.contexts = append(c.contexts, ctx)
+	return c.TestClient
+}
+
+func resetWaitSystem(t *testing.T, powerState PowerState) *ComputerSystem {
+	var result ComputerSystem
+	if err := json.Unmarshal([]byte(fmt.Sprintf(resetWaitSystemBody, powerState)), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	return &result
+}
+
+// TestSystemResetAndWait tests waiting for a system to power on.
+func TestSystemResetAndWait(t *testing.T) {
+	result := resetWaitSystem(t, OffPowerState)
+
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				getCall(fmt.Sprintf(resetWaitSystemBody, OffPowerState)),
+				getCall(fmt.Sprintf(resetWaitSystemBody, PoweringOnPowerState)),
+				getCall(fmt.Sprintf(resetWaitSystemBody, OnPowerState)),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	var states []PowerState
+	system, err := result.ResetAndWait(context.Background(), OnResetType, &ResetWaitOptions{
+		PollRate:   time.Millisecond,
+		OnProgress: func(p ResetProgress) { states = append(states, p.PowerState) },
+	})
+	if err != nil {
+		t.Fatalf("Error waiting for reset: %s", err)
+	}
+
+	if system.PowerState != OnPowerState {
+		t.Errorf("Expected system to be On, got %s", system.PowerState)
+	}
+
+	expected := []PowerState{OffPowerState, PoweringOnPowerState, OnPowerState}
+	if fmt.Sprint(states) != fmt.Sprint(expected) {
+		t.Errorf("Expected progress %v, got %v", expected, states)
+	}
+
+	calls := testClient.CapturedCalls()
+	if calls[0].Action != http.MethodPost || !strings.Contains(calls[0].Payload, "ResetType:On") {
+		t.Errorf("Unexpected reset call: %v", calls[0])
+	}
+}
+
+// TestSystemResetAndWaitRestart tests a restart is only complete once the
+// system has gone down and come back, with requests made using the context.
+func TestSystemResetAndWaitRestart(t *testing.T) {
+	result := resetWaitSystem(t, OnPowerState)
+
+	testClient := &contextTestClient{
+		TestClient: &TestClient{
+			CustomReturnForActions: map[string][]any{
+				http.MethodGet: {
+					getCall(fmt.Sprintf(resetWaitSystemBody, OnPowerState)),
+					errorCall(http.StatusServiceUnavailable),
+					getCall(fmt.Sprintf(resetWaitSystemBody, PoweringOnPowerState)),
+					getCall(fmt.Sprintf(resetWaitSystemBody, OnPowerState)),
+				},
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var states []PowerState
+	system, err := result.ResetAndWait(ctx, ForceRestartResetType, &ResetWaitOptions{
+		PollRate:   time.Millisecond,
+		OnProgress: func(p ResetProgress) { states = append(states, p.PowerState) },
+	})
+	if err != nil {
+		t.Fatalf("Error waiting for reset: %s", err)
+	}
+
+	expected := []PowerState{OnPowerState, "", PoweringOnPowerState, OnPowerState}
+	if fmt.Sprint(states) != fmt.Sprint(expected) {
+		t.Errorf("Expected progress %v, got %v", expected, states)
+	}
+	AssertEqual(t, 4, len(testClient.contexts))
+	for _, requestCtx := range testClient.contexts {
+		if requestCtx != ctx {
+			t.Errorf("Expected requests to be made with the wait context")
+		}
+	}
+	if system.GetClient() != testClient {
+		t.Errorf("Expected the system to keep the client it was reset with")
+	}
+}
+
+// TestSystemResetAndWaitUnsupported tests that unsupported reset types are rejected.
+func TestSystemResetAndWaitUnsupported(t *testing.T) {
+	result := resetWaitSystem(t, OnPowerState)
+
+	testClient := &TestClient{}
+	result.SetClient(testClient)
+
+	_, err := result.ResetAndWait(context.Background(), GracefulShutdownResetType, nil)
+	RequireErrorContains(t, err, "reset type 'GracefulShutdown' is not supported")
+
+	if len(testClient.CapturedCalls()) != 0 {
+		t.Errorf("Expected no calls, got %v", testClient.CapturedCalls())
+	}
+}
+
+// TestSystemResetAndWaitTimeout tests that the context deadline is honored.
+func TestSystemResetAndWaitTimeout(t *testing.T) {
+	result := resetWaitSystem(t, OnPowerState)
+
+	gets := make([]any, 100)
+	for i := range gets {
+		gets[i] = getCall(fmt.Sprintf(resetWaitSystemBody, OnPowerState))
+	}
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{http.MethodGet: gets},
+	}
+	result.SetClient(testClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := result.ResetAndWait(ctx, ForceOffResetType, &ResetWaitOptions{PollRate: 5 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+// TestSystemResetAndWaitPermanentError tests an error that won't go away,
+// unlike those seen while the system restarts, ends the wait.
+func TestSystemResetAndWaitPermanentError(t *testing.T) {
+	result := resetWaitSystem(t, OffPowerState)
+
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				errorCall(http.StatusServiceUnavailable),
+				errorCall(http.StatusNotFound),
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err := result.ResetAndWait(ctx, OnResetType, &ResetWaitOptions{PollRate: time.Millisecond})
+	var redfishErr *Error
+	if !errors.As(err, &redfishErr) || redfishErr.HTTPReturnedStatusCode != http.StatusNotFound {
+		t.Fatalf("Expected the not found error, got %v", err)
+	}
+	RequireErrorContains(t, err, "unable to get /redfish/v1/Systems/1 while waiting for reset")
+}
+
+// TestExpectedPowerState tests the power state mapping of reset types.
+func TestExpectedPowerState(t *testing.T) {
+	tests := []struct {
+		resetType ResetType
+		current   PowerState
+		expected  PowerState
+		ok        bool
+	}{
+		{OnResetType, OffPowerState, OnPowerState, true},
+		{GracefulShutdownResetType, OnPowerState, OffPowerState, true},
+		{ForceRestartResetType, OnPowerState, OnPowerState, true},
+		{PushPowerButtonResetType, OnPowerState, OffPowerState, true},
+		{PushPowerButtonResetType, OffPowerState, OnPowerState, true},
+		{NmiResetType, OnPowerState, "", false},
+	}
+
+	for _, tt := range tests {
+		state, ok := ExpectedPowerState(tt.resetType, tt.current)
+		if state != tt.expected || ok != tt.ok {
+			t.Errorf("%s from %s: expected %q/%v, got %q/%v",
+				tt.resetType, tt.current, tt.expected, tt.ok, state, ok)
+		}
+	}
+}
+
+// TestManagerResetAndWait tests waiting for a manager to restart and
+// renewing the session it dropped.
+func TestManagerResetAndWait(t *testing.T) {
+	var result Manager
+	if err := json.Unmarshal([]byte(resetWaitManagerBody), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+
+	testClient := &renewingTestClient{
+		TestClient: &TestClient{
+			CustomReturnForActions: map[string][]any{
+				http.MethodGet: {
+					errorCall(http.StatusServiceUnavailable),
+					errorCall(http.StatusUnauthorized),
+					getCall(resetWaitManagerBody),
+				},
+			},
+		},
+	}
+	result.SetClient(testClient)
+
+	var reachable []bool
+	manager, err := result.ResetAndWait(context.Background(), GracefulRestartResetType, &ResetWaitOptions{
+		PollRate:   time.Millisecond,
+		OnProgress: func(p ResetProgress) { reachable = append(reachable, p.Reachable) },
+	})
+	if err != nil {
+		t.Fatalf("Error waiting for reset: %s", err)
+	}
+
+	if manager.ID != "1" {
+		t.Errorf("Unexpected manager returned: %s", manager.ID)
+	}
+	if testClient.renewals != 1 {
+		t.Errorf("Expected the session to be renewed once, got %d", testClient.renewals)
+	}
+	if fmt.Sprint(reachable) != "[false true]" {
+		t.Errorf("Unexpected progress: %v", reachable)
+	}
+}
@@ -214,14 +214,17 @@ func (r *ResourceBlock) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (r *ResourceBlock) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (r *ResourceBlock) writableFields() []string {
+	return []string{
 		"Client",
 		"Pool",
 	}
+}
 
-	return r.UpdateFromRawData(r, r.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (r *ResourceBlock) Update() error {
+	return r.UpdateFromRawData(r, r.RawData, r.writableFields())
 }
 
 // GetResourceBlock will get a ResourceBlock instance from the service.
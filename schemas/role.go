@@ -77,14 +77,17 @@ func (r *Role) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (r *Role) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (r *Role) writableFields() []string {
+	return []string{
 		"AssignedPrivileges",
 		"OemPrivileges",
 	}
+}
 
-	return r.UpdateFromRawData(r, r.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (r *Role) Update() error {
+	return r.UpdateFromRawData(r, r.RawData, r.writableFields())
 }
 
 // GetRole will get a Role instance from the service.
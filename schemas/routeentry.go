@@ -60,14 +60,17 @@ func (r *RouteEntry) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (r *RouteEntry) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (r *RouteEntry) writableFields() []string {
+	return []string{
 		"MinimumHopCount",
 		"RawEntryHex",
 	}
+}
 
-	return r.UpdateFromRawData(r, r.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (r *RouteEntry) Update() error {
+	return r.UpdateFromRawData(r, r.RawData, r.writableFields())
 }
 
 // GetRouteEntry will get a RouteEntry instance from the service.
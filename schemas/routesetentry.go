@@ -60,16 +60,19 @@ func (r *RouteSetEntry) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (r *RouteSetEntry) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (r *RouteSetEntry) writableFields() []string {
+	return []string{
 		"EgressIdentifier",
 		"HopCount",
 		"VCAction",
 		"Valid",
 	}
+}
 
-	return r.UpdateFromRawData(r, r.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (r *RouteSetEntry) Update() error {
+	return r.UpdateFromRawData(r, r.RawData, r.writableFields())
 }
 
 // GetRouteSetEntry will get a RouteSetEntry instance from the service.
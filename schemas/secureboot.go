@@ -110,13 +110,16 @@ func (s *SecureBoot) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *SecureBoot) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *SecureBoot) writableFields() []string {
+	return []string{
 		"SecureBootEnable",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *SecureBoot) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetSecureBoot will get a SecureBoot instance from the service.
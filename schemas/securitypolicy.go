@@ -64,13 +64,16 @@ func (s *SecurityPolicy) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *SecurityPolicy) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *SecurityPolicy) writableFields() []string {
+	return []string{
 		"OverrideParentManager",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *SecurityPolicy) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetSecurityPolicy will get a SecurityPolicy instance from the service.
@@ -665,9 +665,9 @@ func (s *Sensor) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *Sensor) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *Sensor) writableFields() []string {
+	return []string{
 		"AveragingInterval",
 		"Calibration",
 		"CalibrationTime",
@@ -677,8 +677,11 @@ func (s *Sensor) Update() error {
 		"RelatedItem",
 		"UserLabel",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *Sensor) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetSensor will get a Sensor instance from the service.
@@ -0,0 +1,397 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// ErrInvalidThreshold is returned when sensor thresholds are outside the
+// reading range of the sensor or are not in order.
+var ErrInvalidThreshold = errors.New("invalid sensor threshold")
+
+// ThresholdName is the name of a threshold in the Thresholds of a sensor.
+type ThresholdName string
+
+const (
+	// LowerCautionThresholdName is the LowerCaution threshold.
+	LowerCautionThresholdName ThresholdName = "LowerCaution"
+	// LowerCautionUserThresholdName is the LowerCautionUser threshold.
+	LowerCautionUserThresholdName ThresholdName = "LowerCautionUser"
+	// LowerCriticalThresholdName is the LowerCritical threshold.
+	LowerCriticalThresholdName ThresholdName = "LowerCritical"
+	// LowerCriticalUserThresholdName is the LowerCriticalUser threshold.
+	LowerCriticalUserThresholdName ThresholdName = "LowerCriticalUser"
+	// LowerFatalThresholdName is the LowerFatal threshold.
+	LowerFatalThresholdName ThresholdName = "LowerFatal"
+	// UpperCautionThresholdName is the UpperCaution threshold.
+	UpperCautionThresholdName ThresholdName = "UpperCaution"
+	// UpperCautionUserThresholdName is the UpperCautionUser threshold.
+	UpperCautionUserThresholdName ThresholdName = "UpperCautionUser"
+	// UpperCriticalThresholdName is the UpperCritical threshold.
+	UpperCriticalThresholdName ThresholdName = "UpperCritical"
+	// UpperCriticalUserThresholdName is the UpperCriticalUser threshold.
+	UpperCriticalUserThresholdName ThresholdName = "UpperCriticalUser"
+	// UpperFatalThresholdName is the UpperFatal threshold.
+	UpperFatalThresholdName ThresholdName = "UpperFatal"
+)
+
+// thresholdNames lists the thresholds in the order they are reported.
+var thresholdNames = []ThresholdName{
+	LowerCautionThresholdName,
+	LowerCautionUserThresholdName,
+	LowerCriticalThresholdName,
+	LowerCriticalUserThresholdName,
+	LowerFatalThresholdName,
+	UpperCautionThresholdName,
+	UpperCautionUserThresholdName,
+	UpperCriticalThresholdName,
+	UpperCriticalUserThresholdName,
+	UpperFatalThresholdName,
+}
+
+// thresholdOrders lists the thresholds that must be in increasing order.
+var thresholdOrders = [][]ThresholdName{
+	{LowerFatalThresholdName, LowerCriticalThresholdName, LowerCautionThresholdName},
+	{LowerCriticalUserThresholdName, LowerCautionUserThresholdName},
+	{UpperCautionThresholdName, UpperCriticalThresholdName, UpperFatalThresholdName},
+	{UpperCautionUserThresholdName, UpperCriticalUserThresholdName},
+}
+
+// Health returns the health of a reading that violates the threshold.
+func (n ThresholdName) Health() Health {
+	switch n {
+	case LowerCautionThresholdName, LowerCautionUserThresholdName,
+		UpperCautionThresholdName, UpperCautionUserThresholdName:
+		return WarningHealth
+	default:
+		return CriticalHealth
+	}
+}
+
+// isUpper indicates whether the threshold is an upper limit.
+func (n ThresholdName) isUpper() bool {
+	switch n {
+	case UpperCautionThresholdName, UpperCautionUserThresholdName,
+		UpperCriticalThresholdName, UpperCriticalUserThresholdName, UpperFatalThresholdName:
+		return true
+	default:
+		return false
+	}
+}
+
+// Threshold returns the threshold with the name, or nil for an unknown name.
+func (t *Thresholds) Threshold(name ThresholdName) *Threshold {
+	switch name {
+	case LowerCautionThresholdName:
+		return &t.LowerCaution
+	case LowerCautionUserThresholdName:
+		return &t.LowerCautionUser
+	case LowerCriticalThresholdName:
+		return &t.LowerCritical
+	case LowerCriticalUserThresholdName:
+		return &t.LowerCriticalUser
+	case LowerFatalThresholdName:
+		return &t.LowerFatal
+	case UpperCautionThresholdName:
+		return &t.UpperCaution
+	case UpperCautionUserThresholdName:
+		return &t.UpperCautionUser
+	case UpperCriticalThresholdName:
+		return &t.UpperCritical
+	case UpperCriticalUserThresholdName:
+		return &t.UpperCriticalUser
+	case UpperFatalThresholdName:
+		return &t.UpperFatal
+	}
+	return nil
+}
+
+// enabled indicates whether the threshold has a value and is not disabled.
+func (t *Threshold) enabled() bool {
+	return t.Reading != nil && t.Activation != DisabledThresholdActivation
+}
+
+// increasing indicates whether the threshold is activated by increasing
+// readings. The activation gives the direction, and otherwise upper thresholds
+// are activated by increasing readings and lower thresholds by decreasing ones.
+func (t *Threshold) increasing(upper bool) bool {
+	switch t.Activation {
+	case IncreasingThresholdActivation:
+		return true
+	case DecreasingThresholdActivation:
+		return false
+	}
+	return upper
+}
+
+// violatedBy indicates whether a reading violates the threshold, by being at
+// or beyond it.
+func (t *Threshold) violatedBy(reading float64, upper bool) bool {
+	if t.increasing(upper) {
+		return reading >= *t.Reading
+	}
+	return reading <= *t.Reading
+}
+
+// clearedBy indicates whether a reading clears an active threshold, which
+// requires the reading to move back past the threshold by the hysteresis
+// offset.
+func (t *Threshold) clearedBy(reading float64, upper bool) bool {
+	var offset float64
+	if t.HysteresisReading != nil {
+		offset = math.Abs(*t.HysteresisReading)
+	}
+	if t.increasing(upper) {
+		return reading < *t.Reading-offset
+	}
+	return reading > *t.Reading+offset
+}
+
+// Classify returns the health of a reading from the thresholds it violates.
+// The dwell time and hysteresis of the thresholds are not applied, use a
+// ThresholdEvaluator for a series of readings.
+func (t *Thresholds) Classify(reading float64) Health {
+	health := OKHealth
+	for _, name := range thresholdNames {
+		threshold := t.Threshold(name)
+		if threshold.enabled() && threshold.violatedBy(reading, name.isUpper()) {
+			health = worseHealth(health, name.Health())
+		}
+	}
+	return health
+}
+
+func worseHealth(a, b Health) Health {
+	if a == CriticalHealth || b == CriticalHealth {
+		return CriticalHealth
+	}
+	if a == WarningHealth || b == WarningHealth {
+		return WarningHealth
+	}
+	return OKHealth
+}
+
+// thresholdState tracks whether a threshold is active and when a reading
+// started to change that.
+type thresholdState struct {
+	name               ThresholdName
+	threshold          Threshold
+	dwellTime          time.Duration
+	hysteresisDuration time.Duration
+	active             bool
+	pendingSince       time.Time
+}
+
+// ThresholdEvaluator classifies a series of readings of a sensor. A threshold
+// is activated once readings violate it for its DwellTime, and deactivated once
+// readings move past it by its HysteresisReading for its HysteresisDuration.
+type ThresholdEvaluator struct {
+	states []*thresholdState
+}
+
+// NewThresholdEvaluator creates a ThresholdEvaluator for the thresholds.
+func NewThresholdEvaluator(thresholds *Thresholds) (*ThresholdEvaluator, error) {
+	evaluator := &ThresholdEvaluator{}
+	for _, name := range thresholdNames {
+		threshold := thresholds.Threshold(name)
+		if !threshold.enabled() {
+			continue
+		}
+		state := &thresholdState{name: name, threshold: *threshold}
+		var err error
+		if threshold.DwellTime != "" {
+			if state.dwellTime, err = ParseRedfishDuration(threshold.DwellTime); err != nil {
+				return nil, fmt.Errorf("%s DwellTime: %w", name, err)
+			}
+		}
+		if threshold.HysteresisDuration != "" {
+			if state.hysteresisDuration, err = ParseRedfishDuration(threshold.HysteresisDuration); err != nil {
+				return nil, fmt.Errorf("%s HysteresisDuration: %w", name, err)
+			}
+		}
+		evaluator.states = append(evaluator.states, state)
+	}
+	return evaluator, nil
+}
+
+// ThresholdEvaluator creates a ThresholdEvaluator for the sensor's thresholds.
+func (s *Sensor) ThresholdEvaluator() (*ThresholdEvaluator, error) {
+	return NewThresholdEvaluator(&s.Thresholds)
+}
+
+// Evaluate updates the active thresholds with a reading taken at a time, and
+// returns the health of the sensor. Readings should be evaluated in the order
+// they were taken.
+func (e *ThresholdEvaluator) Evaluate(reading float64, at time.Time) Health {
+	health := OKHealth
+	for _, state := range e.states {
+		upper := state.name.isUpper()
+		var changing bool
+		var wait time.Duration
+		if state.active {
+			changing, wait = state.threshold.clearedBy(reading, upper), state.hysteresisDuration
+		} else {
+			changing, wait = state.threshold.violatedBy(reading, upper), state.dwellTime
+		}
+
+		switch {
+		case !changing:
+			state.pendingSince = time.Time{}
+		case state.pendingSince.IsZero() && wait > 0:
+			state.pendingSince = at
+		case at.Sub(state.pendingSince) >= wait:
+			state.active = !state.active
+			state.pendingSince = time.Time{}
+		}
+
+		if state.active {
+			health = worseHealth(health, state.name.Health())
+		}
+	}
+	return health
+}
+
+// Active returns the names of the active thresholds.
+func (e *ThresholdEvaluator) Active() []ThresholdName {
+	var active []ThresholdName
+	for _, state := range e.states {
+		if state.active {
+			active = append(active, state.name)
+		}
+	}
+	return active
+}
+
+// WritableThresholds returns the names of the thresholds that are likely to
+// be changeable. This is a heuristic: Redfish doesn't describe which
+// thresholds a service accepts, so the thresholds the sensor reports a reading
+// for are assumed to be writable. A service can still reject a change to one
+// of them, or may accept a threshold it doesn't report.
+func (s *Sensor) WritableThresholds() []ThresholdName {
+	original := s.originalThresholds()
+	if original == nil {
+		return nil
+	}
+
+	var writable []ThresholdName
+	for _, name := range thresholdNames {
+		if original.Threshold(name).Reading != nil {
+			writable = append(writable, name)
+		}
+	}
+	return writable
+}
+
+// originalThresholds returns the thresholds as they were read from the
+// service, which Update compares against to find the changes.
+func (s *Sensor) originalThresholds() *Thresholds {
+	if len(s.RawData) == 0 {
+		return nil
+	}
+	var original Sensor
+	if err := original.UnmarshalJSON(s.RawData); err != nil {
+		return nil
+	}
+	return &original.Thresholds
+}
+
+// ValidateThresholds checks the thresholds are within the reading range of
+// the sensor, and that the lower and upper limits are in order.
+func (s *Sensor) ValidateThresholds(thresholds *Thresholds) error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidThreshold, fmt.Sprintf(format, args...)))
+	}
+
+	var highestLower, lowestUpper ThresholdName
+	for _, name := range thresholdNames {
+		threshold := thresholds.Threshold(name)
+		if !threshold.enabled() {
+			continue
+		}
+		value := *threshold.Reading
+		if s.ReadingRangeMin != nil && value < *s.ReadingRangeMin {
+			invalid("%s %g is below the minimum reading %g", name, value, *s.ReadingRangeMin)
+		}
+		if s.ReadingRangeMax != nil && value > *s.ReadingRangeMax {
+			invalid("%s %g is above the maximum reading %g", name, value, *s.ReadingRangeMax)
+		}
+
+		if name.isUpper() {
+			if lowestUpper == "" || value < *thresholds.Threshold(lowestUpper).Reading {
+				lowestUpper = name
+			}
+		} else if highestLower == "" || value > *thresholds.Threshold(highestLower).Reading {
+			highestLower = name
+		}
+	}
+
+	for _, order := range thresholdOrders {
+		var previous ThresholdName
+		for _, name := range order {
+			threshold := thresholds.Threshold(name)
+			if !threshold.enabled() {
+				continue
+			}
+			if previous != "" && *threshold.Reading < *thresholds.Threshold(previous).Reading {
+				invalid("%s %g is below %s %g", name, *threshold.Reading, previous, *thresholds.Threshold(previous).Reading)
+			}
+			previous = name
+		}
+	}
+
+	if highestLower != "" && lowestUpper != "" {
+		lower, upper := *thresholds.Threshold(highestLower).Reading, *thresholds.Threshold(lowestUpper).Reading
+		if lower >= upper {
+			invalid("%s %g is not below %s %g", highestLower, lower, lowestUpper, upper)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SetThresholds validates the thresholds and sends any changes to the
+// service. Only the thresholds returned by WritableThresholds can be changed,
+// though the service can still reject them.
+// The sensor's Thresholds are left unchanged if the update fails.
+func (s *Sensor) SetThresholds(thresholds *Thresholds) error {
+	if err := s.ValidateThresholds(thresholds); err != nil {
+		return err
+	}
+
+	original := s.originalThresholds()
+	if original == nil {
+		original = &Thresholds{}
+	}
+	writable := s.WritableThresholds()
+	var errs []error
+	for _, name := range thresholdNames {
+		if reflect.DeepEqual(*original.Threshold(name), *thresholds.Threshold(name)) {
+			continue
+		}
+		if !slices.Contains(writable, name) {
+			errs = append(errs, fmt.Errorf("%w: Thresholds.%s", ErrNotWritable, name))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// The schema marks the properties of the thresholds writable rather than
+	// the Thresholds object, so it isn't one of the fields Update sends.
+	previous := s.Thresholds
+	s.Thresholds = *thresholds
+	if err := s.UpdateFromRawData(s, s.RawData, []string{"Thresholds"}); err != nil {
+		s.Thresholds = previous
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const thresholdSensorBody = `{
+		"@odata.id": "/redfish/v1/Chassis/1/Sensors/CPU1Temp",
+		"Id": "CPU1Temp",
+		"Reading": 45,
+		"ReadingUnits": "Cel",
+		"ReadingRangeMin": 0,
+		"ReadingRangeMax": 120,
+		"Thresholds": {
+			"LowerCaution": {"Reading": 10},
+			"UpperCaution": {"Reading": 80, "Activation": "Increasing", "HysteresisReading": -2},
+			"UpperCritical": {"Reading": 90, "DwellTime": "PT10S", "HysteresisDuration": "PT5S"},
+			"UpperFatal": {"Reading": 100, "Activation": "Disabled"}
+		}
+	}`
+
+func thresholdSensor(t *testing.T, testClient *TestClient) *Sensor {
+	var result Sensor
+	if err := json.Unmarshal([]byte(thresholdSensorBody), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(testClient)
+	result.DisableEtagMatch(true)
+	return &result
+}
+
+// TestThresholdsClassify tests readings are classified by the thresholds they
+// violate.
+func TestThresholdsClassify(t *testing.T) {
+	sensor := thresholdSensor(t, &TestClient{})
+
+	tests := []struct {
+		reading float64
+		health  Health
+	}{
+		{45, OKHealth},
+		{10, WarningHealth},
+		{5, WarningHealth},
+		{80, WarningHealth},
+		{95, CriticalHealth},
+		// The disabled fatal threshold is ignored
+		{110, CriticalHealth},
+	}
+	for _, tt := range tests {
+		AssertEqual(t, tt.health, sensor.Thresholds.Classify(tt.reading))
+	}
+}
+
+// TestThresholdEvaluator tests the dwell time and hysteresis of thresholds
+// are applied to a series of readings.
+func TestThresholdEvaluator(t *testing.T) {
+	sensor := thresholdSensor(t, &TestClient{})
+	evaluator, err := sensor.ThresholdEvaluator()
+	if err != nil {
+		t.Fatalf("Error creating evaluator: %s", err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	readings := []struct {
+		offset  time.Duration
+		reading float64
+		health  Health
+	}{
+		{0, 45, OKHealth},
+		// The critical threshold needs to be violated for its dwell time
+		{1 * time.Second, 92, WarningHealth},
+		{6 * time.Second, 93, WarningHealth},
+		{11 * time.Second, 91, CriticalHealth},
+		// And cleared for its hysteresis duration
+		{12 * time.Second, 85, CriticalHealth},
+		{17 * time.Second, 84, WarningHealth},
+		// The caution threshold stays active within its hysteresis reading
+		{18 * time.Second, 79, WarningHealth},
+		{19 * time.Second, 77.5, OKHealth},
+	}
+	for _, r := range readings {
+		AssertEqual(t, r.health, evaluator.Evaluate(r.reading, start.Add(r.offset)))
+	}
+	AssertEqual(t, 0, len(evaluator.Active()))
+
+	sensor.Thresholds.UpperCritical.DwellTime = "10 seconds"
+	if _, err := sensor.ThresholdEvaluator(); err == nil {
+		t.Error("Expected an invalid dwell time to be reported")
+	}
+}
+
+// TestSensorWritableThresholds tests the thresholds reported by the service
+// are writable.
+func TestSensorWritableThresholds(t *testing.T) {
+	sensor := thresholdSensor(t, &TestClient{})
+	AssertEqual(t, []ThresholdName{
+		LowerCautionThresholdName,
+		UpperCautionThresholdName,
+		UpperCriticalThresholdName,
+		UpperFatalThresholdName,
+	}, sensor.WritableThresholds())
+}
+
+// TestSensorValidateThresholds tests thresholds are checked against the
+// reading range and each other.
+func TestSensorValidateThresholds(t *testing.T) {
+	sensor := thresholdSensor(t, &TestClient{})
+
+	tests := []struct {
+		name   string
+		change func(*Thresholds)
+		errMsg string
+	}{
+		{
+			name:   "valid",
+			change: func(th *Thresholds) { th.UpperCaution.Reading = floatPtr(85) },
+		},
+		{
+			name:   "above range",
+			change: func(th *Thresholds) { th.UpperCritical.Reading = floatPtr(125) },
+			errMsg: "UpperCritical 125 is above the maximum reading 120",
+		},
+		{
+			name:   "below range",
+			change: func(th *Thresholds) { th.LowerCritical.Reading = floatPtr(-5) },
+			errMsg: "LowerCritical -5 is below the minimum reading 0",
+		},
+		{
+			name:   "upper out of order",
+			change: func(th *Thresholds) { th.UpperCaution.Reading = floatPtr(95) },
+			errMsg: "UpperCritical 90 is below UpperCaution 95",
+		},
+		{
+			name:   "lower out of order",
+			change: func(th *Thresholds) { th.LowerFatal.Reading = floatPtr(15) },
+			errMsg: "LowerCaution 10 is below LowerFatal 15",
+		},
+		{
+			name:   "lower above upper",
+			change: func(th *Thresholds) { th.LowerCautionUser.Reading = floatPtr(82) },
+			errMsg: "LowerCautionUser 82 is not below UpperCaution 80",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thresholds := sensor.Thresholds
+			tt.change(&thresholds)
+			err := sensor.ValidateThresholds(&thresholds)
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+				return
+			}
+			RequireErrorContains(t, err, tt.errMsg)
+			if !errors.Is(err, ErrInvalidThreshold) {
+				t.Errorf("Expected ErrInvalidThreshold, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestSensorSetThresholds tests only changed thresholds are sent, and
+// unsupported thresholds are rejected.
+func TestSensorSetThresholds(t *testing.T) {
+	testClient := &TestClient{}
+	sensor := thresholdSensor(t, testClient)
+
+	thresholds := sensor.Thresholds
+	thresholds.UpperCaution.Reading = floatPtr(85)
+	if err := sensor.SetThresholds(&thresholds); err != nil {
+		t.Fatalf("Error setting thresholds: %s", err)
+	}
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, 1, len(calls))
+	AssertEqual(t, http.MethodPatch, calls[0].Action)
+	AssertEqual(t, "/redfish/v1/Chassis/1/Sensors/CPU1Temp", calls[0].URL)
+	AssertEqual(t, "map[Thresholds:map[UpperCaution:map[Reading:85]]]", calls[0].Payload)
+	AssertEqual(t, 85.0, *sensor.Thresholds.UpperCaution.Reading)
+
+	thresholds = sensor.Thresholds
+	thresholds.LowerCriticalUser.Reading = floatPtr(5)
+	err := sensor.SetThresholds(&thresholds)
+	if !errors.Is(err, ErrNotWritable) {
+		t.Errorf("Expected ErrNotWritable, got: %v", err)
+	}
+
+	thresholds = sensor.Thresholds
+	thresholds.UpperCritical.Reading = floatPtr(70)
+	if err := sensor.SetThresholds(&thresholds); !errors.Is(err, ErrInvalidThreshold) {
+		t.Errorf("Expected ErrInvalidThreshold, got: %v", err)
+	}
+	AssertEqual(t, 1, len(testClient.CapturedCalls()))
+	AssertEqual(t, 90.0, *sensor.Thresholds.UpperCritical.Reading)
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
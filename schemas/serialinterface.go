@@ -212,9 +212,9 @@ func (s *SerialInterface) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *SerialInterface) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *SerialInterface) writableFields() []string {
+	return []string{
 		"BitRate",
 		"DataBits",
 		"FlowControl",
@@ -222,8 +222,11 @@ func (s *SerialInterface) Update() error {
 		"Parity",
 		"StopBits",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *SerialInterface) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetSerialInterface will get a SerialInterface instance from the service.
@@ -9,7 +9,6 @@ package schemas
 
 import (
 	"encoding/json"
-	"net/url"
 )
 
 type SessionTypes string
@@ -176,33 +175,12 @@ type AuthToken struct {
 	BasicAuth bool
 }
 
-type authPayload struct {
-	UserName string `json:"UserName"`
-	Password string `json:"Password"`
-}
-
 // CreateSession creates a new session and returns the token and id
 func CreateSession(c Client, uri, username, password string) (auth *AuthToken, err error) {
-	a := &authPayload{
+	return CreateSessionWithParameters(c, uri, &SessionCreateParameters{
 		UserName: username,
 		Password: password,
-	}
-
-	resp, err := c.Post(uri, a)
-	defer DeferredCleanupHTTPResponse(resp)
-	if err != nil {
-		return auth, err
-	}
-
-	auth = &AuthToken{}
-	auth.Token = resp.Header.Get("X-Auth-Token")
-	auth.Session = resp.Header.Get("Location")
-
-	if urlParser, err := url.ParseRequestURI(auth.Session); err == nil {
-		auth.Session = urlParser.RequestURI()
-	}
-
-	return auth, err
+	})
 }
 
 // DeleteSession deletes a session using the location as argument
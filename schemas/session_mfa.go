@@ -0,0 +1,176 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// totpPeriod is the RFC6238 time step used by Redfish services.
+	totpPeriod = 30
+	// totpDigits is the number of digits in a generated passcode.
+	totpDigits = 6
+)
+
+// ErrPasswordChangeRequired is returned, wrapped in a
+// PasswordChangeRequiredError, when the service requires the account password
+// to be changed before granting access.
+var ErrPasswordChangeRequired = errors.New("password change required")
+
+// SessionCreateParameters holds the properties used to create a session.
+type SessionCreateParameters struct {
+	// UserName is the account to log in with.
+	UserName string
+	// Password is the password for the account.
+	Password string
+	// Token is the multi-factor authentication token, such as an RFC6238 time
+	// based one-time passcode or an RSA SecurID passcode.
+	Token string `json:",omitempty"`
+	// Context is a client-supplied context that remains with the session.
+	Context string `json:",omitempty"`
+}
+
+// PasswordChangeRequiredError is returned when the service requires the
+// password to be changed before access is granted.
+type PasswordChangeRequiredError struct {
+	// Auth is the restricted session created by the service, if any. The
+	// session may only be used to change the account password.
+	Auth *AuthToken
+	// AccountURI is the account whose password must be changed, if the
+	// service reported it.
+	AccountURI string
+	// Messages are the messages returned by the service.
+	Messages []Message
+}
+
+func (e *PasswordChangeRequiredError) Error() string {
+	if e.AccountURI != "" {
+		return fmt.Sprintf("%s for account %s", ErrPasswordChangeRequired, e.AccountURI)
+	}
+	return ErrPasswordChangeRequired.Error()
+}
+
+func (e *PasswordChangeRequiredError) Unwrap() error {
+	return ErrPasswordChangeRequired
+}
+
+// passwordChangeRequired looks for the Base registry PasswordChangeRequired
+// message, returning the account URI from its arguments.
+func passwordChangeRequired(messages []Message) (accountURI string, found bool) {
+	for i := range messages {
+		if strings.HasSuffix(messages[i].MessageID, ".PasswordChangeRequired") {
+			if len(messages[i].MessageArgs) > 0 {
+				accountURI = messages[i].MessageArgs[0]
+			}
+			return accountURI, true
+		}
+	}
+	return "", false
+}
+
+// CreateSessionWithParameters creates a new session and returns the token and
+// id. This allows multi-factor authentication tokens and a session context to
+// be provided.
+//
+// If the service requires the password to be changed, a
+// PasswordChangeRequiredError is returned. When the service created a
+// restricted session it is available from the error, and can be used with
+// ChangeRequiredPassword.
+func CreateSessionWithParameters(c Client, uri string, params *SessionCreateParameters) (*AuthToken, error) {
+	resp, err := c.Post(uri, params)
+	defer DeferredCleanupHTTPResponse(resp)
+	if err != nil {
+		var redfishErr *Error
+		if errors.As(err, &redfishErr) {
+			messages := make([]Message, len(redfishErr.ExtendedInfos))
+			for i := range redfishErr.ExtendedInfos {
+				messages[i] = Message(redfishErr.ExtendedInfos[i])
+			}
+			if accountURI, found := passwordChangeRequired(messages); found {
+				return nil, &PasswordChangeRequiredError{AccountURI: accountURI, Messages: messages}
+			}
+		}
+		return nil, err
+	}
+
+	auth := &AuthToken{}
+	auth.Token = resp.Header.Get("X-Auth-Token")
+	auth.Session = resp.Header.Get("Location")
+
+	if urlParser, err := url.ParseRequestURI(auth.Session); err == nil {
+		auth.Session = urlParser.RequestURI()
+	}
+
+	// The body is optional, so decoding failures are not an error
+	var body struct {
+		ExtendedInfo []Message `json:"@Message.ExtendedInfo"`
+	}
+	if resp.Body != nil {
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+	}
+	if accountURI, found := passwordChangeRequired(body.ExtendedInfo); found {
+		return auth, &PasswordChangeRequiredError{Auth: auth, AccountURI: accountURI, Messages: body.ExtendedInfo}
+	}
+
+	return auth, nil
+}
+
+// ChangeRequiredPassword sets a new password on an account that requires a
+// password change. The client should be authenticated with the restricted
+// session returned in the PasswordChangeRequiredError.
+func ChangeRequiredPassword(c Client, accountURI, newPassword string) error {
+	if accountURI == "" {
+		return errors.New("the account requiring a password change is not known")
+	}
+
+	payload := struct {
+		Password string
+	}{Password: newPassword}
+	resp, err := c.Patch(accountURI, payload)
+	defer DeferredCleanupHTTPResponse(resp)
+	return err
+}
+
+// GenerateTOTP returns the RFC6238 time based one-time passcode for the
+// base32 encoded secret at time t. The secret is the value returned by
+// ManagerAccount.GenerateSecretKey.
+func GenerateTOTP(secret string, t time.Time) (string, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/totpPeriod)) //nolint:gosec
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation as described in RFC4226
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, code%1000000), nil
+}
+
+// TOTPTokenSource returns a function that generates the current time based
+// one-time passcode for the secret each time it is called.
+func TOTPTokenSource(secret string) func() (string, error) {
+	return func() (string, error) {
+		return GenerateTOTP(secret, time.Now())
+	}
+}
@@ -0,0 +1,73 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGenerateTOTP tests passcodes against the RFC6238 SHA1 test vectors.
+func TestGenerateTOTP(t *testing.T) {
+	// Base32 encoding of the RFC6238 secret "12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	tests := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		2000000000: "279037",
+	}
+	for unix, expected := range tests {
+		code, err := GenerateTOTP(secret, time.Unix(unix, 0))
+		if err != nil {
+			t.Fatalf("Error generating TOTP: %s", err)
+		}
+		AssertEqual(t, expected, code)
+	}
+
+	_, err := GenerateTOTP("not base32!", time.Now())
+	RequireErrorContains(t, err, "invalid TOTP secret")
+}
+
+// TestCreateSessionWithParameters tests sending MFA tokens and detecting a
+// required password change.
+func TestCreateSessionWithParameters(t *testing.T) {
+	header := make(http.Header)
+	header.Set("X-Auth-Token", "restricted")
+	header.Set("Location", "https://bmc.example.com/redfish/v1/SessionService/Sessions/7")
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodPost: {
+				&http.Response{
+					StatusCode: http.StatusCreated,
+					Header:     header,
+					Body: io.NopCloser(strings.NewReader(`{"@Message.ExtendedInfo": [{
+						"MessageId": "Base.1.18.PasswordChangeRequired",
+						"MessageArgs": ["/redfish/v1/AccountService/Accounts/3"]}]}`)),
+				},
+			},
+		},
+	}
+
+	auth, err := CreateSessionWithParameters(testClient, "/redfish/v1/SessionService/Sessions",
+		&SessionCreateParameters{UserName: "admin", Password: "secret", Token: "123456", Context: "automation"})
+
+	var passwordErr *PasswordChangeRequiredError
+	if !errors.As(err, &passwordErr) || !errors.Is(err, ErrPasswordChangeRequired) {
+		t.Fatalf("Expected PasswordChangeRequiredError, got %v", err)
+	}
+	AssertEqual(t, "/redfish/v1/AccountService/Accounts/3", passwordErr.AccountURI)
+	AssertEqual(t, "restricted", auth.Token)
+	AssertEqual(t, "/redfish/v1/SessionService/Sessions/7", passwordErr.Auth.Session)
+
+	calls := testClient.CapturedCalls()
+	if !strings.Contains(calls[0].Payload, "Token:123456") || !strings.Contains(calls[0].Payload, "Context:automation") {
+		t.Errorf("Unexpected session payload: %s", calls[0].Payload)
+	}
+}
@@ -81,16 +81,19 @@ func (s *SessionService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *SessionService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *SessionService) writableFields() []string {
+	return []string{
 		"AbsoluteSessionTimeout",
 		"AbsoluteSessionTimeoutEnabled",
 		"ServiceEnabled",
 		"SessionTimeout",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *SessionService) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetSessionService will get a SessionService instance from the service.
@@ -743,9 +743,9 @@ func (s *SFVolume) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *SFVolume) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *SFVolume) writableFields() []string {
+	return []string{
 		"ALUA",
 		"AccessCapabilities",
 		"Capacity",
@@ -776,8 +776,11 @@ func (s *SFVolume) Update() error {
 		"WriteCachePolicy",
 		"WriteHoleProtectionPolicy",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.rawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *SFVolume) Update() error {
+	return s.UpdateFromRawData(s, s.rawData, s.writableFields())
 }
 
 // GetSFVolume will get a SFVolume instance from the service.
@@ -207,13 +207,16 @@ func (s *SoftwareInventory) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *SoftwareInventory) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *SoftwareInventory) writableFields() []string {
+	return []string{
 		"WriteProtected",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *SoftwareInventory) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetSoftwareInventory will get a SoftwareInventory instance from the service.
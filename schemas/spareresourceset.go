@@ -71,16 +71,19 @@ func (s *SpareResourceSet) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *SpareResourceSet) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *SpareResourceSet) writableFields() []string {
+	return []string{
 		"OnLine",
 		"ResourceType",
 		"TimeToProvision",
 		"TimeToReplenish",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *SpareResourceSet) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetSpareResourceSet will get a SpareResourceSet instance from the service.
@@ -436,9 +436,9 @@ func (s *Storage) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *Storage) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *Storage) writableFields() []string {
+	return []string{
 		"AutoVolumeCreate",
 		"BlockSecurityIDPolicy",
 		"ConfigurationLock",
@@ -446,8 +446,11 @@ func (s *Storage) Update() error {
 		"HotspareActivationPolicy",
 		"TargetConfigurationLockLevel",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *Storage) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetStorage will get a Storage instance from the service.
@@ -1180,13 +1183,16 @@ func (s *StorageStorageController) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *StorageStorageController) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *StorageStorageController) writableFields() []string {
+	return []string{
 		"AssetTag",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *StorageStorageController) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetStorageStorageController will get a StorageStorageController instance from the service.
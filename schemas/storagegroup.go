@@ -177,9 +177,9 @@ func (s *StorageGroup) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *StorageGroup) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *StorageGroup) writableFields() []string {
+	return []string{
 		"AccessState",
 		"AuthenticationMethod",
 		"ClientEndpointGroups",
@@ -188,8 +188,11 @@ func (s *StorageGroup) Update() error {
 		"Volumes",
 		"VolumesAreExposed",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *StorageGroup) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetStorageGroup will get a StorageGroup instance from the service.
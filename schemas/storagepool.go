@@ -301,9 +301,9 @@ func (s *StoragePool) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *StoragePool) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *StoragePool) writableFields() []string {
+	return []string{
 		"CapacitySources",
 		"ClassesOfService",
 		"Compressed",
@@ -321,8 +321,11 @@ func (s *StoragePool) Update() error {
 		"ReplicationEnabled",
 		"SupportedProvisioningPolicies",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *StoragePool) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetStoragePool will get a StoragePool instance from the service.
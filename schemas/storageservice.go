@@ -229,9 +229,9 @@ func (s *StorageService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *StorageService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *StorageService) writableFields() []string {
+	return []string{
 		"ClassesOfService",
 		"ClientEndpointGroups",
 		"ConsistencyGroups",
@@ -248,8 +248,11 @@ func (s *StorageService) Update() error {
 		"SpareResourceSets",
 		"Volumes",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *StorageService) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetStorageService will get a StorageService instance from the service.
@@ -0,0 +1,294 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// SubscriptionSpec is the desired state of an event subscription. A
+// subscription is identified by its Destination and Context.
+type SubscriptionSpec struct {
+	// Destination is the URI events are sent to.
+	Destination string
+	// Context is sent with each event. It is required.
+	Context string
+	// Protocol is the protocol events are sent with. Defaults to Redfish.
+	Protocol EventDestinationProtocol
+	// EventFormatType is the format of the events, if set.
+	EventFormatType EventFormatType
+	// RegistryPrefixes limits the events to those from these message
+	// registries. If empty, the registries are unspecified: a new
+	// subscription receives events from all registries, and an existing one
+	// is kept whatever registries it is limited to.
+	RegistryPrefixes []string
+	// ResourceTypes limits the events to those originating from these types
+	// of resource. If empty, the resource types are unspecified, as for
+	// RegistryPrefixes.
+	ResourceTypes []string
+	// OriginResources limits the events to those originating from these
+	// resources. If empty, the resources are unspecified, as for
+	// RegistryPrefixes.
+	OriginResources []string
+	// DeliveryRetryPolicy is the policy for retrying failed deliveries, if
+	// set.
+	DeliveryRetryPolicy DeliveryRetryPolicy
+	// HTTPHeaders are sent with each event. Services don't return their
+	// values, so changes to them are not detected.
+	HTTPHeaders map[string]string
+}
+
+// EnsureSubscriptionOptions control how subscriptions are reconciled.
+type EnsureSubscriptionOptions struct {
+	// Owner is a prefix of the Context of the subscriptions owned by the
+	// caller. Owned subscriptions that don't match a desired subscription are
+	// deleted. If empty, only duplicates of the desired subscriptions are
+	// deleted.
+	Owner string
+	// DeliverBufferedEventDuration is the age of the buffered events to
+	// deliver when a suspended subscription is resumed, such as "PT1H".
+	DeliverBufferedEventDuration string
+}
+
+// SubscriptionReconciliation is the outcome of reconciling subscriptions.
+type SubscriptionReconciliation struct {
+	// Subscriptions are the URIs of the desired subscriptions, in the same
+	// order.
+	Subscriptions []string
+	// Created are the URIs of the subscriptions created.
+	Created []string
+	// Updated are the URIs of the subscriptions with a changed
+	// DeliveryRetryPolicy.
+	Updated []string
+	// Resumed are the URIs of the suspended subscriptions resumed.
+	Resumed []string
+	// Deleted are the URIs of the duplicate, outdated or unwanted
+	// subscriptions deleted.
+	Deleted []string
+}
+
+// Changed returns whether reconciling changed any subscriptions.
+func (r *SubscriptionReconciliation) Changed() bool {
+	return len(r.Created)+len(r.Updated)+len(r.Resumed)+len(r.Deleted) > 0
+}
+
+// EnsureSubscription makes sure a subscription matching the spec exists and
+// is not suspended. See EnsureSubscriptions.
+func (e *EventService) EnsureSubscription(spec *SubscriptionSpec, opts *EnsureSubscriptionOptions) (string, *SubscriptionReconciliation, error) {
+	result, err := e.EnsureSubscriptions([]*SubscriptionSpec{spec}, opts)
+	if err != nil || len(result.Subscriptions) == 0 {
+		return "", result, err
+	}
+	return result.Subscriptions[0], result, nil
+}
+
+// EnsureSubscriptions reconciles the service's subscriptions with the desired
+// subscriptions, so calling it again with the same specs changes nothing.
+//
+// For each spec, an existing subscription with the same destination, context
+// and settings is kept and resumed if it was suspended. Its
+// DeliveryRetryPolicy is updated if that is all that differs. Otherwise a new
+// subscription is created. Any other subscriptions with the same destination
+// and context are deleted as duplicates, as are owned subscriptions that
+// aren't desired.
+func (e *EventService) EnsureSubscriptions(specs []*SubscriptionSpec, opts *EnsureSubscriptionOptions) (*SubscriptionReconciliation, error) {
+	if opts == nil {
+		opts = &EnsureSubscriptionOptions{}
+	}
+	for _, spec := range specs {
+		if err := spec.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := e.Subscriptions()
+	if err != nil {
+		return nil, err
+	}
+	// Keep the reconciliation stable, the collection is read concurrently
+	slices.SortFunc(existing, func(a, b *EventDestination) int {
+		return strings.Compare(a.ODataID, b.ODataID)
+	})
+
+	result := &SubscriptionReconciliation{}
+	kept := make(map[string]bool)
+	var errs []error
+
+	for _, spec := range specs {
+		uri, err := e.ensureSubscription(spec, existing, kept, opts, result)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("subscription to %s: %w", spec.Destination, err))
+		}
+		result.Subscriptions = append(result.Subscriptions, uri)
+	}
+
+	if opts.Owner != "" {
+		for _, subscription := range existing {
+			if kept[subscription.ODataID] || !strings.HasPrefix(subscription.Context, opts.Owner) {
+				continue
+			}
+			if err := e.deleteSubscription(subscription, result); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// ensureSubscription reconciles a desired subscription, returning its URI.
+func (e *EventService) ensureSubscription(spec *SubscriptionSpec, existing []*EventDestination, kept map[string]bool,
+	opts *EnsureSubscriptionOptions, result *SubscriptionReconciliation) (string, error) {
+	var keep *EventDestination
+	var others []*EventDestination
+	for _, subscription := range existing {
+		if kept[subscription.ODataID] || !spec.identifies(subscription) {
+			continue
+		}
+		// Prefer a matching subscription that isn't suspended
+		if spec.matches(subscription) && (keep == nil || (keep.suspended() && !subscription.suspended())) {
+			if keep != nil {
+				others = append(others, keep)
+			}
+			keep = subscription
+			continue
+		}
+		others = append(others, subscription)
+	}
+
+	// Suspended subscriptions that can't be resumed are replaced
+	if keep != nil && keep.suspended() && keep.resumeSubscriptionTarget == "" {
+		others = append(others, keep)
+		keep = nil
+	}
+
+	// Remove duplicates first, services have a limited number of subscriptions
+	for _, subscription := range others {
+		kept[subscription.ODataID] = true
+		if err := e.deleteSubscription(subscription, result); err != nil {
+			return "", err
+		}
+	}
+
+	if keep == nil {
+		uri, err := e.createSubscription(spec)
+		if err != nil {
+			return "", err
+		}
+		kept[uri] = true
+		result.Created = append(result.Created, uri)
+		return uri, nil
+	}
+	kept[keep.ODataID] = true
+
+	if spec.DeliveryRetryPolicy != "" && keep.DeliveryRetryPolicy != "" && keep.DeliveryRetryPolicy != spec.DeliveryRetryPolicy {
+		keep.DeliveryRetryPolicy = spec.DeliveryRetryPolicy
+		if err := keep.Update(); err != nil {
+			return keep.ODataID, err
+		}
+		result.Updated = append(result.Updated, keep.ODataID)
+	}
+
+	if keep.suspended() {
+		if _, err := keep.ResumeSubscription(opts.DeliverBufferedEventDuration); err != nil {
+			return keep.ODataID, err
+		}
+		result.Resumed = append(result.Resumed, keep.ODataID)
+	}
+
+	return keep.ODataID, nil
+}
+
+func (e *EventService) deleteSubscription(subscription *EventDestination, result *SubscriptionReconciliation) error {
+	if err := e.DeleteEventSubscription(subscription.ODataID); err != nil {
+		return fmt.Errorf("deleting subscription %s: %w", subscription.ODataID, err)
+	}
+	result.Deleted = append(result.Deleted, subscription.ODataID)
+	return nil
+}
+
+// createSubscription creates a subscription from the spec.
+func (e *EventService) createSubscription(spec *SubscriptionSpec) (string, error) {
+	if e.SubscriptionsLink == "" {
+		return "", errors.New("empty subscription link in the event service")
+	}
+
+	s := &subscriptionPayload{
+		EventFormatType:     spec.EventFormatType,
+		RegistryPrefixes:    spec.RegistryPrefixes,
+		ResourceTypes:       spec.ResourceTypes,
+		DeliveryRetryPolicy: spec.DeliveryRetryPolicy,
+	}
+	for _, origin := range spec.OriginResources {
+		s.OriginResources = append(s.OriginResources, map[string]string{"@odata.id": origin})
+	}
+
+	return sendCreateEventDestinationRequest(e.GetClient(), s, e.SubscriptionsLink, spec.Destination,
+		spec.HTTPHeaders, spec.protocol(), spec.Context, nil)
+}
+
+func (spec *SubscriptionSpec) validate() error {
+	if spec == nil {
+		return errors.New("subscription spec is required")
+	}
+	if strings.TrimSpace(spec.Destination) == "" {
+		return errors.New("empty destination is not valid")
+	}
+	if _, err := url.ParseRequestURI(spec.Destination); err != nil {
+		return err
+	}
+	if strings.TrimSpace(spec.Context) == "" {
+		return errors.New("the required property context should be defined")
+	}
+	return nil
+}
+
+func (spec *SubscriptionSpec) protocol() EventDestinationProtocol {
+	if spec.Protocol == "" {
+		return RedfishEventDestinationProtocol
+	}
+	return spec.Protocol
+}
+
+// identifies returns whether the subscription is for the spec's destination
+// and context, whatever its other settings.
+func (spec *SubscriptionSpec) identifies(subscription *EventDestination) bool {
+	return strings.TrimSuffix(subscription.Destination, "/") == strings.TrimSuffix(spec.Destination, "/") &&
+		subscription.Context == spec.Context
+}
+
+// matches returns whether the subscription has the spec's settings, other
+// than the DeliveryRetryPolicy which can be updated. A subscription matches if
+// it has the spec's protocol and event format, and the same registry
+// prefixes, resource types and origin resources, in any order. Settings the
+// spec leaves empty and settings the service doesn't report are assumed to
+// match.
+func (spec *SubscriptionSpec) matches(subscription *EventDestination) bool {
+	if subscription.Protocol != "" && subscription.Protocol != spec.protocol() {
+		return false
+	}
+	if spec.EventFormatType != "" && subscription.EventFormatType != "" && subscription.EventFormatType != spec.EventFormatType {
+		return false
+	}
+	return matchesFilter(spec.RegistryPrefixes, subscription.RegistryPrefixes) &&
+		matchesFilter(spec.ResourceTypes, subscription.ResourceTypes) &&
+		matchesFilter(spec.OriginResources, subscription.OriginResources)
+}
+
+// matchesFilter returns whether a subscription's filter has the same elements
+// as the spec's, or the spec leaves the filter unspecified.
+func matchesFilter(spec, actual []string) bool {
+	return len(spec) == 0 || sameElements(spec, actual)
+}
+
+// suspended returns whether the subscription is suspended, in which case its
+// state is Disabled.
+func (e *EventDestination) suspended() bool {
+	return e.Status.State == DisabledState
+}
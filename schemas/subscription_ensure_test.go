@@ -0,0 +1,189 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const subscriptionsURI = "/redfish/v1/EventService/Subscriptions"
+
+// fakeSubscriptionClient serves subscriptions by URI, recording other
+// requests with the TestClient.
+type fakeSubscriptionClient struct {
+	*TestClient
+	subscriptions map[string]string
+}
+
+func (c *fakeSubscriptionClient) Get(uri string) (*http.Response, error) {
+	return c.GetWithHeaders(uri, nil)
+}
+
+func (c *fakeSubscriptionClient) GetWithHeaders(uri string, _ map[string]string) (*http.Response, error) {
+	if uri == subscriptionsURI {
+		members := make([]string, 0, len(c.subscriptions))
+		for member := range c.subscriptions {
+			members = append(members, fmt.Sprintf(`{"@odata.id": %q}`, member))
+		}
+		return getCall(fmt.Sprintf(`{"Members": [%s]}`, strings.Join(members, ","))), nil
+	}
+	body, ok := c.subscriptions[uri]
+	if !ok {
+		return nil, ConstructError(http.StatusNotFound, nil)
+	}
+	return getCall(body), nil
+}
+
+func subscriptionBody(id, destination, context, state, extra string) string {
+	return fmt.Sprintf(`{"@odata.id": "%s/%s", "Id": %q, "Destination": %q, "Context": %q,
+		"Protocol": "Redfish", "RegistryPrefixes": ["Base", "ResourceEvent"], "DeliveryRetryPolicy": "SuspendRetries",
+		"Status": {"State": %q},
+		"Actions": {"#EventDestination.ResumeSubscription": {"target": "%s/%s/Actions/EventDestination.ResumeSubscription"}}%s}`,
+		subscriptionsURI, id, id, destination, context, state, subscriptionsURI, id, extra)
+}
+
+func ensureEventService(t *testing.T, c Client) *EventService {
+	var result EventService
+	body := `{"@odata.id": "/redfish/v1/EventService", "Id": "EventService",
+		"Subscriptions": {"@odata.id": "/redfish/v1/EventService/Subscriptions"}}`
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(c)
+	return &result
+}
+
+func ensureSpec() *SubscriptionSpec {
+	return &SubscriptionSpec{
+		Destination:         "https://collector.example.com/events",
+		Context:             "monitor:dc1",
+		RegistryPrefixes:    []string{"ResourceEvent", "Base"},
+		DeliveryRetryPolicy: SuspendRetriesDeliveryRetryPolicy,
+	}
+}
+
+// TestEnsureSubscriptionUnchanged tests a matching subscription is left alone.
+func TestEnsureSubscriptionUnchanged(t *testing.T) {
+	client := &fakeSubscriptionClient{TestClient: &TestClient{}, subscriptions: map[string]string{
+		subscriptionsURI + "/1": subscriptionBody("1", "https://collector.example.com/events/", "monitor:dc1", "Enabled", ""),
+		subscriptionsURI + "/2": subscriptionBody("2", "https://other.example.com/events", "someone-else", "Enabled", ""),
+	}}
+
+	uri, result, err := ensureEventService(t, client).EnsureSubscription(ensureSpec(), &EnsureSubscriptionOptions{Owner: "monitor:"})
+	if err != nil {
+		t.Fatalf("Error ensuring subscription: %s", err)
+	}
+	AssertEqual(t, subscriptionsURI+"/1", uri)
+	AssertEqual(t, false, result.Changed())
+	AssertEqual(t, 0, len(client.CapturedCalls()))
+}
+
+// TestEnsureSubscriptionUnspecifiedFilters tests filters the spec leaves empty
+// match any filters of the subscription, while ones it sets must be the same.
+func TestEnsureSubscriptionUnspecifiedFilters(t *testing.T) {
+	client := &fakeSubscriptionClient{TestClient: &TestClient{}, subscriptions: map[string]string{
+		subscriptionsURI + "/1": subscriptionBody("1", "https://collector.example.com/events", "monitor:dc1", "Enabled",
+			`, "ResourceTypes": ["Chassis"], "OriginResources": ["/redfish/v1/Chassis/1"]`),
+	}}
+
+	spec := ensureSpec()
+	spec.RegistryPrefixes = nil
+	spec.ResourceTypes = []string{}
+	if !spec.matches(mustSubscription(t, client, "1")) {
+		t.Error("Expected a spec without filters to match")
+	}
+
+	spec.RegistryPrefixes = []string{"Base"}
+	if spec.matches(mustSubscription(t, client, "1")) {
+		t.Error("Expected different registry prefixes not to match")
+	}
+
+	spec.RegistryPrefixes = []string{"ResourceEvent", "Base"}
+	spec.OriginResources = []string{"/redfish/v1/Chassis/1"}
+	if !spec.matches(mustSubscription(t, client, "1")) {
+		t.Error("Expected the same filters in another order to match")
+	}
+}
+
+func mustSubscription(t *testing.T, c Client, id string) *EventDestination {
+	subscription, err := GetEventDestination(c, subscriptionsURI+"/"+id)
+	if err != nil {
+		t.Fatalf("Error getting subscription: %s", err)
+	}
+	return subscription
+}
+
+// TestEnsureSubscriptionReconcile tests duplicates and unwanted owned
+// subscriptions are deleted and suspended ones are resumed.
+func TestEnsureSubscriptionReconcile(t *testing.T) {
+	client := &fakeSubscriptionClient{TestClient: &TestClient{}, subscriptions: map[string]string{
+		subscriptionsURI + "/1": subscriptionBody("1", "https://collector.example.com/events", "monitor:dc1", "Disabled", ""),
+		subscriptionsURI + "/2": subscriptionBody("2", "https://collector.example.com/events", "monitor:dc1", "Disabled", ""),
+		subscriptionsURI + "/3": subscriptionBody("3", "https://old.example.com/events", "monitor:old", "Enabled", ""),
+		subscriptionsURI + "/4": subscriptionBody("4", "https://other.example.com/events", "someone-else", "Enabled", ""),
+	}}
+
+	uri, result, err := ensureEventService(t, client).EnsureSubscription(ensureSpec(),
+		&EnsureSubscriptionOptions{Owner: "monitor:", DeliverBufferedEventDuration: "PT1H"})
+	if err != nil {
+		t.Fatalf("Error ensuring subscription: %s", err)
+	}
+	AssertEqual(t, subscriptionsURI+"/1", uri)
+	AssertEqual(t, subscriptionsURI+"/1", strings.Join(result.Resumed, ","))
+	AssertEqual(t, subscriptionsURI+"/2,"+subscriptionsURI+"/3", strings.Join(result.Deleted, ","))
+
+	calls := client.CapturedCalls()
+	AssertEqual(t, 3, len(calls))
+	AssertEqual(t, http.MethodDelete, calls[0].Action)
+	AssertEqual(t, subscriptionsURI+"/1/Actions/EventDestination.ResumeSubscription", calls[1].URL)
+	AssertEqual(t, "map[DeliverBufferedEventDuration:PT1H]", calls[1].Payload)
+}
+
+// TestEnsureSubscriptionRecreate tests a subscription with different settings
+// is replaced, and one differing only in retry policy is updated.
+func TestEnsureSubscriptionRecreate(t *testing.T) {
+	created := &http.Response{StatusCode: http.StatusCreated, Header: make(http.Header), Body: http.NoBody}
+	created.Header.Set("Location", "https://bmc.example.com"+subscriptionsURI+"/9")
+	client := &fakeSubscriptionClient{
+		TestClient: &TestClient{CustomReturnForActions: map[string][]any{http.MethodPost: {created}}},
+		subscriptions: map[string]string{
+			subscriptionsURI + "/1": subscriptionBody("1", "https://collector.example.com/events", "monitor:dc1", "Enabled",
+				`, "ResourceTypes": ["Chassis"]`),
+		},
+	}
+
+	spec := ensureSpec()
+	spec.OriginResources = []string{"/redfish/v1/Chassis/1"}
+	uri, result, err := ensureEventService(t, client).EnsureSubscription(spec, nil)
+	if err != nil {
+		t.Fatalf("Error ensuring subscription: %s", err)
+	}
+	AssertEqual(t, subscriptionsURI+"/9", uri)
+	AssertEqual(t, subscriptionsURI+"/1", strings.Join(result.Deleted, ","))
+
+	calls := client.CapturedCalls()
+	AssertEqual(t, http.MethodPost, calls[1].Action)
+	AssertEqual(t, "map[Context:monitor:dc1 DeliveryRetryPolicy:SuspendRetries Destination:https://collector.example.com/events "+
+		"OriginResources:[map[@odata.id:/redfish/v1/Chassis/1]] Protocol:Redfish RegistryPrefixes:[ResourceEvent Base]]", calls[1].Payload)
+
+	client.TestClient.Reset()
+	client.subscriptions = map[string]string{
+		subscriptionsURI + "/1": subscriptionBody("1", "https://collector.example.com/events", "monitor:dc1", "Enabled", ""),
+	}
+	spec = ensureSpec()
+	spec.DeliveryRetryPolicy = RetryForeverDeliveryRetryPolicy
+	_, result, err = ensureEventService(t, client).EnsureSubscription(spec, nil)
+	if err != nil {
+		t.Fatalf("Error ensuring subscription: %s", err)
+	}
+	AssertEqual(t, subscriptionsURI+"/1", strings.Join(result.Updated, ","))
+	calls = client.CapturedCalls()
+	AssertEqual(t, http.MethodPatch, calls[0].Action)
+	AssertEqual(t, "map[DeliveryRetryPolicy:RetryForever]", calls[0].Payload)
+}
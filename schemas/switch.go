@@ -220,17 +220,20 @@ func (s *Switch) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (s *Switch) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (s *Switch) writableFields() []string {
+	return []string{
 		"AssetTag",
 		"Enabled",
 		"IndicatorLED",
 		"IsManaged",
 		"LocationIndicatorActive",
 	}
+}
 
-	return s.UpdateFromRawData(s, s.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (s *Switch) Update() error {
+	return s.UpdateFromRawData(s, s.RawData, s.writableFields())
 }
 
 // GetSwitch will get a Switch instance from the service.
@@ -57,15 +57,18 @@ func (t *TargetEndpointGroup) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (t *TargetEndpointGroup) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (t *TargetEndpointGroup) writableFields() []string {
+	return []string{
 		"AccessState",
 		"Preferred",
 		"TargetEndpointGroupIdentifier",
 	}
+}
 
-	return t.UpdateFromRawData(t, t.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (t *TargetEndpointGroup) Update() error {
+	return t.UpdateFromRawData(t, t.RawData, t.writableFields())
 }
 
 // GetTargetEndpointGroup will get a TargetEndpointGroup instance from the service.
@@ -98,15 +98,18 @@ func (t *TaskService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (t *TaskService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (t *TaskService) writableFields() []string {
+	return []string{
 		"ServiceEnabled",
 		"TaskAutoDeleteTimeoutMinutes",
 		"TaskMonitorAutoExpirySeconds",
 	}
+}
 
-	return t.UpdateFromRawData(t, t.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (t *TaskService) Update() error {
+	return t.UpdateFromRawData(t, t.RawData, t.writableFields())
 }
 
 // GetTaskService will get a TaskService instance from the service.
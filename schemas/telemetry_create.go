@@ -0,0 +1,555 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTelemetrySpec is returned when a metric report definition or
+// trigger is not valid for the service.
+var ErrInvalidTelemetrySpec = errors.New("invalid telemetry definition")
+
+// wildcardPattern matches the wildcards in metric properties, such as "{ID}".
+var wildcardPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// MetricReportDefinitionSpec describes a metric report definition to create.
+type MetricReportDefinitionSpec struct {
+	// ID is the Id of the definition, and of the metric report it produces.
+	ID string `json:"Id"`
+	// Name is the name of the definition.
+	Name string `json:",omitempty"`
+	// MetricReportDefinitionType is when the metric report is produced.
+	MetricReportDefinitionType MetricReportDefinitionType
+	// MetricReportDefinitionEnabled is whether the definition is enabled.
+	MetricReportDefinitionEnabled bool
+	// Metrics are the metrics to include in the report.
+	Metrics []Metric
+	// Wildcards are the values of the wildcards in the metric properties.
+	Wildcards []Wildcard `json:",omitempty"`
+	// ReportActions are what is done when the report is produced.
+	ReportActions []ReportActionsEnum `json:",omitempty"`
+	// ReportUpdates is how subsequent reports are handled.
+	ReportUpdates ReportUpdatesEnum `json:",omitempty"`
+	// AppendLimit is the maximum number of entries in the report when
+	// ReportUpdates appends.
+	AppendLimit uint `json:",omitempty"`
+	// Schedule is when periodic reports are produced.
+	Schedule Schedule
+	// ReportTimespan is the maximum timespan covered by the report.
+	ReportTimespan string `json:",omitempty"`
+	// MetricReportHeartbeatInterval is the interval of heartbeat reports for
+	// OnChange definitions.
+	MetricReportHeartbeatInterval string `json:",omitempty"`
+	// SuppressRepeatedMetricValue is whether unchanged values are left out
+	// of the report.
+	SuppressRepeatedMetricValue bool `json:",omitempty"`
+}
+
+// NewPeriodicMetricReportDefinition creates a spec for a report produced at
+// each interval and kept in the metric reports collection.
+func NewPeriodicMetricReportDefinition(id string, interval time.Duration, metrics ...Metric) *MetricReportDefinitionSpec {
+	spec := newMetricReportDefinitionSpec(id, PeriodicMetricReportDefinitionType, metrics)
+	spec.Schedule.RecurrenceInterval = FormatRedfishDuration(interval)
+	return spec
+}
+
+// NewOnChangeMetricReportDefinition creates a spec for a report produced when
+// a metric value changes and kept in the metric reports collection.
+func NewOnChangeMetricReportDefinition(id string, metrics ...Metric) *MetricReportDefinitionSpec {
+	return newMetricReportDefinitionSpec(id, OnChangeMetricReportDefinitionType, metrics)
+}
+
+// NewOnRequestMetricReportDefinition creates a spec for a report produced
+// when it is retrieved.
+func NewOnRequestMetricReportDefinition(id string, metrics ...Metric) *MetricReportDefinitionSpec {
+	return newMetricReportDefinitionSpec(id, OnRequestMetricReportDefinitionType, metrics)
+}
+
+func newMetricReportDefinitionSpec(id string, definitionType MetricReportDefinitionType, metrics []Metric) *MetricReportDefinitionSpec {
+	return &MetricReportDefinitionSpec{
+		ID:                            id,
+		MetricReportDefinitionType:    definitionType,
+		MetricReportDefinitionEnabled: true,
+		Metrics:                       metrics,
+		ReportActions:                 []ReportActionsEnum{LogToMetricReportsCollectionReportActionsEnum},
+		ReportUpdates:                 OverwriteReportUpdatesEnum,
+	}
+}
+
+// NewPropertyMetric creates a metric of the properties, given as URIs with a
+// JSON pointer fragment, such as "/redfish/v1/Chassis/{ID}/Power#/PowerControl/0/PowerConsumedWatts".
+// Wildcards in braces are replaced with the values of the definition's
+// Wildcards.
+func NewPropertyMetric(properties ...string) Metric {
+	return Metric{MetricProperties: properties}
+}
+
+// NewDefinedMetric creates a metric of the properties of a metric definition.
+func NewDefinedMetric(metricID string) Metric {
+	return Metric{MetricID: metricID}
+}
+
+// WithCollection returns the metric with the function applied to its values
+// over the duration.
+func (m Metric) WithCollection(function CalculationAlgorithmEnum, duration time.Duration) Metric {
+	m.CollectionFunction = function
+	m.CollectionDuration = FormatRedfishDuration(duration)
+	m.CollectionTimeScope = IntervalCollectionTimeScope
+	return m
+}
+
+// TriggerSpec describes a trigger to create.
+type TriggerSpec struct {
+	// ID is the Id of the trigger.
+	ID string `json:"Id"`
+	// Name is the name of the trigger.
+	Name string `json:",omitempty"`
+	// MetricType is whether the trigger is for numeric or discrete metrics.
+	MetricType MetricTypeEnum
+	// MetricProperties are the properties the trigger applies to.
+	MetricProperties []string `json:",omitempty"`
+	// MetricIDs are the Ids of the metric definitions the trigger applies to.
+	MetricIDs []string `json:"MetricIds,omitempty"`
+	// Wildcards are the values of the wildcards in the metric properties.
+	Wildcards []TriggerWildcard `json:",omitempty"`
+	// TriggerActions are what is done when the trigger fires.
+	TriggerActions []TriggerActionEnum `json:",omitempty"`
+	// TriggerEnabled is whether the trigger is enabled.
+	TriggerEnabled bool
+	// NumericThresholds are the thresholds of numeric triggers.
+	NumericThresholds TriggerThresholds
+	// DiscreteTriggerCondition is whether discrete triggers fire on any
+	// change or on the values of DiscreteTriggers.
+	DiscreteTriggerCondition DiscreteTriggerConditionEnum `json:",omitempty"`
+	// DiscreteTriggers are the values that fire discrete triggers.
+	DiscreteTriggers []DiscreteTrigger `json:",omitempty"`
+	// HysteresisDuration is how long a value must stay past a threshold.
+	HysteresisDuration string `json:",omitempty"`
+	// HysteresisReading is how far a value must move back past a threshold
+	// for it to be cleared.
+	HysteresisReading *float64 `json:",omitempty"`
+	// MetricReportDefinitions are the URIs of the metric report definitions
+	// to produce reports for when TriggerActions includes
+	// RedfishMetricReport.
+	MetricReportDefinitions []string `json:"-"`
+}
+
+// NewNumericTrigger creates a spec for a trigger that sends an event when the
+// properties cross the thresholds.
+func NewNumericTrigger(id string, thresholds TriggerThresholds, metricProperties ...string) *TriggerSpec {
+	return &TriggerSpec{
+		ID:                id,
+		MetricType:        NumericMetricTypeEnum,
+		MetricProperties:  metricProperties,
+		TriggerActions:    []TriggerActionEnum{RedfishEventTriggerActionEnum},
+		TriggerEnabled:    true,
+		NumericThresholds: thresholds,
+	}
+}
+
+// NewDiscreteTrigger creates a spec for a trigger that sends an event when the
+// properties change. If values are given, it only fires when a property has
+// one of the values.
+func NewDiscreteTrigger(id string, values []DiscreteTrigger, metricProperties ...string) *TriggerSpec {
+	spec := &TriggerSpec{
+		ID:                       id,
+		MetricType:               DiscreteMetricTypeEnum,
+		MetricProperties:         metricProperties,
+		TriggerActions:           []TriggerActionEnum{RedfishEventTriggerActionEnum},
+		TriggerEnabled:           true,
+		DiscreteTriggerCondition: ChangedDiscreteTriggerConditionEnum,
+	}
+	if len(values) > 0 {
+		spec.DiscreteTriggerCondition = SpecifiedDiscreteTriggerConditionEnum
+		spec.DiscreteTriggers = values
+	}
+	return spec
+}
+
+// CreateMetricReportDefinition creates a metric report definition after
+// validating it against the service's capabilities.
+func (t *TelemetryService) CreateMetricReportDefinition(spec *MetricReportDefinitionSpec) (*MetricReportDefinition, error) {
+	if t.metricReportDefinitions == "" {
+		return nil, errors.New("metric report definitions are not supported by this service")
+	}
+	if err := t.ValidateMetricReportDefinition(spec); err != nil {
+		return nil, err
+	}
+
+	payload, err := telemetryPayload(spec)
+	if err != nil {
+		return nil, err
+	}
+	return createCollectionMember[MetricReportDefinition](t.GetClient(), t.metricReportDefinitions, payload)
+}
+
+// CreateTrigger creates a trigger after validating it against the service's
+// capabilities.
+func (t *TelemetryService) CreateTrigger(spec *TriggerSpec) (*Triggers, error) {
+	if t.triggers == "" {
+		return nil, errors.New("triggers are not supported by this service")
+	}
+	if err := t.ValidateTrigger(spec); err != nil {
+		return nil, err
+	}
+
+	payload, err := telemetryPayload(spec)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.MetricReportDefinitions) > 0 {
+		definitions := make([]map[string]string, 0, len(spec.MetricReportDefinitions))
+		for _, uri := range spec.MetricReportDefinitions {
+			definitions = append(definitions, linkObject(uri))
+		}
+		payload["Links"] = map[string]any{"MetricReportDefinitions": definitions}
+	}
+	return createCollectionMember[Triggers](t.GetClient(), t.triggers, payload)
+}
+
+// DeleteMetricReportDefinition deletes the metric report definition at the
+// URI.
+func (t *TelemetryService) DeleteMetricReportDefinition(uri string) error {
+	return deleteTelemetryResource(t.GetClient(), uri)
+}
+
+// DeleteTrigger deletes the trigger at the URI.
+func (t *TelemetryService) DeleteTrigger(uri string) error {
+	return deleteTelemetryResource(t.GetClient(), uri)
+}
+
+func deleteTelemetryResource(c Client, uri string) error {
+	if uri == "" {
+		return errors.New("uri should not be empty")
+	}
+	resp, err := c.Delete(uri)
+	defer DeferredCleanupHTTPResponse(resp)
+	return err
+}
+
+// ValidateMetricReportDefinition checks the spec is complete and is within
+// the service's MinCollectionInterval, SupportedCollectionFunctions and
+// MetricDefinitions. Metric properties are checked against the
+// MetricProperties of the metric definitions, if the service lists any.
+func (t *TelemetryService) ValidateMetricReportDefinition(spec *MetricReportDefinitionSpec) error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidTelemetrySpec, fmt.Sprintf(format, args...)))
+	}
+
+	if spec.ID == "" {
+		invalid("an Id is required")
+	}
+	if len(spec.Metrics) == 0 {
+		invalid("at least one metric is required")
+	}
+
+	switch spec.MetricReportDefinitionType {
+	case PeriodicMetricReportDefinitionType:
+		if spec.Schedule.RecurrenceInterval == "" {
+			invalid("periodic reports need a Schedule RecurrenceInterval")
+		} else if err := t.checkInterval("Schedule RecurrenceInterval", spec.Schedule.RecurrenceInterval); err != nil {
+			errs = append(errs, err)
+		}
+	case OnChangeMetricReportDefinitionType, OnRequestMetricReportDefinitionType:
+	default:
+		invalid("unknown MetricReportDefinitionType %q", spec.MetricReportDefinitionType)
+	}
+
+	wildcards := make([]string, 0, len(spec.Wildcards))
+	for _, wildcard := range spec.Wildcards {
+		wildcards = append(wildcards, wildcard.Name)
+	}
+
+	var metricIDs, properties []string
+	for i, metric := range spec.Metrics {
+		name := fmt.Sprintf("metric %d", i)
+		switch {
+		case metric.MetricID != "" && len(metric.MetricProperties) > 0:
+			invalid("%s has both a MetricId and MetricProperties", name)
+		case metric.MetricID != "":
+			metricIDs = append(metricIDs, metric.MetricID)
+		case len(metric.MetricProperties) == 0:
+			invalid("%s needs a MetricId or MetricProperties", name)
+		}
+		properties = append(properties, metric.MetricProperties...)
+		errs = append(errs, checkWildcards(name, metric.MetricProperties, wildcards)...)
+
+		if metric.CollectionFunction != "" && len(t.SupportedCollectionFunctions) > 0 &&
+			!slices.Contains(t.SupportedCollectionFunctions, CollectionFunction(metric.CollectionFunction)) {
+			invalid("%s CollectionFunction %s is not supported by the service", name, metric.CollectionFunction)
+		}
+		if metric.CollectionDuration != "" {
+			if err := t.checkInterval(name+" CollectionDuration", metric.CollectionDuration); err != nil {
+				errs = append(errs, err)
+			}
+		} else if metric.CollectionTimeScope == IntervalCollectionTimeScope {
+			invalid("%s needs a CollectionDuration for the Interval CollectionTimeScope", name)
+		}
+	}
+
+	if len(errs) == 0 {
+		errs = append(errs, t.checkMetrics(metricIDs, properties)...)
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateTrigger checks the spec is complete and is within the service's
+// MetricDefinitions. Metric properties are checked against the
+// MetricProperties of the metric definitions, if the service lists any.
+func (t *TelemetryService) ValidateTrigger(spec *TriggerSpec) error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidTelemetrySpec, fmt.Sprintf(format, args...)))
+	}
+
+	if spec.ID == "" {
+		invalid("an Id is required")
+	}
+	if len(spec.MetricProperties) == 0 && len(spec.MetricIDs) == 0 {
+		invalid("MetricProperties or MetricIds are required")
+	}
+
+	switch spec.MetricType {
+	case NumericMetricTypeEnum:
+		thresholds := spec.NumericThresholds
+		set := false
+		for _, threshold := range []TriggerThreshold{
+			thresholds.LowerCritical, thresholds.LowerWarning, thresholds.UpperCritical, thresholds.UpperWarning,
+		} {
+			if threshold.Reading != nil {
+				set = true
+			}
+			if threshold.DwellTime != "" {
+				if _, err := ParseRedfishDuration(threshold.DwellTime); err != nil {
+					invalid("threshold DwellTime: %s", err)
+				}
+			}
+		}
+		if !set {
+			invalid("numeric triggers need at least one threshold Reading")
+		}
+	case DiscreteMetricTypeEnum:
+		if spec.DiscreteTriggerCondition == SpecifiedDiscreteTriggerConditionEnum && len(spec.DiscreteTriggers) == 0 {
+			invalid("the Specified DiscreteTriggerCondition needs DiscreteTriggers")
+		}
+	default:
+		invalid("unknown MetricType %q", spec.MetricType)
+	}
+
+	if spec.HysteresisDuration != "" {
+		if _, err := ParseRedfishDuration(spec.HysteresisDuration); err != nil {
+			invalid("HysteresisDuration: %s", err)
+		}
+	}
+	if slices.Contains(spec.TriggerActions, RedfishMetricReportTriggerActionEnum) && len(spec.MetricReportDefinitions) == 0 {
+		invalid("the RedfishMetricReport action needs MetricReportDefinitions")
+	}
+
+	wildcards := make([]string, 0, len(spec.Wildcards))
+	for _, wildcard := range spec.Wildcards {
+		wildcards = append(wildcards, wildcard.Name)
+	}
+	errs = append(errs, checkWildcards("trigger", spec.MetricProperties, wildcards)...)
+
+	if len(errs) == 0 {
+		errs = append(errs, t.checkMetrics(spec.MetricIDs, spec.MetricProperties)...)
+	}
+	return errors.Join(errs...)
+}
+
+// checkInterval checks the duration is valid and not shorter than the
+// service's MinCollectionInterval.
+func (t *TelemetryService) checkInterval(name, value string) error {
+	duration, err := ParseRedfishDuration(value)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidTelemetrySpec, name, err)
+	}
+	if t.MinCollectionInterval == "" {
+		return nil
+	}
+	minimum, err := ParseRedfishDuration(t.MinCollectionInterval)
+	if err != nil {
+		// The service's minimum is unusable, leave it to the service
+		return nil
+	}
+	if duration < minimum {
+		return fmt.Errorf("%w: %s %s is shorter than the MinCollectionInterval %s",
+			ErrInvalidTelemetrySpec, name, value, t.MinCollectionInterval)
+	}
+	return nil
+}
+
+// checkMetrics checks the metric definitions of the Ids exist, and that the
+// properties are covered by the MetricProperties of a metric definition.
+// Properties aren't checked if no metric definition lists MetricProperties,
+// as there is nothing to check them against.
+func (t *TelemetryService) checkMetrics(metricIDs, properties []string) []error {
+	if len(metricIDs) == 0 && len(properties) == 0 {
+		return nil
+	}
+	if t.metricDefinitions == "" {
+		if len(metricIDs) == 0 {
+			return nil
+		}
+		return []error{fmt.Errorf("%w: the service has no metric definitions", ErrInvalidTelemetrySpec)}
+	}
+
+	var known, patterns []string
+	if len(properties) == 0 {
+		collection, err := GetCollection(t.GetClient(), t.metricDefinitions)
+		if err != nil {
+			return []error{err}
+		}
+		for _, link := range collection.ItemLinks {
+			known = append(known, path.Base(link))
+		}
+	} else {
+		definitions, err := GetCollectionObjects[MetricDefinition](t.GetClient(), t.metricDefinitions)
+		if err != nil {
+			return []error{err}
+		}
+		for _, definition := range definitions {
+			known = append(known, definition.ID, path.Base(definition.ODataID))
+			patterns = append(patterns, definition.MetricProperties...)
+		}
+	}
+
+	var errs []error
+	for _, id := range metricIDs {
+		if !slices.Contains(known, id) {
+			errs = append(errs, fmt.Errorf("%w: unknown MetricId %s", ErrInvalidTelemetrySpec, id))
+		}
+	}
+	if len(patterns) == 0 {
+		return errs
+	}
+	for _, property := range properties {
+		if !slices.ContainsFunc(patterns, func(pattern string) bool { return metricPropertyMatches(property, pattern) }) {
+			errs = append(errs, fmt.Errorf("%w: property %s is not in the MetricProperties of any metric definition",
+				ErrInvalidTelemetrySpec, property))
+		}
+	}
+	return errs
+}
+
+// metricPropertyMatches returns whether the property is covered by the
+// pattern of a metric definition. Path segments with wildcards, in either the
+// property or the pattern, match any segment.
+func metricPropertyMatches(property, pattern string) bool {
+	propertySegments := strings.Split(property, "/")
+	patternSegments := strings.Split(pattern, "/")
+	if len(propertySegments) != len(patternSegments) {
+		return false
+	}
+	for i, segment := range propertySegments {
+		if segment == patternSegments[i] || wildcardPattern.MatchString(segment) {
+			continue
+		}
+		literals := wildcardPattern.Split(patternSegments[i], -1)
+		for j, literal := range literals {
+			literals[j] = regexp.QuoteMeta(literal)
+		}
+		expr := "^" + strings.Join(literals, ".*") + "$"
+		if matched, err := regexp.MatchString(expr, segment); err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWildcards checks each wildcard in the properties has values.
+func checkWildcards(name string, properties, wildcards []string) []error {
+	var errs []error
+	for _, property := range properties {
+		for _, match := range wildcardPattern.FindAllStringSubmatch(property, -1) {
+			if !slices.Contains(wildcards, match[1]) {
+				errs = append(errs, fmt.Errorf("%w: %s property %s has no values for wildcard %s",
+					ErrInvalidTelemetrySpec, name, property, match[1]))
+			}
+		}
+	}
+	return errs
+}
+
+// telemetryPayload encodes the spec, leaving out the empty values of the
+// nested schema types.
+func telemetryPayload(spec any) (map[string]any, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(b, &payload); err != nil {
+		return nil, err
+	}
+	pruneEmpty(payload)
+	return payload, nil
+}
+
+// pruneEmpty removes empty strings, nulls, and empty objects and arrays,
+// including the elements of arrays that are empty once pruned. It returns the
+// pruned value and whether it is empty.
+func pruneEmpty(value any) (any, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return v, v == ""
+	case map[string]any:
+		for key, child := range v {
+			pruned, empty := pruneEmpty(child)
+			if empty {
+				delete(v, key)
+			} else {
+				v[key] = pruned
+			}
+		}
+		return v, len(v) == 0
+	case []any:
+		kept := v[:0]
+		for _, child := range v {
+			if pruned, empty := pruneEmpty(child); !empty {
+				kept = append(kept, pruned)
+			}
+		}
+		return kept, len(kept) == 0
+	}
+	return value, false
+}
+
+// createCollectionMember posts the payload to the collection and returns the
+// created resource, either from the response or from its Location.
+func createCollectionMember[T any, PT GenericSchemaObjectPointer[T]](c Client, uri string, payload any) (*T, error) {
+	resp, err := c.Post(uri, payload)
+	defer DeferredCleanupHTTPResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var result T
+	if err := json.NewDecoder(resp.Body).Decode(&result); err == nil && PT(&result).GetODataID() != "" {
+		PT(&result).SetClient(c)
+		return &result, nil
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return nil, errors.New("service did not return the created resource")
+	}
+	if u, err := url.Parse(location); err == nil && u.IsAbs() {
+		location = u.RequestURI()
+	}
+	return GetObject[T, PT](c, location)
+}
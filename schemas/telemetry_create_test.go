@@ -0,0 +1,206 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package schemas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createTelemetryService(t *testing.T, testClient *TestClient) *TelemetryService {
+	var result TelemetryService
+	body := `{"@odata.id": "/redfish/v1/TelemetryService", "Id": "TelemetryService",
+		"MinCollectionInterval": "PT10S",
+		"SupportedCollectionFunctions": ["Average", "Maximum"],
+		"MetricDefinitions": {"@odata.id": "/redfish/v1/TelemetryService/MetricDefinitions"},
+		"MetricReportDefinitions": {"@odata.id": "/redfish/v1/TelemetryService/MetricReportDefinitions"},
+		"Triggers": {"@odata.id": "/redfish/v1/TelemetryService/Triggers"}}`
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatalf("Error decoding JSON: %s", err)
+	}
+	result.SetClient(testClient)
+	return &result
+}
+
+func createdCall(location string) *http.Response {
+	resp := &http.Response{StatusCode: http.StatusCreated, Header: make(http.Header), Body: http.NoBody}
+	resp.Header.Set("Location", location)
+	return resp
+}
+
+// TestCreateMetricReportDefinition tests a periodic definition is validated
+// and created.
+func TestCreateMetricReportDefinition(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				getCall(`{"Members": [{"@odata.id": "/redfish/v1/TelemetryService/MetricDefinitions/PowerConsumedWatts"}]}`),
+				getCall(`{"@odata.id": "/redfish/v1/TelemetryService/MetricDefinitions/PowerConsumedWatts",
+					"Id": "PowerConsumedWatts", "MetricProperties": [
+						"/redfish/v1/Chassis/{ChassisID}/Power#/PowerControl/{ControlID}/PowerConsumedWatts",
+						"/redfish/v1/Chassis/{ChassisID}/Thermal#/Fans/{FanID}/Reading"]}`),
+				getCall(`{"@odata.id": "/redfish/v1/TelemetryService/MetricReportDefinitions/Power", "Id": "Power"}`),
+			},
+			http.MethodPost: {createdCall("https://bmc.example.com/redfish/v1/TelemetryService/MetricReportDefinitions/Power")},
+		},
+	}
+	service := createTelemetryService(t, testClient)
+
+	spec := NewPeriodicMetricReportDefinition("Power", time.Minute,
+		NewDefinedMetric("PowerConsumedWatts"),
+		NewPropertyMetric("/redfish/v1/Chassis/{ChassisID}/Thermal#/Fans/0/Reading").
+			WithCollection(MaximumCalculationAlgorithmEnum, 30*time.Second))
+	spec.Wildcards = []Wildcard{{Name: "ChassisID", Values: []string{"*"}}}
+
+	definition, err := service.CreateMetricReportDefinition(spec)
+	if err != nil {
+		t.Fatalf("Error creating definition: %s", err)
+	}
+	AssertEqual(t, "Power", definition.ID)
+
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, "/redfish/v1/TelemetryService/MetricReportDefinitions", calls[2].URL)
+	AssertEqual(t, "map[Id:Power MetricReportDefinitionEnabled:true MetricReportDefinitionType:Periodic "+
+		"Metrics:[map[MetricId:PowerConsumedWatts] map[CollectionDuration:PT30S CollectionFunction:Maximum "+
+		"CollectionTimeScope:Interval MetricProperties:[/redfish/v1/Chassis/{ChassisID}/Thermal#/Fans/0/Reading]]] "+
+		"ReportActions:[LogToMetricReportsCollection] ReportUpdates:Overwrite Schedule:map[RecurrenceInterval:PT1M] "+
+		"Wildcards:[map[Name:ChassisID Values:[*]]]]", calls[2].Payload)
+	AssertEqual(t, "/redfish/v1/TelemetryService/MetricReportDefinitions/Power", calls[3].URL)
+}
+
+// TestValidateMetricReportDefinition tests definitions the service can't
+// support are rejected before being sent.
+func TestValidateMetricReportDefinition(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {getCall(`{"Members": [{"@odata.id": "/redfish/v1/TelemetryService/MetricDefinitions/Temp"}]}`)},
+		},
+	}
+	service := createTelemetryService(t, testClient)
+
+	spec := NewPeriodicMetricReportDefinition("Fast", time.Second,
+		NewPropertyMetric("/redfish/v1/Chassis/{ID}/Power#/Voltages/0/Reading").
+			WithCollection(SummationCalculationAlgorithmEnum, time.Minute))
+	_, err := service.CreateMetricReportDefinition(spec)
+	if !errors.Is(err, ErrInvalidTelemetrySpec) {
+		t.Fatalf("Expected invalid spec error, got: %v", err)
+	}
+	for _, expected := range []string{
+		"RecurrenceInterval PT1S is shorter than the MinCollectionInterval PT10S",
+		"CollectionFunction Summation is not supported",
+		"no values for wildcard ID",
+	} {
+		RequireErrorContains(t, err, expected)
+	}
+
+	err = service.ValidateMetricReportDefinition(NewOnChangeMetricReportDefinition("Temps", NewDefinedMetric("Missing")))
+	RequireErrorContains(t, err, "unknown MetricId Missing")
+	// Only the metric definitions were read, nothing was created
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, 1, len(calls))
+	AssertEqual(t, http.MethodGet, calls[0].Action)
+}
+
+// TestValidateMetricProperties tests metric properties are checked against
+// the properties of the service's metric definitions.
+func TestValidateMetricProperties(t *testing.T) {
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodGet: {
+				getCall(`{"Members": [{"@odata.id": "/redfish/v1/TelemetryService/MetricDefinitions/Temp"}]}`),
+				getCall(`{"@odata.id": "/redfish/v1/TelemetryService/MetricDefinitions/Temp", "Id": "Temp",
+					"MetricProperties": ["/redfish/v1/Chassis/{ChassisID}/Sensors/{SensorID}#/Reading"]}`),
+			},
+		},
+	}
+	service := createTelemetryService(t, testClient)
+
+	reading := 90.0
+	spec := NewNumericTrigger("Temps", TriggerThresholds{UpperCritical: TriggerThreshold{Reading: &reading}},
+		"/redfish/v1/Chassis/1/Sensors/{ID}#/Reading", "/redfish/v1/Chassis/1/Sensors/CPU1Temp#/Reading",
+		"/redfish/v1/Chassis/1/Thermal#/Temperatures/0/ReadingCelsius")
+	spec.Wildcards = []TriggerWildcard{{Name: "ID", Values: []string{"*"}}}
+	err := service.ValidateTrigger(spec)
+	if !errors.Is(err, ErrInvalidTelemetrySpec) {
+		t.Fatalf("Expected invalid spec error, got: %v", err)
+	}
+	AssertEqual(t, "invalid telemetry definition: property /redfish/v1/Chassis/1/Thermal#/Temperatures/0/ReadingCelsius "+
+		"is not in the MetricProperties of any metric definition", err.Error())
+}
+
+// TestTelemetryPayloadPruning tests empty values are left out of payloads,
+// including empty array elements.
+func TestTelemetryPayloadPruning(t *testing.T) {
+	spec := NewOnRequestMetricReportDefinition("Empty", NewDefinedMetric("Temp"), Metric{})
+	spec.Wildcards = []Wildcard{{Name: "ID"}, {}}
+	payload, err := telemetryPayload(spec)
+	if err != nil {
+		t.Fatalf("Error encoding payload: %s", err)
+	}
+	AssertEqual(t, "map[Id:Empty MetricReportDefinitionEnabled:true MetricReportDefinitionType:OnRequest "+
+		"Metrics:[map[MetricId:Temp]] ReportActions:[LogToMetricReportsCollection] ReportUpdates:Overwrite "+
+		"Wildcards:[map[Name:ID]]]", fmt.Sprint(payload))
+}
+
+// TestCreateTrigger tests numeric and discrete triggers are created.
+func TestCreateTrigger(t *testing.T) {
+	created := getCall(`{"@odata.id": "/redfish/v1/TelemetryService/Triggers/CPUTemp", "Id": "CPUTemp"}`)
+	testClient := &TestClient{
+		CustomReturnForActions: map[string][]any{
+			http.MethodPost: {created, createdCall("/redfish/v1/TelemetryService/Triggers/PSU")},
+			http.MethodGet:  {getCall(`{"@odata.id": "/redfish/v1/TelemetryService/Triggers/PSU", "Id": "PSU"}`)},
+		},
+	}
+	service := createTelemetryService(t, testClient)
+	// Without metric definitions, the properties are left to the service
+	service.metricDefinitions = ""
+
+	reading := 90.0
+	spec := NewNumericTrigger("CPUTemp", TriggerThresholds{
+		UpperCritical: TriggerThreshold{Reading: &reading, Activation: IncreasingThresholdActivation, DwellTime: "PT30S"},
+	}, "/redfish/v1/Chassis/1/Sensors/CPU1Temp#/Reading")
+	spec.TriggerActions = append(spec.TriggerActions, RedfishMetricReportTriggerActionEnum)
+	spec.MetricReportDefinitions = []string{"/redfish/v1/TelemetryService/MetricReportDefinitions/Thermal"}
+
+	trigger, err := service.CreateTrigger(spec)
+	if err != nil {
+		t.Fatalf("Error creating trigger: %s", err)
+	}
+	AssertEqual(t, "CPUTemp", trigger.ID)
+
+	calls := testClient.CapturedCalls()
+	AssertEqual(t, "map[Id:CPUTemp Links:map[MetricReportDefinitions:[map[@odata.id:/redfish/v1/TelemetryService/MetricReportDefinitions/Thermal]]] "+
+		"MetricProperties:[/redfish/v1/Chassis/1/Sensors/CPU1Temp#/Reading] MetricType:Numeric "+
+		"NumericThresholds:map[UpperCritical:map[Activation:Increasing DwellTime:PT30S Reading:90]] "+
+		"TriggerActions:[RedfishEvent RedfishMetricReport] TriggerEnabled:true]", calls[0].Payload)
+
+	discrete := NewDiscreteTrigger("PSU", []DiscreteTrigger{{Value: "Absent", Severity: CriticalHealth}},
+		"/redfish/v1/Chassis/1/PowerSubsystem/PowerSupplies/{ID}#/Status/State")
+	_, err = service.CreateTrigger(discrete)
+	RequireErrorContains(t, err, "no values for wildcard ID")
+
+	discrete.Wildcards = []TriggerWildcard{{Name: "ID", Values: []string{"*"}}}
+	trigger, err = service.CreateTrigger(discrete)
+	if err != nil {
+		t.Fatalf("Error creating trigger: %s", err)
+	}
+	AssertEqual(t, "PSU", trigger.ID)
+	if !strings.Contains(testClient.CapturedCalls()[1].Payload, "DiscreteTriggerCondition:Specified") {
+		t.Errorf("Unexpected payload: %s", testClient.CapturedCalls()[1].Payload)
+	}
+
+	_, err = service.CreateTrigger(&TriggerSpec{ID: "Empty", MetricType: NumericMetricTypeEnum, MetricIDs: []string{"X"}})
+	RequireErrorContains(t, err, "at least one threshold Reading")
+
+	if err := service.DeleteTrigger("/redfish/v1/TelemetryService/Triggers/PSU"); err != nil {
+		t.Fatalf("Error deleting trigger: %s", err)
+	}
+	AssertEqual(t, http.MethodDelete, testClient.CapturedCalls()[3].Action)
+}
@@ -154,13 +154,16 @@ func (t *TelemetryService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (t *TelemetryService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (t *TelemetryService) writableFields() []string {
+	return []string{
 		"ServiceEnabled",
 	}
+}
 
-	return t.UpdateFromRawData(t, t.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (t *TelemetryService) Update() error {
+	return t.UpdateFromRawData(t, t.RawData, t.writableFields())
 }
 
 // GetTelemetryService will get a TelemetryService instance from the service.
@@ -140,7 +140,8 @@ func (c *TestClient) performAction(action, url string, payload any, customHeader
 	if resp.StatusCode == http.StatusNotModified {
 		return resp, ErrNotModified
 	}
-	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 202 && resp.StatusCode != 204 {
+	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 202 && resp.StatusCode != 204 &&
+		resp.StatusCode != http.StatusPartialContent {
 		defer DeferredCleanupHTTPResponse(resp)
 		payload, err := io.ReadAll(resp.Body)
 		if err != nil {
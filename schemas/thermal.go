@@ -77,14 +77,17 @@ func (t *Thermal) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (t *Thermal) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (t *Thermal) writableFields() []string {
+	return []string{
 		"Fans",
 		"Temperatures",
 	}
+}
 
-	return t.UpdateFromRawData(t, t.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (t *Thermal) Update() error {
+	return t.UpdateFromRawData(t, t.RawData, t.writableFields())
 }
 
 // GetThermal will get a Thermal instance from the service.
@@ -262,13 +265,16 @@ func (f *ThermalFan) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (f *ThermalFan) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (f *ThermalFan) writableFields() []string {
+	return []string{
 		"IndicatorLED",
 	}
+}
 
-	return f.UpdateFromRawData(f, f.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (f *ThermalFan) Update() error {
+	return f.UpdateFromRawData(f, f.RawData, f.writableFields())
 }
 
 // GetThermalFan will get a ThermalFan instance from the service.
@@ -446,14 +452,17 @@ func (t *Temperature) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (t *Temperature) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (t *Temperature) writableFields() []string {
+	return []string{
 		"LowerThresholdUser",
 		"UpperThresholdUser",
 	}
+}
 
-	return t.UpdateFromRawData(t, t.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (t *Temperature) Update() error {
+	return t.UpdateFromRawData(t, t.RawData, t.writableFields())
 }
 
 // GetTemperature will get a Temperature instance from the service.
@@ -114,13 +114,16 @@ func (t *ThermalSubsystem) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (t *ThermalSubsystem) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (t *ThermalSubsystem) writableFields() []string {
+	return []string{
 		"FansFullSpeedOverrideEnable",
 	}
+}
 
-	return t.UpdateFromRawData(t, t.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (t *ThermalSubsystem) Update() error {
+	return t.UpdateFromRawData(t, t.RawData, t.writableFields())
 }
 
 // GetThermalSubsystem will get a ThermalSubsystem instance from the service.
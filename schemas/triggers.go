@@ -210,9 +210,9 @@ func (t *Triggers) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (t *Triggers) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (t *Triggers) writableFields() []string {
+	return []string{
 		"EventTriggers",
 		"HysteresisDuration",
 		"HysteresisReading",
@@ -221,8 +221,11 @@ func (t *Triggers) Update() error {
 		"TriggerActionMessage",
 		"TriggerEnabled",
 	}
+}
 
-	return t.UpdateFromRawData(t, t.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (t *Triggers) Update() error {
+	return t.UpdateFromRawData(t, t.RawData, t.writableFields())
 }
 
 // GetTriggers will get a Triggers instance from the service.
@@ -367,9 +367,9 @@ func (u *UpdateService) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (u *UpdateService) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (u *UpdateService) writableFields() []string {
+	return []string{
 		"HttpPushUriOptionsBusy",
 		"HttpPushUriTargets",
 		"HttpPushUriTargetsBusy",
@@ -377,8 +377,11 @@ func (u *UpdateService) Update() error {
 		"VerifyRemoteServerCertificate",
 		"VerifyRemoteServerSSHKey",
 	}
+}
 
-	return u.UpdateFromRawData(u, u.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (u *UpdateService) Update() error {
+	return u.UpdateFromRawData(u, u.RawData, u.writableFields())
 }
 
 // GetUpdateService will get a UpdateService instance from the service.
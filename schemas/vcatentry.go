@@ -57,13 +57,16 @@ func (v *VCATEntry) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (v *VCATEntry) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (v *VCATEntry) writableFields() []string {
+	return []string{
 		"RawEntryHex",
 	}
+}
 
-	return v.UpdateFromRawData(v, v.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (v *VCATEntry) Update() error {
+	return v.UpdateFromRawData(v, v.RawData, v.writableFields())
 }
 
 // GetVCATEntry will get a VCATEntry instance from the service.
@@ -245,9 +245,9 @@ func (v *VirtualMedia) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (v *VirtualMedia) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (v *VirtualMedia) writableFields() []string {
+	return []string{
 		"EjectPolicy",
 		"EjectTimeout",
 		"Image",
@@ -259,8 +259,11 @@ func (v *VirtualMedia) Update() error {
 		"VerifyCertificate",
 		"WriteProtected",
 	}
+}
 
-	return v.UpdateFromRawData(v, v.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (v *VirtualMedia) Update() error {
+	return v.UpdateFromRawData(v, v.RawData, v.writableFields())
 }
 
 // GetVirtualMedia will get a VirtualMedia instance from the service.
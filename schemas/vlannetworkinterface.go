@@ -58,15 +58,18 @@ func (v *VLanNetworkInterface) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (v *VLanNetworkInterface) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (v *VLanNetworkInterface) writableFields() []string {
+	return []string{
 		"VLANEnable",
 		"VLANId",
 		"VLANPriority",
 	}
+}
 
-	return v.UpdateFromRawData(v, v.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (v *VLanNetworkInterface) Update() error {
+	return v.UpdateFromRawData(v, v.RawData, v.writableFields())
 }
 
 // GetVLanNetworkInterface will get a VLanNetworkInterface instance from the service.
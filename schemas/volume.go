@@ -744,9 +744,9 @@ func (v *Volume) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (v *Volume) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (v *Volume) writableFields() []string {
+	return []string{
 		"AccessCapabilities",
 		"CapacityBytes",
 		"CapacitySources",
@@ -766,8 +766,11 @@ func (v *Volume) Update() error {
 		"WriteCachePolicy",
 		"WriteHoleProtectionPolicy",
 	}
+}
 
-	return v.UpdateFromRawData(v, v.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (v *Volume) Update() error {
+	return v.UpdateFromRawData(v, v.RawData, v.writableFields())
 }
 
 // GetVolume will get a Volume instance from the service.
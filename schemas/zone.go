@@ -148,15 +148,18 @@ func (z *Zone) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
-// Update commits updates to this object's properties to the running system.
-func (z *Zone) Update() error {
-	readWriteFields := []string{
+// writableFields returns the properties of this object that can be updated.
+func (z *Zone) writableFields() []string {
+	return []string{
 		"DefaultRoutingEnabled",
 		"ExternalAccessibility",
 		"ZoneType",
 	}
+}
 
-	return z.UpdateFromRawData(z, z.RawData, readWriteFields)
+// Update commits updates to this object's properties to the running system.
+func (z *Zone) Update() error {
+	return z.UpdateFromRawData(z, z.RawData, z.writableFields())
 }
 
 // GetZone will get a Zone instance from the service.
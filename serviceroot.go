@@ -585,11 +585,25 @@ func (s *Service) CreateSession(username, password string) (*schemas.AuthToken,
 	return schemas.CreateSession(s.GetClient(), s.sessions, username, password)
 }
 
+// CreateSessionWithParameters creates a new session using the provided
+// parameters, allowing multi-factor authentication tokens and a session
+// context to be sent.
+func (s *Service) CreateSessionWithParameters(params *schemas.SessionCreateParameters) (*schemas.AuthToken, error) {
+	return schemas.CreateSessionWithParameters(s.GetClient(), s.sessions, params)
+}
+
 // DeleteSession logout the specified session
 func (s *Service) DeleteSession(url string) error {
 	return schemas.DeleteSession(s.GetClient(), url)
 }
 
+// MessageResolver returns a function that resolves the text of messages that
+// only contain a MessageId and arguments using the service's message
+// registries in the given language, such as "en".
+func (s *Service) MessageResolver(language string) func(schemas.Message) string {
+	return schemas.NewMessageResolver(s.GetClient(), s.registries, language)
+}
+
 // DeepOperations shall contain information about deep operations that the
 // service supports.
 type DeepOperations struct {
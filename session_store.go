@@ -0,0 +1,162 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SessionStore persists sessions so they can be reused by later clients
+// connecting to the same endpoint as the same user, rather than creating a new
+// session each time. Sessions are stored under a key identifying both, as
+// returned by SessionStoreKey.
+type SessionStore interface {
+	// Load returns the session stored under the key, or nil if there is
+	// none.
+	Load(key string) (*Session, error)
+	// Save stores the session under the key.
+	Save(key string, session *Session) error
+	// Delete removes any session stored under the key.
+	Delete(key string) error
+}
+
+// SessionStoreKey returns the key a client stores the session of the user
+// for the endpoint under.
+func SessionStoreKey(endpoint, username string) string {
+	return username + "@" + endpoint
+}
+
+// EncryptedFileSessionStore is a SessionStore that keeps sessions in a file
+// encrypted with AES-GCM.
+type EncryptedFileSessionStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// NewEncryptedFileSessionStore creates a session store using the file at path.
+// The key is used directly as an AES-256 key, so must be 32 random bytes kept
+// as secret as the sessions themselves. Derive it with a password-based key
+// derivation function such as scrypt if it comes from a passphrase.
+func NewEncryptedFileSessionStore(path string, key []byte) (*EncryptedFileSessionStore, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("the encryption key must be 32 bytes, not %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedFileSessionStore{path: path, aead: aead}, nil
+}
+
+// Load returns the session stored under the key.
+func (s *EncryptedFileSessionStore) Load(key string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return sessions[key], nil
+}
+
+// Save stores the session under the key.
+func (s *EncryptedFileSessionStore) Save(key string, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.read()
+	if err != nil {
+		return err
+	}
+	sessions[key] = session
+	return s.write(sessions)
+}
+
+// Delete removes the session stored under the key.
+func (s *EncryptedFileSessionStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := sessions[key]; !ok {
+		return nil
+	}
+	delete(sessions, key)
+	return s.write(sessions)
+}
+
+func (s *EncryptedFileSessionStore) read() (map[string]*Session, error) {
+	sessions := make(map[string]*Session)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return sessions, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("session store is corrupt")
+	}
+	plain, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt session store: %w", err)
+	}
+
+	if err := json.Unmarshal(plain, &sessions); err != nil {
+		return nil, fmt.Errorf("session store is corrupt: %w", err)
+	}
+	return sessions, nil
+}
+
+func (s *EncryptedFileSessionStore) write(sessions map[string]*Session) error {
+	plain, err := json.Marshal(sessions)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	data := s.aead.Seal(nonce, nonce, plain, nil)
+
+	// Write to a temporary file first so a failure doesn't lose the store
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
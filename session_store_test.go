@@ -0,0 +1,196 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package gofish
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/schemas"
+)
+
+// TestEncryptedFileSessionStore tests saving and loading sessions.
+func TestEncryptedFileSessionStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions")
+	store, err := NewEncryptedFileSessionStore(path, testSessionKey('a'))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.Save("https://bmc1", &Session{ID: "/redfish/v1/SessionService/Sessions/1", Token: "abc123"})
+	if err != nil {
+		t.Fatalf("failed to save session: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "abc123") {
+		t.Error("session token should be encrypted")
+	}
+
+	session, err := store.Load("https://bmc1")
+	if err != nil || session == nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	schemas.AssertEqual(t, "abc123", session.Token)
+
+	other, _ := NewEncryptedFileSessionStore(path, testSessionKey('b'))
+	_, err = other.Load("https://bmc1")
+	schemas.RequireErrorContains(t, err, "unable to decrypt")
+
+	if err := store.Delete("https://bmc1"); err != nil {
+		t.Fatalf("failed to delete session: %v", err)
+	}
+	session, _ = store.Load("https://bmc1")
+	if session != nil {
+		t.Error("session should have been deleted")
+	}
+
+	_, err = NewEncryptedFileSessionStore(path, []byte("passphrase"))
+	schemas.RequireErrorContains(t, err, "must be 32 bytes")
+}
+
+func testSessionKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+// TestConnectSessionStore verifies a stored session is reused and that a new
+// session is created and stored when the stored one is no longer valid.
+func TestConnectSessionStore(t *testing.T) {
+	sessions := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/":
+			w.Write([]byte(`{"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}}`)) //nolint
+		case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/SessionService/Sessions":
+			sessions++
+			w.Header().Set("X-Auth-Token", fmt.Sprintf("token-%d", sessions))
+			w.Header().Set("Location", fmt.Sprintf("/redfish/v1/SessionService/Sessions/%d", sessions))
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/SessionService/Sessions/1":
+			if r.Header.Get("X-Auth-Token") != "token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"Id": "1"}`)) //nolint
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	store, err := NewEncryptedFileSessionStore(filepath.Join(t.TempDir(), "sessions"), testSessionKey('k'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := ClientConfig{
+		Endpoint:           ts.URL,
+		HTTPClient:         ts.Client(),
+		CredentialProvider: StaticCredentials{Username: "admin", Password: "secret"},
+		SessionStore:       store,
+	}
+
+	for i := 0; i < 2; i++ {
+		client, err := Connect(config)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		session, _ := client.GetSession()
+		schemas.AssertEqual(t, "token-1", session.Token)
+	}
+	schemas.AssertEqual(t, 1, sessions)
+
+	// An invalid stored session should be replaced
+	if err := store.Save(SessionStoreKey(ts.URL, "admin"), &Session{ID: "/redfish/v1/SessionService/Sessions/1", Token: "stale"}); err != nil {
+		t.Fatal(err)
+	}
+	client, err := Connect(config)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	session, _ := client.GetSession()
+	schemas.AssertEqual(t, "token-2", session.Token)
+
+	stored, _ := store.Load(SessionStoreKey(ts.URL, "admin"))
+	schemas.AssertEqual(t, "token-2", stored.Token)
+
+	// Another user doesn't get the stored session
+	config.CredentialProvider = StaticCredentials{Username: "operator", Password: "secret"}
+	client, err = Connect(config)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	session, _ = client.GetSession()
+	schemas.AssertEqual(t, "token-3", session.Token)
+
+	// A stored token without a session can't be checked, so isn't used
+	if err := store.Save(SessionStoreKey(ts.URL, "admin"), &Session{Token: "token-1"}); err != nil {
+		t.Fatal(err)
+	}
+	config.CredentialProvider = StaticCredentials{Username: "admin", Password: "secret"}
+	client, err = Connect(config)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	session, _ = client.GetSession()
+	schemas.AssertEqual(t, "token-4", session.Token)
+}
+
+// TestLogoutSessionStore verifies logging out removes the stored session.
+func TestLogoutSessionStore(t *testing.T) {
+	deleted := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/redfish/v1/":
+			w.Write([]byte(`{"Links": {"Sessions": {"@odata.id": "/redfish/v1/SessionService/Sessions"}}}`)) //nolint
+		case r.Method == http.MethodPost && r.URL.Path == "/redfish/v1/SessionService/Sessions":
+			w.Header().Set("X-Auth-Token", "token-1")
+			w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/1")
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodDelete && r.URL.Path == "/redfish/v1/SessionService/Sessions/1":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	store, err := NewEncryptedFileSessionStore(filepath.Join(t.TempDir(), "sessions"), testSessionKey('k'))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := Connect(ClientConfig{
+		Endpoint:     ts.URL,
+		HTTPClient:   ts.Client(),
+		Username:     "admin",
+		Password:     "secret",
+		SessionStore: store,
+	})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	stored, _ := store.Load(SessionStoreKey(ts.URL, "admin"))
+	if stored == nil {
+		t.Fatal("session should have been stored")
+	}
+
+	client.Logout()
+	if !deleted {
+		t.Error("session should have been deleted from the service")
+	}
+	stored, _ = store.Load(SessionStoreKey(ts.URL, "admin"))
+	if stored != nil {
+		t.Error("session should have been deleted from the store")
+	}
+}
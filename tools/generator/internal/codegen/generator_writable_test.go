@@ -0,0 +1,54 @@
+//
+// SPDX-License-Identifier: BSD-3-Clause
+//
+
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stmcginnis/gofish/tools/generator/internal/schema"
+)
+
+func TestWritableFieldsGeneration(t *testing.T) {
+	gen, err := NewGenerator()
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+
+	defs := []*schema.Definition{
+		{
+			Name:                "Widget",
+			OriginalName:        "Widget",
+			IsEntity:            true,
+			ReadWriteProperties: []string{"AssetTag"},
+		},
+		{
+			Name:                "Settings",
+			OriginalName:        "Settings",
+			ReadWriteProperties: []string{"Mode", "BootUri"},
+		},
+	}
+
+	output, err := gen.Generate("Widget", schema.PackageSchemas, defs, false, nil)
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+
+	// Entities get their writable fields and an Update using them
+	if !strings.Contains(output, "func (w *Widget) writableFields() []string {\n\treturn []string{\n\t\t\"AssetTag\",\n\t}\n}") {
+		t.Error("Expected writableFields for the entity")
+	}
+	if !strings.Contains(output, "func (w *Widget) Update() error {") {
+		t.Error("Expected Update for the entity")
+	}
+
+	// Nested objects only get their writable fields
+	if !strings.Contains(output, "func (s *Settings) writableFields() []string {\n\treturn []string{\n\t\t\"BootUri\",\n\t\t\"Mode\",\n\t}\n}") {
+		t.Error("Expected sorted writableFields for the nested object")
+	}
+	if strings.Contains(output, "func (s *Settings) Update() error") {
+		t.Error("Nested objects should NOT have an Update method")
+	}
+}